@@ -0,0 +1,109 @@
+// api/admin/admin_handler.go
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"transbridge/glossary"
+	"transbridge/internal/utils"
+	"transbridge/service"
+	"transbridge/tm"
+	"transbridge/translator"
+)
+
+type AdminHandler struct {
+	modelManager       *translator.ModelManager
+	translationService *service.TranslationService
+	prompts            *utils.PromptSet
+	glossary           *glossary.Store
+	memory             *tm.Store
+	authTokens         map[string]bool
+}
+
+type HandlerConfig struct {
+	AuthTokens         []string                    // 配置中的管理令牌列表
+	TranslationService *service.TranslationService // 缓存管理使用，用于按翻译时的方式计算缓存键
+	Prompts            *utils.PromptSet
+	Glossary           *glossary.Store
+	Memory             *tm.Store
+}
+
+// ModelHealthResponse 模型健康状态响应
+type ModelHealthResponse struct {
+	Healthy  int                              `json:"healthy"`  // 熔断器关闭的模型数
+	Degraded int                              `json:"degraded"` // 半开（探测中）的模型数
+	Down     int                              `json:"down"`     // 熔断中的模型数
+	Models   []translator.ModelHealthSnapshot `json:"models"`
+}
+
+func NewAdminHandler(modelManager *translator.ModelManager, config HandlerConfig) *AdminHandler {
+	tokenMap := make(map[string]bool)
+	for _, token := range config.AuthTokens {
+		tokenMap[token] = true
+	}
+
+	return &AdminHandler{
+		modelManager:       modelManager,
+		translationService: config.TranslationService,
+		prompts:            config.Prompts,
+		glossary:           config.Glossary,
+		memory:             config.Memory,
+		authTokens:         tokenMap,
+	}
+}
+
+// HandleModelHealth 返回各模型的健康与熔断状态
+func (h *AdminHandler) HandleModelHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorize(r) {
+		h.sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	resp := ModelHealthResponse{
+		Models: h.modelManager.HealthSnapshot(),
+	}
+	for _, m := range resp.Models {
+		switch m.State {
+		case translator.BreakerOpen:
+			resp.Down++
+		case translator.BreakerHalfOpen:
+			resp.Degraded++
+		default:
+			resp.Healthy++
+		}
+	}
+
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+// authorize 校验管理令牌，支持 Authorization: Bearer 与 token 查询参数
+func (h *AdminHandler) authorize(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	return token != "" && h.authTokens[token]
+}
+
+func (h *AdminHandler) sendJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// sendError 发送错误响应
+func (h *AdminHandler) sendError(w http.ResponseWriter, message string, status int) {
+	h.sendJSON(w, status, struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{
+		Code:    status,
+		Message: message,
+	})
+}
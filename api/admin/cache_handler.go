@@ -0,0 +1,268 @@
+// api/admin/cache_handler.go
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"transbridge/cache"
+	"transbridge/internal/utils"
+	"transbridge/service"
+)
+
+// maxCacheImportEntries 单次导入最多允许的条目数
+const maxCacheImportEntries = 10000
+
+// CacheEntryRequest 写入或修正一条缓存
+// Provider、Model 表示请求指定的模型，与查找参数含义相同；不指定时条目对未指定模型的请求生效
+type CacheEntryRequest struct {
+	Text        string `json:"text"`
+	SourceLang  string `json:"source_lang"`
+	TargetLang  string `json:"target_lang"`
+	Translation string `json:"translation"`
+	Provider    string `json:"provider,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Formality   string `json:"formality,omitempty"`
+	TagHandling string `json:"tag_handling,omitempty"` // html 或 xml，此时 translation 需使用原文标签对应的占位符
+}
+
+// CacheImportRequest 批量写入缓存请求
+type CacheImportRequest struct {
+	Entries []CacheEntryRequest `json:"entries"`
+	TTL     int                 `json:"ttl"` // 过期时间（秒），0 表示使用缓存的默认过期时间，-1 表示永久保存
+}
+
+// CacheLookupResponse 缓存查找结果
+type CacheLookupResponse struct {
+	Text       string `json:"text"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	*service.CachedTranslation
+}
+
+// HandleCache 管理译文缓存，缓存键按翻译时的方式计算（提示词、术语表、语气等）
+//
+//	GET    按 text、source_lang、target_lang 查找缓存，可带 provider、model、formality、tag_handling 参数
+//	POST   写入或修正缓存条目，用于人工修正译文或导入已确认的译文
+//	DELETE 带 text 参数时删除单条缓存；带 source_lang、target_lang、provider、model 中的任意参数时删除满足条件的缓存；
+//	       all=true 时清空所有译文缓存
+func (h *AdminHandler) HandleCache(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(r) {
+		h.sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.lookupCache(w, r)
+	case http.MethodPost, http.MethodPut:
+		h.importCache(w, r)
+	case http.MethodDelete:
+		h.deleteCache(w, r)
+	default:
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// cacheRequest 从查询参数构造用于计算缓存键的翻译请求
+func cacheRequest(r *http.Request) service.TranslateRequest {
+	query := r.URL.Query()
+	return service.TranslateRequest{
+		Text:        query.Get("text"),
+		SourceLang:  query.Get("source_lang"),
+		TargetLang:  query.Get("target_lang"),
+		Provider:    query.Get("provider"),
+		Model:       query.Get("model"),
+		Formality:   query.Get("formality"),
+		TagHandling: query.Get("tag_handling"),
+	}
+}
+
+// validateCacheRequest 校验单条缓存请求：text 与 target_lang 必填，source_lang 可为空或 auto
+func (h *AdminHandler) validateCacheRequest(req service.TranslateRequest) error {
+	if req.Text == "" {
+		return errors.New("text is required")
+	}
+	if req.TargetLang == "" {
+		return errors.New("target_lang is required")
+	}
+	if !h.translationService.ValidateLanguage(utils.BaseLanguage(utils.NormalizeLanguageCode(req.TargetLang))) {
+		return fmt.Errorf("invalid target_lang %q", req.TargetLang)
+	}
+	if src := utils.NormalizeLanguageCode(req.SourceLang); src != "" && !h.translationService.ValidateLanguage(utils.BaseLanguage(src)) {
+		return fmt.Errorf("invalid source_lang %q", req.SourceLang)
+	}
+	if (req.Provider == "") != (req.Model == "") {
+		return errors.New("provider and model must be specified together")
+	}
+	if req.TagHandling != "" && req.TagHandling != "html" && req.TagHandling != "xml" {
+		return errors.New("tag_handling must be html or xml")
+	}
+	return nil
+}
+
+func (h *AdminHandler) lookupCache(w http.ResponseWriter, r *http.Request) {
+	req := cacheRequest(r)
+	if err := h.validateCacheRequest(req); err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cached, err := h.translationService.CacheLookup(r.Context(), h.prompts, req)
+	if err != nil {
+		h.sendCacheError(w, err)
+		return
+	}
+	if cached == nil {
+		h.sendError(w, "Cache entry not found", http.StatusNotFound)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, CacheLookupResponse{
+		Text:              req.Text,
+		SourceLang:        req.SourceLang,
+		TargetLang:        req.TargetLang,
+		CachedTranslation: cached,
+	})
+}
+
+func (h *AdminHandler) importCache(w http.ResponseWriter, r *http.Request) {
+	var body CacheImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(body.Entries) == 0 {
+		h.sendError(w, "entries is required", http.StatusBadRequest)
+		return
+	}
+	if len(body.Entries) > maxCacheImportEntries {
+		h.sendError(w, fmt.Sprintf("too many entries, at most %d are allowed", maxCacheImportEntries), http.StatusBadRequest)
+		return
+	}
+	if body.TTL < -1 {
+		h.sendError(w, "ttl must be -1, 0 or a positive number of seconds", http.StatusBadRequest)
+		return
+	}
+
+	// 先校验所有条目，避免只写入一部分
+	requests := make([]service.TranslateRequest, len(body.Entries))
+	for i, entry := range body.Entries {
+		requests[i] = service.TranslateRequest{
+			Text:        entry.Text,
+			SourceLang:  entry.SourceLang,
+			TargetLang:  entry.TargetLang,
+			Provider:    entry.Provider,
+			Model:       entry.Model,
+			Formality:   entry.Formality,
+			TagHandling: entry.TagHandling,
+		}
+		if err := h.validateCacheRequest(requests[i]); err != nil {
+			h.sendError(w, fmt.Sprintf("entries[%d]: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		if entry.Translation == "" {
+			h.sendError(w, fmt.Sprintf("entries[%d]: translation is required", i), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ttl := time.Duration(body.TTL) * time.Second
+	keys := make([]string, 0, len(requests))
+	for i, req := range requests {
+		key, err := h.translationService.CacheStore(r.Context(), h.prompts, req, cache.CacheEntry{
+			Translation: body.Entries[i].Translation,
+			Provider:    req.Provider,
+			Model:       req.Model,
+		}, ttl)
+		if err != nil {
+			h.sendCacheError(w, fmt.Errorf("entries[%d]: %w", i, err))
+			return
+		}
+		keys = append(keys, key)
+	}
+	h.sendJSON(w, http.StatusOK, struct {
+		Updated int      `json:"updated"`
+		Keys    []string `json:"keys"`
+	}{
+		Updated: len(keys),
+		Keys:    keys,
+	})
+}
+
+func (h *AdminHandler) deleteCache(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := cacheRequest(r)
+
+	switch {
+	case query.Get("all") == "true":
+		if err := h.translationService.CacheClear(r.Context()); err != nil {
+			h.sendCacheError(w, err)
+			return
+		}
+		h.sendJSON(w, http.StatusOK, map[string]bool{"cleared": true})
+
+	case req.Text != "":
+		if err := h.validateCacheRequest(req); err != nil {
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		count, err := h.translationService.CacheDelete(r.Context(), h.prompts, req)
+		if err != nil {
+			h.sendCacheError(w, err)
+			return
+		}
+		h.sendJSON(w, http.StatusOK, map[string]int{"deleted": count})
+
+	default:
+		count, err := h.translationService.CachePurge(r.Context(), service.CachePurgeFilter{
+			SourceLang: req.SourceLang,
+			TargetLang: req.TargetLang,
+			Provider:   req.Provider,
+			Model:      req.Model,
+		})
+		if err != nil {
+			h.sendCacheError(w, err)
+			return
+		}
+		h.sendJSON(w, http.StatusOK, map[string]int{"deleted": count})
+	}
+}
+
+// HandleCacheStats 返回各缓存层的条目数、占用、命中率与淘汰次数，不提供统计信息的缓存层（如 Redis）不列出
+func (h *AdminHandler) HandleCacheStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorize(r) {
+		h.sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stats, err := h.translationService.CacheStats()
+	if err != nil {
+		h.sendCacheError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, struct {
+		Layers []cache.Stats `json:"layers"`
+	}{
+		Layers: stats,
+	})
+}
+
+// sendCacheError 请求条件不合法或无法按单条管理时返回 400，缓存未启用时返回 404，其余为缓存读写错误
+func (h *AdminHandler) sendCacheError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmptyPurgeFilter), errors.Is(err, service.ErrPurgeByLanguageV1), errors.Is(err, service.ErrPurgeByLanguageLegacy),
+		errors.Is(err, service.ErrCacheNoText), errors.Is(err, service.ErrCacheChunked), errors.Is(err, service.ErrCachePlaceholders):
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrCacheDisabled):
+		h.sendError(w, err.Error(), http.StatusNotFound)
+	default:
+		h.sendError(w, "Cache operation failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
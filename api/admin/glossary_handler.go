@@ -0,0 +1,137 @@
+// api/admin/glossary_handler.go
+package admin
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"transbridge/glossary"
+)
+
+// GlossaryRequest 术语表新增/删除请求
+type GlossaryRequest struct {
+	SourceLang string          `json:"source_lang"` // 源语言，"*" 表示任意语言
+	TargetLang string          `json:"target_lang"` // 目标语言，"*" 表示任意语言
+	Terms      []glossary.Term `json:"terms"`       // 新增或更新的术语
+	Sources    []string        `json:"sources"`     // 删除时指定的原文，为空时删除整个语言对
+}
+
+// GlossaryTermsResponse 单个语言对的术语列表
+type GlossaryTermsResponse struct {
+	SourceLang string          `json:"source_lang"`
+	TargetLang string          `json:"target_lang"`
+	Version    string          `json:"version"`
+	Terms      []glossary.Term `json:"terms"`
+}
+
+// HandleGlossary 管理术语表
+//
+//	GET    列出所有语言对；带 source_lang、target_lang 参数时返回该语言对的术语
+//	POST   新增或更新术语，请求体为 JSON，或带 source_lang、target_lang 参数的 CSV/TSV（text/csv、text/tab-separated-values）
+//	DELETE 删除术语，sources 为空时删除整个语言对
+//
+// 通过接口所做的修改只保存在内存中，不会写回术语文件
+func (h *AdminHandler) HandleGlossary(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(r) {
+		h.sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.listGlossary(w, r)
+	case http.MethodPost, http.MethodPut:
+		h.upsertGlossary(w, r)
+	case http.MethodDelete:
+		h.deleteGlossary(w, r)
+	default:
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *AdminHandler) listGlossary(w http.ResponseWriter, r *http.Request) {
+	sourceLang := r.URL.Query().Get("source_lang")
+	targetLang := r.URL.Query().Get("target_lang")
+	if sourceLang == "" && targetLang == "" {
+		h.sendJSON(w, http.StatusOK, struct {
+			Pairs []glossary.PairInfo `json:"pairs"`
+		}{
+			Pairs: h.glossary.Pairs(),
+		})
+		return
+	}
+
+	terms, err := h.glossary.Terms(sourceLang, targetLang)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, GlossaryTermsResponse{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		Version:    glossary.Fingerprint(terms),
+		Terms:      terms,
+	})
+}
+
+func (h *AdminHandler) upsertGlossary(w http.ResponseWriter, r *http.Request) {
+	var req GlossaryRequest
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv", "text/tab-separated-values":
+		comma := ','
+		if mediaType == "text/tab-separated-values" {
+			comma = '\t'
+		}
+		terms, err := glossary.Parse(r.Body, comma)
+		if err != nil {
+			h.sendError(w, "Invalid glossary file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req = GlossaryRequest{
+			SourceLang: r.URL.Query().Get("source_lang"),
+			TargetLang: r.URL.Query().Get("target_lang"),
+			Terms:      terms,
+		}
+	default:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if len(req.Terms) == 0 {
+		h.sendError(w, "terms is required", http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.glossary.Upsert(req.SourceLang, req.TargetLang, req.Terms)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, map[string]int{"updated": count})
+}
+
+func (h *AdminHandler) deleteGlossary(w http.ResponseWriter, r *http.Request) {
+	req := GlossaryRequest{
+		SourceLang: r.URL.Query().Get("source_lang"),
+		TargetLang: r.URL.Query().Get("target_lang"),
+		Sources:    r.URL.Query()["source"],
+	}
+	if req.SourceLang == "" && req.TargetLang == "" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	count, err := h.glossary.Delete(req.SourceLang, req.TargetLang, req.Sources)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, map[string]int{"deleted": count})
+}
@@ -0,0 +1,192 @@
+// api/admin/memory_handler.go
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"transbridge/tm"
+)
+
+// MemoryRequest 翻译记忆新增/删除请求
+type MemoryRequest struct {
+	SourceLang string     `json:"source_lang"`
+	TargetLang string     `json:"target_lang"`
+	Entries    []tm.Entry `json:"entries"` // 新增或更新的条目
+	Sources    []string   `json:"sources"` // 删除时指定的原文，为空时删除整个语言对
+}
+
+// MemoryEntriesResponse 单个语言对的条目列表
+type MemoryEntriesResponse struct {
+	SourceLang string     `json:"source_lang"`
+	TargetLang string     `json:"target_lang"`
+	Entries    []tm.Entry `json:"entries"`
+}
+
+// MemorySearchResponse 模糊查找结果
+type MemorySearchResponse struct {
+	SourceLang string     `json:"source_lang"`
+	TargetLang string     `json:"target_lang"`
+	Text       string     `json:"text"`
+	Matches    []tm.Match `json:"matches"`
+}
+
+// HandleMemory 管理翻译记忆
+//
+//	GET    列出所有语言对；带 source_lang、target_lang 参数时返回该语言对的条目，再带 text 参数时返回与其相似的条目；
+//	       format=tmx 时导出 TMX，不带语言参数时导出所有语言对
+//	POST   新增或更新条目，请求体为 JSON，或 TMX（application/x-tmx+xml、application/xml、text/xml），
+//	       TMX 可带 source_lang、target_lang 参数指定导入的语言对
+//	DELETE 删除条目，sources 为空时删除整个语言对
+//
+// 通过接口所做的修改只保存在内存中，不会写回 TMX 文件
+func (h *AdminHandler) HandleMemory(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(r) {
+		h.sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("format") == "tmx" {
+			h.exportMemory(w, r)
+		} else {
+			h.listMemory(w, r)
+		}
+	case http.MethodPost, http.MethodPut:
+		h.upsertMemory(w, r)
+	case http.MethodDelete:
+		h.deleteMemory(w, r)
+	default:
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *AdminHandler) listMemory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sourceLang := query.Get("source_lang")
+	targetLang := query.Get("target_lang")
+	if sourceLang == "" && targetLang == "" {
+		h.sendJSON(w, http.StatusOK, struct {
+			Pairs []tm.PairInfo `json:"pairs"`
+		}{
+			Pairs: h.memory.Pairs(),
+		})
+		return
+	}
+
+	text := query.Get("text")
+	if text == "" {
+		entries, err := h.memory.Entries(sourceLang, targetLang)
+		if err != nil {
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.sendJSON(w, http.StatusOK, MemoryEntriesResponse{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			Entries:    entries,
+		})
+		return
+	}
+
+	minScore := 0.5
+	if v := query.Get("min_score"); v != "" {
+		score, err := strconv.ParseFloat(v, 64)
+		if err != nil || score < 0 || score > 1 {
+			h.sendError(w, "min_score must be a number between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		minScore = score
+	}
+	limit := 10
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			h.sendError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	matches := h.memory.Search(sourceLang, targetLang, text, minScore, limit)
+	if matches == nil {
+		matches = []tm.Match{}
+	}
+	h.sendJSON(w, http.StatusOK, MemorySearchResponse{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		Text:       text,
+		Matches:    matches,
+	})
+}
+
+func (h *AdminHandler) exportMemory(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := h.memory.ExportTMX(&buf, r.URL.Query().Get("source_lang"), r.URL.Query().Get("target_lang")); err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tmx+xml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="translation-memory.tmx"`)
+	w.Write(buf.Bytes())
+}
+
+func (h *AdminHandler) upsertMemory(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-tmx+xml", "application/xml", "text/xml":
+		count, err := h.memory.ImportTMX(r.Body, r.URL.Query().Get("source_lang"), r.URL.Query().Get("target_lang"))
+		if err != nil {
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.sendJSON(w, http.StatusOK, map[string]int{"updated": count})
+		return
+	}
+
+	var req MemoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Entries) == 0 {
+		h.sendError(w, "entries is required", http.StatusBadRequest)
+		return
+	}
+
+	for i := range req.Entries {
+		req.Entries[i].Origin = tm.OriginImport
+	}
+	count, err := h.memory.Upsert(req.SourceLang, req.TargetLang, req.Entries)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, map[string]int{"updated": count})
+}
+
+func (h *AdminHandler) deleteMemory(w http.ResponseWriter, r *http.Request) {
+	req := MemoryRequest{
+		SourceLang: r.URL.Query().Get("source_lang"),
+		TargetLang: r.URL.Query().Get("target_lang"),
+		Sources:    r.URL.Query()["source"],
+	}
+	if req.SourceLang == "" && req.TargetLang == "" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	count, err := h.memory.Delete(req.SourceLang, req.TargetLang, req.Sources)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, map[string]int{"deleted": count})
+}
@@ -0,0 +1,30 @@
+// api/compat/types.go
+package compat
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// StringList 既可以是字符串也可以是字符串数组的参数，如 Google Translate v2 与 LibreTranslate 的 q 参数
+type StringList struct {
+	Values  []string
+	IsBatch bool // 请求中为数组
+}
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		l.Values = []string{single}
+		l.IsBatch = false
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("q must be a string or an array of strings")
+	}
+	l.Values = list
+	l.IsBatch = true
+	return nil
+}
@@ -0,0 +1,227 @@
+// api/deepl/handler.go
+package deepl
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"transbridge/internal/utils"
+	"transbridge/service"
+)
+
+// maxTexts 单次请求最多允许的文本条数（与 DeepL 官方限制一致）
+const maxTexts = 50
+
+type Handler struct {
+	translationService *service.TranslationService
+	authTokens         map[string]bool
+	prompts            *utils.PromptSet
+}
+
+type HandlerConfig struct {
+	AuthTokens []string // 配置中的 API 密钥列表
+	Prompts    *utils.PromptSet
+}
+
+func NewHandler(translationService *service.TranslationService, config HandlerConfig) *Handler {
+	authTokens := make(map[string]bool)
+	for _, token := range config.AuthTokens {
+		authTokens[token] = true
+	}
+
+	return &Handler{
+		translationService: translationService,
+		authTokens:         authTokens,
+		prompts:            config.Prompts,
+	}
+}
+
+// HandleTranslate 实现 POST /v2/translate
+func (h *Handler) HandleTranslate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := h.parseTranslateRequest(r)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.authorize(r, req.AuthKey) {
+		h.sendError(w, "Authorization failed. Please supply a valid auth_key parameter.", http.StatusForbidden)
+		return
+	}
+
+	if err := h.validateRequest(req); err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sourceLang := utils.NormalizeLanguageCode(req.SourceLang)
+	targetLang := utils.NormalizeLanguageCode(req.TargetLang)
+	tagHandling := strings.ToLower(req.TagHandling)
+
+	// 与 DeepL 一致，空文本（含只有空白的文本）原样返回，不调用翻译服务
+	resp := TranslateResponse{
+		Translations: make([]Translation, len(req.Text)),
+	}
+	var requests []service.TranslateRequest
+	var indexes []int
+	for i, text := range req.Text {
+		if strings.TrimSpace(text) == "" {
+			resp.Translations[i] = Translation{
+				DetectedSourceLanguage: utils.ExtractLanguageCode(sourceLang),
+				Text:                   text,
+			}
+			continue
+		}
+		requests = append(requests, service.TranslateRequest{
+			Text:        text,
+			SourceLang:  sourceLang,
+			TargetLang:  targetLang,
+			Formality:   strings.ToLower(req.Formality),
+			TagHandling: tagHandling,
+		})
+		indexes = append(indexes, i)
+	}
+
+	results := h.translationService.BatchTranslate(r.Context(), h.prompts, requests)
+	for i, res := range results {
+		if res.Error != nil {
+			h.sendError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		resp.Translations[indexes[i]] = Translation{
+			DetectedSourceLanguage: utils.ExtractLanguageCode(res.Result.SourceLang), // DeepL 的源语言只区分基础语言
+			Text:                   res.Text,
+		}
+	}
+
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+// HandleLanguages 实现 GET/POST /v2/languages，type=target 时返回目标语言列表
+func (h *Handler) HandleLanguages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorize(r, r.FormValue("auth_key")) {
+		h.sendError(w, "Authorization failed. Please supply a valid auth_key parameter.", http.StatusForbidden)
+		return
+	}
+
+	isTarget := r.FormValue("type") == "target"
+	languages := make([]Language, 0, len(utils.SupportedLanguages))
+	for _, code := range utils.SupportedLanguages {
+		name, _ := utils.GetLanguageName(code)
+		languages = append(languages, Language{
+			Language:          deeplLanguageCode(code),
+			Name:              name,
+			SupportsFormality: isTarget,
+		})
+	}
+
+	h.sendJSON(w, http.StatusOK, languages)
+}
+
+// HandleUsage 实现 GET/POST /v2/usage，部分客户端用它校验密钥；本服务不限制字符用量
+func (h *Handler) HandleUsage(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(r, r.FormValue("auth_key")) {
+		h.sendError(w, "Authorization failed. Please supply a valid auth_key parameter.", http.StatusForbidden)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, UsageResponse{
+		CharacterCount: 0,
+		CharacterLimit: 1000000000000,
+	})
+}
+
+// parseTranslateRequest 同时支持 JSON 与表单（application/x-www-form-urlencoded、multipart）请求体
+func (h *Handler) parseTranslateRequest(r *http.Request) (*TranslateRequest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var req TranslateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, errors.New("Invalid request body")
+		}
+		return &req, nil
+	}
+
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			return nil, errors.New("Invalid request body")
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	return &TranslateRequest{
+		Text:        r.Form["text"],
+		SourceLang:  r.FormValue("source_lang"),
+		TargetLang:  r.FormValue("target_lang"),
+		Formality:   r.FormValue("formality"),
+		TagHandling: r.FormValue("tag_handling"),
+		AuthKey:     r.FormValue("auth_key"),
+	}, nil
+}
+
+// validateRequest 验证请求参数
+func (h *Handler) validateRequest(req *TranslateRequest) error {
+	if len(req.Text) == 0 {
+		return errors.New("Parameter 'text' not specified.")
+	}
+	if len(req.Text) > maxTexts {
+		return errors.New("Too many texts: maximum allowed is 50")
+	}
+	if req.TargetLang == "" {
+		return errors.New("Parameter 'target_lang' not specified.")
+	}
+	switch strings.ToLower(req.Formality) {
+	case "", "default", "more", "less", "prefer_more", "prefer_less":
+	default:
+		return errors.New("Value for 'formality' not supported.")
+	}
+	switch strings.ToLower(req.TagHandling) {
+	case "", "html", "xml":
+	default:
+		return errors.New("Value for 'tag_handling' not supported.")
+	}
+	return nil
+}
+
+// authorize 校验密钥，支持 Authorization: DeepL-Auth-Key、Bearer 以及 auth_key 参数
+func (h *Handler) authorize(r *http.Request, authKey string) bool {
+	authHeader := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(authHeader, "DeepL-Auth-Key "):
+		authKey = strings.TrimSpace(strings.TrimPrefix(authHeader, "DeepL-Auth-Key "))
+	case strings.HasPrefix(authHeader, "Bearer "):
+		authKey = strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	case authKey == "":
+		authKey = r.URL.Query().Get("auth_key")
+	}
+	return authKey != "" && h.authTokens[authKey]
+}
+
+// deeplLanguageCode 将内部语言代码转换为 DeepL 使用的大写形式，例如 "zh-Hans" -> "ZH-HANS"
+func deeplLanguageCode(code string) string {
+	return strings.ToUpper(code)
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// sendError 发送 DeepL 风格的错误响应
+func (h *Handler) sendError(w http.ResponseWriter, message string, status int) {
+	h.sendJSON(w, status, ErrorResponse{Message: message})
+}
@@ -0,0 +1,40 @@
+package deepl
+
+// TranslateRequest DeepL v2 翻译请求（JSON 形式；表单形式字段名相同）
+type TranslateRequest struct {
+	Text        []string `json:"text"`
+	SourceLang  string   `json:"source_lang"`
+	TargetLang  string   `json:"target_lang"`
+	Formality   string   `json:"formality"`
+	TagHandling string   `json:"tag_handling"`
+	AuthKey     string   `json:"auth_key"`
+}
+
+// Translation 单条翻译结果
+type Translation struct {
+	DetectedSourceLanguage string `json:"detected_source_language"`
+	Text                   string `json:"text"`
+}
+
+// TranslateResponse DeepL v2 翻译响应
+type TranslateResponse struct {
+	Translations []Translation `json:"translations"`
+}
+
+// Language 语言列表中的条目
+type Language struct {
+	Language          string `json:"language"`
+	Name              string `json:"name"`
+	SupportsFormality bool   `json:"supports_formality"`
+}
+
+// UsageResponse 用量查询响应
+type UsageResponse struct {
+	CharacterCount int64 `json:"character_count"`
+	CharacterLimit int64 `json:"character_limit"`
+}
+
+// ErrorResponse DeepL 风格的错误响应
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
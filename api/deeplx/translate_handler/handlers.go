@@ -6,20 +6,22 @@ import (
 	"net/http"
 	"strings"
 
+	"transbridge/internal/utils"
+	"transbridge/jobs"
 	"transbridge/service"
 )
 
 type Handler struct {
 	translationService *service.TranslationService
-	authTokens         map[string]bool // 存储有效的 API 密钥
-	promptTemplate     string          // 👈 新增
-	maxConcurrent      int             // 批量接口最大并发
+	authTokens         map[string]bool  // 存储有效的 API 密钥
+	prompts            *utils.PromptSet // 👈 新增
+	jobs               *jobs.Manager    // 异步翻译任务，未启用时为 nil
 }
 
 type HandlerConfig struct {
-	AuthTokens     []string // 配置中的 API 密钥列表
-	PromptTemplate string
-	MaxConcurrent  int // 批量接口最大并发（可选）
+	AuthTokens []string         // 配置中的 API 密钥列表
+	Prompts    *utils.PromptSet // 按语言对选择的提示词模板
+	Jobs       *jobs.Manager    // 异步翻译任务管理器（可选）
 }
 
 func NewHandler(translationService *service.TranslationService, config HandlerConfig) *Handler {
@@ -32,8 +34,8 @@ func NewHandler(translationService *service.TranslationService, config HandlerCo
 	return &Handler{
 		translationService: translationService,
 		authTokens:         authTokens,
-		promptTemplate:     config.PromptTemplate, // 👈 设置进去
-		maxConcurrent:      config.MaxConcurrent,
+		prompts:            config.Prompts, // 👈 设置进去
+		jobs:               config.Jobs,
 	}
 }
 
@@ -68,14 +70,27 @@ func (h *Handler) HandleTranslation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 使用翻译服务处理请求
-	translation, err := h.translationService.Translate(r.Context(), "", "", h.promptTemplate, req.Text, req.SourceLang, req.TargetLang)
+	result, err := h.translationService.TranslateWithResult(r.Context(), h.prompts, service.TranslateRequest{
+		Text:        req.Text,
+		SourceLang:  req.SourceLang,
+		TargetLang:  req.TargetLang,
+		TagHandling: strings.ToLower(req.TagHandling),
+	})
 	if err != nil {
 		h.sendError(w, "Translation failed", "translation_failed", http.StatusInternalServerError)
 		return
 	}
 
 	// 发送响应
-	h.sendResponse(w, translation, req.SourceLang, req.TargetLang)
+	h.sendResponse(w, result, detectedSourceLang(result, req.SourceLang), req.TargetLang)
+}
+
+// detectedSourceLang 源语言由自动检测得出时返回检测结果，否则原样返回请求中的源语言
+func detectedSourceLang(result *service.TranslateResult, requested string) string {
+	if result.Detected {
+		return result.SourceLang
+	}
+	return requested
 }
 
 // validateRequest 验证请求参数
@@ -86,16 +101,27 @@ func (h *Handler) validateRequest(req *TranslateRequest) error {
 	if req.TargetLang == "" {
 		return errors.New("target_lang is required")
 	}
-	return nil
+	return validateTagHandling(req.TagHandling)
+}
+
+// validateTagHandling 校验 tag_handling 参数，只支持 html 与 xml
+func validateTagHandling(tagHandling string) error {
+	switch strings.ToLower(tagHandling) {
+	case "", "html", "xml":
+		return nil
+	default:
+		return errors.New("tag_handling must be html or xml")
+	}
 }
 
 // sendResponse 发送成功响应
-func (h *Handler) sendResponse(w http.ResponseWriter, translation, sourceLang, targetLang string) {
+func (h *Handler) sendResponse(w http.ResponseWriter, result *service.TranslateResult, sourceLang, targetLang string) {
 	resp := TranslateResponse{
 		Code:       200,
-		Data:       translation,
+		Data:       result.Text,
 		SourceLang: sourceLang,
 		TargetLang: targetLang,
+		Model:      result.ModelName(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
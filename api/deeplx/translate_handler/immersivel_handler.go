@@ -4,19 +4,25 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
-	"sync"
+
+	"transbridge/jobs"
+	"transbridge/service"
 )
 
 type BatchTranslateRequest struct {
 	SourceLang string   `json:"source_lang"`
 	TargetLang string   `json:"target_lang"`
 	TextList   []string `json:"text_list"`
+
+	TagHandling string `json:"tag_handling,omitempty"` // 可选，html 或 xml：保留文本中的标签，只翻译文本内容
+	CallbackURL string `json:"callback_url,omitempty"` // 可选，改为异步任务执行，结束后 POST 结果到该地址
 }
 
 type BatchTranslateItem struct {
 	Index              int    `json:"index"`
 	DetectedSourceLang string `json:"detected_source_lang"`
 	Text               string `json:"text"`
+	Model              string `json:"model,omitempty"` // 实际给出译文的模型（provider/model）
 	Error              string `json:"error,omitempty"`
 }
 
@@ -54,62 +60,54 @@ func (h *Handler) HandleImmersiveLTranslation(w http.ResponseWriter, r *http.Req
 		h.sendError(w, "source_lang, target_lang and text_list are required", "invalid_request", http.StatusBadRequest)
 		return
 	}
+	if err := validateTagHandling(req.TagHandling); err != nil {
+		h.sendError(w, err.Error(), "invalid_request", http.StatusBadRequest)
+		return
+	}
 	if len(req.TextList) > 50 {
 		h.sendError(w, "Too many texts: maximum allowed is 50", "too_many_texts", http.StatusBadRequest)
 		return
 	}
 
-	type result struct {
-		index int
-		item  *BatchTranslateItem
-	}
-
-	maxConcurrent := h.maxConcurrent
-	if maxConcurrent <= 0 {
-		maxConcurrent = 5
+	if req.CallbackURL != "" {
+		h.submitJob(w, r, jobs.Owner(apiKey), jobs.Request{
+			Type:        jobs.TypeText,
+			Texts:       req.TextList,
+			SourceLang:  req.SourceLang,
+			TargetLang:  req.TargetLang,
+			TagHandling: strings.ToLower(req.TagHandling),
+			CallbackURL: req.CallbackURL,
+		})
+		return
 	}
-	sem := make(chan struct{}, maxConcurrent)
-	resultChan := make(chan result, len(req.TextList))
-	results := make([]*BatchTranslateItem, len(req.TextList))
 
-	var wg sync.WaitGroup
+	// 由翻译服务统一限制并发；启用合并调用时未命中缓存的文本合并为一次模型调用
+	requests := make([]service.TranslateRequest, len(req.TextList))
 	for i, text := range req.TextList {
-		wg.Add(1)
-		go func(idx int, t string) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			translated, err := h.translationService.Translate(r.Context(), "", "", h.promptTemplate, t, req.SourceLang, req.TargetLang)
-			if err != nil {
-				resultChan <- result{
-					index: idx,
-					item: &BatchTranslateItem{
-						Index:              idx,
-						DetectedSourceLang: req.SourceLang,
-						Text:               "",
-						Error:              err.Error(),
-					},
-				}
-				return
-			}
-
-			resultChan <- result{
-				index: idx,
-				item: &BatchTranslateItem{
-					Index:              idx,
-					DetectedSourceLang: req.SourceLang,
-					Text:               translated,
-				},
-			}
-		}(i, text)
+		requests[i] = service.TranslateRequest{
+			Text:        text,
+			SourceLang:  req.SourceLang,
+			TargetLang:  req.TargetLang,
+			TagHandling: strings.ToLower(req.TagHandling),
+		}
 	}
 
-	wg.Wait()
-	close(resultChan)
-
-	for res := range resultChan {
-		results[res.index] = res.item
+	results := make([]*BatchTranslateItem, len(req.TextList))
+	for i, res := range h.translationService.BatchTranslate(r.Context(), h.prompts, requests) {
+		if res.Error != nil {
+			results[i] = &BatchTranslateItem{
+				Index:              i,
+				DetectedSourceLang: req.SourceLang,
+				Error:              res.Error.Error(),
+			}
+			continue
+		}
+		results[i] = &BatchTranslateItem{
+			Index:              i,
+			DetectedSourceLang: detectedSourceLang(res.Result, req.SourceLang),
+			Text:               res.Text,
+			Model:              res.Result.ModelName(),
+		}
 	}
 
 	resp := BatchTranslateResponse{
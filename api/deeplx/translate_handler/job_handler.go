@@ -0,0 +1,145 @@
+package translate_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"transbridge/jobs"
+)
+
+// JobResponse 异步翻译任务状态
+type JobResponse struct {
+	Code       int            `json:"code"`
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	Status     jobs.Status    `json:"status"`
+	Total      int            `json:"total"`     // 需要翻译的文本数，文档类任务为 1
+	Completed  int            `json:"completed"` // 已完成（含失败）的文本数
+	Results    []*jobs.Result `json:"results"`   // 与 texts 一一对应，尚未完成的为 null
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Callback   *jobs.Callback `json:"callback,omitempty"` // 回调投递状态
+}
+
+// HandleJobs 提交异步翻译任务：POST /jobs，立即返回任务 ID
+func (h *Handler) HandleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", "method_not_allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	owner, ok := h.jobOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req jobs.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, "Invalid request body", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	h.submitJob(w, r, owner, req)
+}
+
+// submitJob 创建任务并返回 202；同步接口的请求带 callback_url 时也经由此处改为异步执行
+func (h *Handler) submitJob(w http.ResponseWriter, r *http.Request, owner string, req jobs.Request) {
+	if h.jobs == nil {
+		h.sendError(w, "callback_url requires jobs to be enabled", "invalid_request", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.sendError(w, err.Error(), "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobs.Submit(r.Context(), owner, req)
+	if errors.Is(err, jobs.ErrQueueFull) {
+		h.sendError(w, "Job queue is full", "queue_full", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		h.sendError(w, "Failed to create job", "internal_error", http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJob(w, http.StatusAccepted, job)
+}
+
+// HandleJob 查询或取消任务：GET /jobs/{id} 返回进度与已完成的结果，DELETE /jobs/{id} 取消任务
+func (h *Handler) HandleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		h.sendError(w, "Method not allowed", "method_not_allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	owner, ok := h.jobOwner(w, r)
+	if !ok {
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+	job, err := h.jobs.Get(r.Context(), id)
+	// 其它令牌提交的任务按不存在处理
+	if errors.Is(err, jobs.ErrNotFound) || (err == nil && job.Owner != owner) {
+		h.sendError(w, "Job not found", "not_found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.sendError(w, "Failed to load job", "internal_error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		job, err = h.jobs.Cancel(r.Context(), id)
+		if errors.Is(err, jobs.ErrFinished) {
+			h.sendError(w, "Job already finished", "job_finished", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			h.sendError(w, "Failed to cancel job", "internal_error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.sendJob(w, http.StatusOK, job)
+}
+
+// jobOwner 校验 API Key，返回任务归属（见 jobs.Owner）
+func (h *Handler) jobOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	apiKey := strings.TrimPrefix(authHeader, "Bearer ")
+	if apiKey == "" {
+		apiKey = r.URL.Query().Get("token")
+	}
+	if !h.authTokens[apiKey] {
+		h.sendError(w, "Invalid API key", "unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+
+	return jobs.Owner(apiKey), true
+}
+
+func (h *Handler) sendJob(w http.ResponseWriter, status int, job *jobs.Job) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(JobResponse{
+		Code:       status,
+		ID:         job.ID,
+		Type:       job.Request.Type,
+		Status:     job.Status,
+		Total:      job.Total,
+		Completed:  job.Completed,
+		Results:    job.Results,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+		Callback:   job.Callback,
+	})
+}
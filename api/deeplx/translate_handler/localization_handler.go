@@ -0,0 +1,187 @@
+package translate_handler
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"transbridge/jobs"
+	"transbridge/l10n"
+	"transbridge/service"
+)
+
+// maxLocalizationSize 本地化文件大小上限
+const maxLocalizationSize = 10 << 20
+
+// LocalizationTranslateRequest 本地化文件翻译请求
+type LocalizationTranslateRequest struct {
+	Text       string `json:"text"`             // PO、i18n JSON 或 XLIFF 文件内容
+	Format     string `json:"format,omitempty"` // 可选，po、json 或 xliff，默认按内容推断
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"` // PO、XLIFF 文件已声明目标语言时可省略
+
+	Existing     string `json:"existing,omitempty"`      // 可选，JSON 格式目标语言的现有翻译，已有的键不再翻译
+	IncludeFuzzy bool   `json:"include_fuzzy,omitempty"` // 可选，同时翻译待审校（fuzzy）的条目
+	Retranslate  bool   `json:"retranslate,omitempty"`   // 可选，重新翻译所有条目
+	CallbackURL  string `json:"callback_url,omitempty"`  // 可选，改为异步任务执行，结束后 POST 结果到该地址
+}
+
+// LocalizationTranslateResponse 本地化文件翻译响应
+type LocalizationTranslateResponse struct {
+	Code       int      `json:"code"`
+	Data       string   `json:"data"`
+	Format     string   `json:"format"` // po、json 或 xliff
+	SourceLang string   `json:"source_lang"`
+	TargetLang string   `json:"target_lang"`
+	Entries    int      `json:"entries"`    // 翻译的条目数
+	Skipped    int      `json:"skipped"`    // 已有译文或原文为空而跳过的条目数
+	Fuzzy      []string `json:"fuzzy"`      // 占位符校验未通过、标记为待审校的条目
+	CacheHits  int      `json:"cache_hits"` // 命中缓存的文本数
+}
+
+// localizationContentTypes 直接上传本地化文件时支持的 Content-Type 及对应格式
+var localizationContentTypes = map[string]string{
+	"text/x-gettext-translation": l10n.FormatPO,
+	"text/x-po":                  l10n.FormatPO,
+	"application/x-po":           l10n.FormatPO,
+	"application/x-gettext":      l10n.FormatPO,
+	"application/x-xliff+xml":    l10n.FormatXLIFF,
+	"application/xliff+xml":      l10n.FormatXLIFF,
+}
+
+// localizationResponseTypes 直接上传时响应的 Content-Type
+var localizationResponseTypes = map[string]string{
+	l10n.FormatPO:    "text/x-gettext-translation; charset=utf-8",
+	l10n.FormatJSON:  "application/json; charset=utf-8",
+	l10n.FormatXLIFF: "application/xliff+xml; charset=utf-8",
+}
+
+// HandleLocalizationTranslation 翻译 PO、i18n JSON 与 XLIFF 本地化文件，写回同格式的文件
+// 请求体为 JSON；也可以直接上传文件（PO、XLIFF 的 Content-Type，或在查询字符串中指定 format），
+// 此时参数通过查询字符串传递，响应为翻译后的文件
+func (h *Handler) HandleLocalizationTranslation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", "method_not_allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 验证 API Key
+	authHeader := r.Header.Get("Authorization")
+	apiKey := strings.TrimPrefix(authHeader, "Bearer ")
+	if apiKey == "" {
+		apiKey = r.URL.Query().Get("token")
+	}
+	if !h.authTokens[apiKey] {
+		h.sendError(w, "Invalid API key", "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req LocalizationTranslateRequest
+	query := r.URL.Query()
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format, raw := localizationContentTypes[mediaType]
+	if query.Get("format") != "" {
+		format, raw = query.Get("format"), true
+	}
+	if raw {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxLocalizationSize+1))
+		if err != nil || len(body) > maxLocalizationSize {
+			h.sendError(w, "Invalid request body", "invalid_request", http.StatusBadRequest)
+			return
+		}
+		includeFuzzy, _ := strconv.ParseBool(query.Get("include_fuzzy"))
+		retranslate, _ := strconv.ParseBool(query.Get("retranslate"))
+		req = LocalizationTranslateRequest{
+			Text:         string(body),
+			Format:       format,
+			SourceLang:   query.Get("source_lang"),
+			TargetLang:   query.Get("target_lang"),
+			IncludeFuzzy: includeFuzzy,
+			Retranslate:  retranslate,
+			CallbackURL:  query.Get("callback_url"),
+		}
+	} else if err := json.NewDecoder(io.LimitReader(r.Body, maxLocalizationSize)).Decode(&req); err != nil {
+		h.sendError(w, "Invalid request body", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Text == "" {
+		h.sendError(w, "text is required", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	opts := l10n.Options{
+		Format:       req.Format,
+		Existing:     req.Existing,
+		IncludeFuzzy: req.IncludeFuzzy,
+		Retranslate:  req.Retranslate,
+	}
+	file, err := l10n.Parse(req.Text, opts)
+	if err != nil {
+		h.sendError(w, "Invalid localization file: "+err.Error(), "invalid_request", http.StatusBadRequest)
+		return
+	}
+	if req.TargetLang == "" && file.TargetLanguage() == "" {
+		h.sendError(w, "target_lang is required", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	if req.CallbackURL != "" {
+		h.submitJob(w, r, jobs.Owner(apiKey), jobs.Request{
+			Type:         jobs.TypeLocalization,
+			Text:         req.Text,
+			SourceLang:   req.SourceLang,
+			TargetLang:   req.TargetLang,
+			Format:       req.Format,
+			Existing:     req.Existing,
+			IncludeFuzzy: req.IncludeFuzzy,
+			Retranslate:  req.Retranslate,
+			CallbackURL:  req.CallbackURL,
+		})
+		return
+	}
+
+	result, err := h.translationService.TranslateLocalization(r.Context(), h.prompts, service.TranslateRequest{
+		Text:       req.Text,
+		SourceLang: req.SourceLang,
+		TargetLang: req.TargetLang,
+	}, opts)
+	if err != nil {
+		h.sendError(w, "Translation failed", "translation_failed", http.StatusInternalServerError)
+		return
+	}
+
+	// 未指定源语言时为检测结果或文件中声明的源语言
+	sourceLang := req.SourceLang
+	if result.Detected || sourceLang == "" {
+		sourceLang = result.SourceLang
+	}
+
+	if raw {
+		w.Header().Set("Content-Type", localizationResponseTypes[result.Format])
+		w.Header().Set("X-Source-Lang", sourceLang)
+		w.Header().Set("X-Fuzzy-Entries", strconv.Itoa(len(result.Fuzzy)))
+		io.WriteString(w, result.Text)
+		return
+	}
+
+	fuzzy := result.Fuzzy
+	if fuzzy == nil {
+		fuzzy = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LocalizationTranslateResponse{
+		Code:       200,
+		Data:       result.Text,
+		Format:     result.Format,
+		SourceLang: sourceLang,
+		TargetLang: result.TargetLang,
+		Entries:    result.Segments,
+		Skipped:    result.Skipped,
+		Fuzzy:      fuzzy,
+		CacheHits:  result.CacheHits,
+	})
+}
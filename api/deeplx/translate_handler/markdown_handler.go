@@ -0,0 +1,97 @@
+package translate_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"transbridge/jobs"
+	"transbridge/markdown"
+	"transbridge/service"
+)
+
+// MarkdownTranslateRequest Markdown 文档翻译请求
+type MarkdownTranslateRequest struct {
+	Text       string `json:"text"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+
+	FrontMatterKeys []string `json:"front_matter_keys,omitempty"` // 可选，需要翻译的 front matter 字段，默认 title、description、summary
+	CallbackURL     string   `json:"callback_url,omitempty"`      // 可选，改为异步任务执行，结束后 POST 结果到该地址
+}
+
+// MarkdownTranslateResponse Markdown 文档翻译响应
+type MarkdownTranslateResponse struct {
+	Code       int    `json:"code"`
+	Data       string `json:"data"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Segments   int    `json:"segments"`   // 翻译的正文片段数
+	CacheHits  int    `json:"cache_hits"` // 命中缓存的片段数
+}
+
+// HandleMarkdownTranslation 翻译 Markdown 文档，只翻译正文并保留文档结构
+func (h *Handler) HandleMarkdownTranslation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", "method_not_allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 验证 API Key
+	authHeader := r.Header.Get("Authorization")
+	apiKey := strings.TrimPrefix(authHeader, "Bearer ")
+	if apiKey == "" {
+		apiKey = r.URL.Query().Get("token")
+	}
+	if !h.authTokens[apiKey] {
+		h.sendError(w, "Invalid API key", "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req MarkdownTranslateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, "Invalid request body", "invalid_request", http.StatusBadRequest)
+		return
+	}
+	if req.Text == "" || req.TargetLang == "" {
+		h.sendError(w, "text and target_lang are required", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	if req.CallbackURL != "" {
+		h.submitJob(w, r, jobs.Owner(apiKey), jobs.Request{
+			Type:            jobs.TypeMarkdown,
+			Text:            req.Text,
+			SourceLang:      req.SourceLang,
+			TargetLang:      req.TargetLang,
+			FrontMatterKeys: req.FrontMatterKeys,
+			CallbackURL:     req.CallbackURL,
+		})
+		return
+	}
+
+	result, err := h.translationService.TranslateMarkdown(r.Context(), h.prompts, service.TranslateRequest{
+		Text:       req.Text,
+		SourceLang: req.SourceLang,
+		TargetLang: req.TargetLang,
+	}, markdown.Options{FrontMatterKeys: req.FrontMatterKeys})
+	if err != nil {
+		h.sendError(w, "Translation failed", "translation_failed", http.StatusInternalServerError)
+		return
+	}
+
+	sourceLang := req.SourceLang
+	if result.Detected {
+		sourceLang = result.SourceLang
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MarkdownTranslateResponse{
+		Code:       200,
+		Data:       result.Text,
+		SourceLang: sourceLang,
+		TargetLang: req.TargetLang,
+		Segments:   result.Segments,
+		CacheHits:  result.CacheHits,
+	})
+}
@@ -0,0 +1,144 @@
+package translate_handler
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"transbridge/jobs"
+	"transbridge/service"
+	"transbridge/subtitle"
+)
+
+// maxSubtitleSize 字幕文件大小上限
+const maxSubtitleSize = 10 << 20
+
+// SubtitleTranslateRequest 字幕翻译请求
+type SubtitleTranslateRequest struct {
+	Text       string `json:"text"` // SRT 或 WebVTT 字幕内容
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Bilingual  bool   `json:"bilingual,omitempty"` // 可选，输出原文在上、译文在下的双语字幕
+
+	CallbackURL string `json:"callback_url,omitempty"` // 可选，改为异步任务执行，结束后 POST 结果到该地址
+}
+
+// SubtitleTranslateResponse 字幕翻译响应
+type SubtitleTranslateResponse struct {
+	Code       int    `json:"code"`
+	Data       string `json:"data"`
+	Format     string `json:"format"` // srt 或 vtt
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Cues       int    `json:"cues"`       // 翻译的字幕条数
+	CacheHits  int    `json:"cache_hits"` // 命中缓存的字幕条数
+}
+
+// subtitleContentTypes 直接上传字幕文件时支持的 Content-Type
+var subtitleContentTypes = map[string]bool{
+	"text/vtt":             true,
+	"application/x-subrip": true,
+	"text/srt":             true,
+	"text/plain":           true,
+}
+
+// HandleSubtitleTranslation 翻译 SRT/WebVTT 字幕，保留序号、时间轴与样式标签
+// 请求体为 JSON；也可以直接上传字幕文件（Content-Type 为 text/vtt、application/x-subrip 等），
+// 此时参数通过查询字符串传递，响应为同格式的字幕文件
+func (h *Handler) HandleSubtitleTranslation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", "method_not_allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 验证 API Key
+	authHeader := r.Header.Get("Authorization")
+	apiKey := strings.TrimPrefix(authHeader, "Bearer ")
+	if apiKey == "" {
+		apiKey = r.URL.Query().Get("token")
+	}
+	if !h.authTokens[apiKey] {
+		h.sendError(w, "Invalid API key", "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SubtitleTranslateRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	raw := subtitleContentTypes[mediaType]
+	if raw {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSubtitleSize+1))
+		if err != nil || len(body) > maxSubtitleSize {
+			h.sendError(w, "Invalid request body", "invalid_request", http.StatusBadRequest)
+			return
+		}
+		query := r.URL.Query()
+		bilingual, _ := strconv.ParseBool(query.Get("bilingual"))
+		req = SubtitleTranslateRequest{
+			Text:        string(body),
+			SourceLang:  query.Get("source_lang"),
+			TargetLang:  query.Get("target_lang"),
+			Bilingual:   bilingual,
+			CallbackURL: query.Get("callback_url"),
+		}
+	} else if err := json.NewDecoder(io.LimitReader(r.Body, maxSubtitleSize)).Decode(&req); err != nil {
+		h.sendError(w, "Invalid request body", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Text == "" || req.TargetLang == "" {
+		h.sendError(w, "text and target_lang are required", "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	if req.CallbackURL != "" {
+		h.submitJob(w, r, jobs.Owner(apiKey), jobs.Request{
+			Type:        jobs.TypeSubtitles,
+			Text:        req.Text,
+			SourceLang:  req.SourceLang,
+			TargetLang:  req.TargetLang,
+			Bilingual:   req.Bilingual,
+			CallbackURL: req.CallbackURL,
+		})
+		return
+	}
+
+	result, err := h.translationService.TranslateSubtitles(r.Context(), h.prompts, service.TranslateRequest{
+		Text:       req.Text,
+		SourceLang: req.SourceLang,
+		TargetLang: req.TargetLang,
+	}, req.Bilingual)
+	if err != nil {
+		h.sendError(w, "Translation failed", "translation_failed", http.StatusInternalServerError)
+		return
+	}
+
+	sourceLang := req.SourceLang
+	if result.Detected {
+		sourceLang = result.SourceLang
+	}
+
+	if raw {
+		contentType := "application/x-subrip; charset=utf-8"
+		if result.Format == subtitle.FormatVTT {
+			contentType = "text/vtt; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Source-Lang", sourceLang)
+		io.WriteString(w, result.Text)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SubtitleTranslateResponse{
+		Code:       200,
+		Data:       result.Text,
+		Format:     result.Format,
+		SourceLang: sourceLang,
+		TargetLang: req.TargetLang,
+		Cues:       result.Segments,
+		CacheHits:  result.CacheHits,
+	})
+}
@@ -5,6 +5,8 @@ type TranslateRequest struct {
 	Text       string `json:"text"`
 	SourceLang string `json:"source_lang"`
 	TargetLang string `json:"target_lang"`
+
+	TagHandling string `json:"tag_handling,omitempty"` // 可选，html 或 xml：保留文本中的标签，只翻译文本内容
 }
 
 // TranslateResponse 定义响应体结构
@@ -16,4 +18,5 @@ type TranslateResponse struct {
 	Method       string   `json:"method"`
 	SourceLang   string   `json:"source_lang"`
 	TargetLang   string   `json:"target_lang"`
+	Model        string   `json:"model,omitempty"` // 实际给出译文的模型（provider/model）
 }
@@ -0,0 +1,222 @@
+// api/google/handler.go
+package google
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"transbridge/internal/utils"
+	"transbridge/service"
+)
+
+// maxSegments 单次请求最多允许的 q 条数（与 Google 官方限制一致）
+const maxSegments = 128
+
+type Handler struct {
+	translationService *service.TranslationService
+	authTokens         map[string]bool
+	prompts            *utils.PromptSet
+}
+
+type HandlerConfig struct {
+	AuthTokens []string // 配置中的 API 密钥列表
+	Prompts    *utils.PromptSet
+}
+
+func NewHandler(translationService *service.TranslationService, config HandlerConfig) *Handler {
+	authTokens := make(map[string]bool)
+	for _, token := range config.AuthTokens {
+		authTokens[token] = true
+	}
+
+	return &Handler{
+		translationService: translationService,
+		authTokens:         authTokens,
+		prompts:            config.Prompts,
+	}
+}
+
+// HandleTranslate 实现 POST /language/translate/v2
+func (h *Handler) HandleTranslate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		h.sendError(w, "Method not allowed", "methodNotAllowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 密钥也可以放在 JSON 请求体中，先解析请求再校验
+	req, err := h.parseTranslateRequest(r)
+	if !h.authorize(r, req.Key) {
+		h.sendError(w, "API key not valid. Please pass a valid API key.", "keyInvalid", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		h.sendError(w, err.Error(), "invalid", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateRequest(req); err != nil {
+		h.sendError(w, err.Error(), "invalid", http.StatusBadRequest)
+		return
+	}
+
+	sourceLang := utils.NormalizeLanguageCode(req.Source)
+	targetLang := utils.NormalizeLanguageCode(req.Target)
+
+	// 与 Google 不同，未指定 format 时按纯文本处理，避免对普通文本做 HTML 转义
+	var tagHandling string
+	if strings.ToLower(req.Format) == "html" {
+		tagHandling = "html"
+	}
+
+	requests := make([]service.TranslateRequest, len(req.Q.Values))
+	for i, q := range req.Q.Values {
+		requests[i] = service.TranslateRequest{
+			Text:        q,
+			SourceLang:  sourceLang,
+			TargetLang:  targetLang,
+			TagHandling: tagHandling,
+		}
+	}
+
+	results := h.translationService.BatchTranslate(r.Context(), h.prompts, requests)
+
+	var resp TranslateResponse
+	resp.Data.Translations = make([]Translation, len(results))
+	for i, res := range results {
+		if res.Error != nil {
+			h.sendError(w, "Translation failed", "backendError", http.StatusInternalServerError)
+			return
+		}
+		resp.Data.Translations[i] = Translation{TranslatedText: res.Text}
+		if sourceLang == "" {
+			resp.Data.Translations[i].DetectedSourceLanguage = res.Result.SourceLang
+		}
+	}
+
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+// HandleLanguages 实现 GET /language/translate/v2/languages，指定 target 时返回该语言下的语言名称
+func (h *Handler) HandleLanguages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", "methodNotAllowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorize(r, r.FormValue("key")) {
+		h.sendError(w, "API key not valid. Please pass a valid API key.", "keyInvalid", http.StatusForbidden)
+		return
+	}
+
+	target := r.FormValue("target")
+
+	var resp LanguagesResponse
+	resp.Data.Languages = make([]Language, 0, len(utils.SupportedLanguages))
+	for _, code := range utils.SupportedLanguages {
+		lang := Language{Language: code}
+		if target != "" {
+			lang.Name, _ = utils.GetLanguageNameIn(code, target)
+		}
+		resp.Data.Languages = append(resp.Data.Languages, lang)
+	}
+
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+// parseTranslateRequest 合并查询参数与请求体（JSON 或表单）中的参数
+// 请求体无效时仍返回非 nil 的请求并尽量带上 key，供调用方先校验密钥
+func (h *Handler) parseTranslateRequest(r *http.Request) (*TranslateRequest, error) {
+	req := &TranslateRequest{}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Method == http.MethodPost && mediaType == "application/json" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return &TranslateRequest{Key: r.URL.Query().Get("key")}, errors.New("Invalid request body")
+		}
+		if err := json.Unmarshal(body, req); err != nil {
+			// q 等字段类型错误时仍尽量取出密钥，未授权的请求返回 403 而不是 400
+			var keyOnly struct {
+				Key string `json:"key"`
+			}
+			json.Unmarshal(body, &keyOnly)
+			if keyOnly.Key == "" {
+				keyOnly.Key = r.URL.Query().Get("key")
+			}
+			return &TranslateRequest{Key: keyOnly.Key}, errors.New("Invalid JSON payload received.")
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return &TranslateRequest{Key: r.URL.Query().Get("key")}, errors.New("Invalid request body")
+	}
+
+	// 查询参数（以及表单）中的值作为补充
+	query := r.URL.Query()
+	if r.Form != nil {
+		query = r.Form
+	}
+	req.Q.Values = append(req.Q.Values, query["q"]...)
+	if req.Key == "" {
+		req.Key = query.Get("key")
+	}
+	if req.Source == "" {
+		req.Source = query.Get("source")
+	}
+	if req.Target == "" {
+		req.Target = query.Get("target")
+	}
+	if req.Format == "" {
+		req.Format = query.Get("format")
+	}
+	return req, nil
+}
+
+// validateRequest 验证请求参数
+func (h *Handler) validateRequest(req *TranslateRequest) error {
+	if len(req.Q.Values) == 0 {
+		return errors.New("Required Text")
+	}
+	if len(req.Q.Values) > maxSegments {
+		return errors.New("Too many text segments")
+	}
+	if req.Target == "" {
+		return errors.New("Required Target")
+	}
+	switch strings.ToLower(req.Format) {
+	case "", "text", "html":
+	default:
+		return errors.New("Invalid Value")
+	}
+	return nil
+}
+
+// authorize 校验 key 参数（查询参数、表单或 JSON 请求体），也兼容 Authorization: Bearer
+func (h *Handler) authorize(r *http.Request, key string) bool {
+	if key == "" {
+		key = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if key == "" {
+		key = r.Header.Get("X-Goog-Api-Key")
+	}
+	return key != "" && h.authTokens[key]
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// sendError 发送 Google 风格的错误响应
+func (h *Handler) sendError(w http.ResponseWriter, message, reason string, status int) {
+	var resp ErrorResponse
+	resp.Error.Code = status
+	resp.Error.Message = message
+	resp.Error.Errors = []ErrorDetail{{
+		Message: message,
+		Domain:  "global",
+		Reason:  reason,
+	}}
+	h.sendJSON(w, status, resp)
+}
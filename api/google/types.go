@@ -0,0 +1,57 @@
+package google
+
+import "transbridge/api/compat"
+
+// TranslateRequest Google Translate v2 翻译请求（JSON 形式；查询参数与表单字段名相同）
+// q 可以是字符串或字符串数组
+type TranslateRequest struct {
+	Q      compat.StringList `json:"q"`
+	Source string            `json:"source"`
+	Target string            `json:"target"`
+	Format string            `json:"format"`
+	Model  string            `json:"model"`
+	Key    string            `json:"key"`
+}
+
+// Translation 单条翻译结果
+type Translation struct {
+	TranslatedText         string `json:"translatedText"`
+	DetectedSourceLanguage string `json:"detectedSourceLanguage,omitempty"`
+	Model                  string `json:"model,omitempty"`
+}
+
+// TranslateResponse Google v2 翻译响应
+type TranslateResponse struct {
+	Data struct {
+		Translations []Translation `json:"translations"`
+	} `json:"data"`
+}
+
+// Language 语言列表中的条目
+type Language struct {
+	Language string `json:"language"`
+	Name     string `json:"name,omitempty"`
+}
+
+// LanguagesResponse Google v2 语言列表响应
+type LanguagesResponse struct {
+	Data struct {
+		Languages []Language `json:"languages"`
+	} `json:"data"`
+}
+
+// ErrorDetail 错误详情
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Domain  string `json:"domain"`
+	Reason  string `json:"reason"`
+}
+
+// ErrorResponse Google 风格的错误响应
+type ErrorResponse struct {
+	Error struct {
+		Code    int           `json:"code"`
+		Message string        `json:"message"`
+		Errors  []ErrorDetail `json:"errors"`
+	} `json:"error"`
+}
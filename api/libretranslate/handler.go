@@ -0,0 +1,293 @@
+// api/libretranslate/handler.go
+package libretranslate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"transbridge/api/compat"
+	"transbridge/internal/utils"
+	"transbridge/service"
+)
+
+// maxAlternatives 单条文本最多返回的备选译文数量
+const maxAlternatives = 3
+
+// maxAlternativesTexts 请求备选译文时 q 最多包含的文本数；每条文本的备选译文都需要逐个调用其它模型，且不读写缓存
+const maxAlternativesTexts = 10
+
+type Handler struct {
+	translationService *service.TranslationService
+	authTokens         map[string]bool
+	prompts            *utils.PromptSet
+	allowAnonymous     bool
+}
+
+type HandlerConfig struct {
+	AuthTokens     []string // 配置中的 API 密钥列表
+	Prompts        *utils.PromptSet
+	AllowAnonymous bool // 是否允许不带 api_key 访问
+}
+
+func NewHandler(translationService *service.TranslationService, config HandlerConfig) *Handler {
+	authTokens := make(map[string]bool)
+	for _, token := range config.AuthTokens {
+		authTokens[token] = true
+	}
+
+	return &Handler{
+		translationService: translationService,
+		authTokens:         authTokens,
+		prompts:            config.Prompts,
+		allowAnonymous:     config.AllowAnonymous,
+	}
+}
+
+// HandleTranslate 实现 POST /translate
+func (h *Handler) HandleTranslate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := h.parseTranslateRequest(r)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.authorize(req.APIKey) {
+		h.sendError(w, "Invalid API key", http.StatusForbidden)
+		return
+	}
+	if err := h.validateRequest(req); err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	targetLang := utils.NormalizeLanguageCode(req.Target)
+	sourceLang := utils.NormalizeLanguageCode(req.Source)
+	var tagHandling string
+	if strings.ToLower(req.Format) == "html" {
+		tagHandling = "html"
+	}
+
+	// source 为 auto 时由翻译服务逐条检测语言
+	texts := req.Q.Values
+	requests := make([]service.TranslateRequest, len(texts))
+	for i, text := range texts {
+		requests[i] = service.TranslateRequest{
+			Text:        text,
+			SourceLang:  sourceLang,
+			TargetLang:  targetLang,
+			TagHandling: tagHandling,
+		}
+	}
+
+	results := h.translationService.BatchTranslate(r.Context(), h.prompts, requests)
+
+	numAlternatives := req.Alternatives
+	if numAlternatives > maxAlternatives {
+		numAlternatives = maxAlternatives
+	}
+
+	translated := make([]string, len(results))
+	primaries := make([]*service.TranslateResult, len(results))
+	detected := make([]*DetectedLanguage, len(results))
+	for i, res := range results {
+		if res.Error != nil {
+			h.sendError(w, "Translation failed", http.StatusInternalServerError)
+			return
+		}
+		translated[i] = res.Text
+		primaries[i] = res.Result
+		if sourceLang == "" {
+			detected[i] = &DetectedLanguage{Confidence: res.Result.DetectConfidence * 100, Language: res.Result.SourceLang}
+			requests[i].SourceLang = res.Result.SourceLang
+		}
+	}
+
+	var alternatives [][]string
+	if numAlternatives > 0 {
+		alternatives = h.translationService.BatchAlternatives(r.Context(), h.prompts, requests, primaries, numAlternatives)
+		for i := range alternatives {
+			if alternatives[i] == nil {
+				alternatives[i] = []string{}
+			}
+		}
+	}
+
+	if !req.Q.IsBatch {
+		resp := TranslateResponse{
+			TranslatedText:   translated[0],
+			DetectedLanguage: detected[0],
+		}
+		if numAlternatives > 0 {
+			resp.Alternatives = alternatives[0]
+		}
+		h.sendJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	resp := BatchTranslateResponse{TranslatedText: translated}
+	if sourceLang == "" {
+		resp.DetectedLanguage = make([]DetectedLanguage, len(detected))
+		for i, d := range detected {
+			resp.DetectedLanguage[i] = *d
+		}
+	}
+	if numAlternatives > 0 {
+		resp.Alternatives = alternatives
+	}
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+// HandleDetect 实现 POST /detect
+func (h *Handler) HandleDetect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DetectRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := parseForm(r, mediaType); err != nil {
+			h.sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Q = r.FormValue("q")
+		req.APIKey = r.FormValue("api_key")
+	}
+
+	if !h.authorize(req.APIKey) {
+		h.sendError(w, "Invalid API key", http.StatusForbidden)
+		return
+	}
+	if strings.TrimSpace(req.Q) == "" {
+		h.sendError(w, "Invalid request: missing q parameter", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.translationService.DetectLanguage(r.Context(), req.Q)
+	if err != nil {
+		h.sendError(w, "Language detection failed", http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, []DetectedLanguage{{
+		Confidence: result.Confidence * 100,
+		Language:   result.Language,
+	}})
+}
+
+// HandleLanguages 实现 GET /languages，任意语言之间都可以互译
+func (h *Handler) HandleLanguages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	languages := make([]Language, 0, len(utils.SupportedLanguages))
+	for _, code := range utils.SupportedLanguages {
+		name, _ := utils.GetLanguageName(code)
+		languages = append(languages, Language{
+			Code:    code,
+			Name:    name,
+			Targets: utils.SupportedLanguages,
+		})
+	}
+
+	h.sendJSON(w, http.StatusOK, languages)
+}
+
+// parseTranslateRequest 同时支持 JSON 与表单请求体
+func (h *Handler) parseTranslateRequest(r *http.Request) (*TranslateRequest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var req TranslateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, errors.New("Invalid request body")
+		}
+		return &req, nil
+	}
+
+	if err := parseForm(r, mediaType); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	req := &TranslateRequest{
+		Q:      compat.StringList{Values: r.Form["q"], IsBatch: len(r.Form["q"]) > 1},
+		Source: r.FormValue("source"),
+		Target: r.FormValue("target"),
+		Format: r.FormValue("format"),
+		APIKey: r.FormValue("api_key"),
+	}
+	if v := r.FormValue("alternatives"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("Invalid request: alternatives must be an integer")
+		}
+		req.Alternatives = n
+	}
+	return req, nil
+}
+
+// validateRequest 验证请求参数
+func (h *Handler) validateRequest(req *TranslateRequest) error {
+	if len(req.Q.Values) == 0 {
+		return errors.New("Invalid request: missing q parameter")
+	}
+	for _, q := range req.Q.Values {
+		if strings.TrimSpace(q) == "" {
+			return errors.New("Invalid request: missing q parameter")
+		}
+	}
+	if req.Target == "" {
+		return errors.New("Invalid request: missing target parameter")
+	}
+	switch strings.ToLower(req.Format) {
+	case "", "text", "html":
+	default:
+		return errors.New("Invalid request: format must be text or html")
+	}
+	if req.Alternatives > 0 && len(req.Q.Values) > maxAlternativesTexts {
+		return fmt.Errorf("Invalid request: alternatives is limited to %d texts per request", maxAlternativesTexts)
+	}
+	return nil
+}
+
+// authorize 校验 api_key；允许匿名访问时不校验
+func (h *Handler) authorize(apiKey string) bool {
+	if h.allowAnonymous {
+		return true
+	}
+	return apiKey != "" && h.authTokens[apiKey]
+}
+
+func parseForm(r *http.Request, mediaType string) error {
+	if mediaType == "multipart/form-data" {
+		return r.ParseMultipartForm(10 << 20)
+	}
+	return r.ParseForm()
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// sendError 发送 LibreTranslate 风格的错误响应
+func (h *Handler) sendError(w http.ResponseWriter, message string, status int) {
+	h.sendJSON(w, status, ErrorResponse{Error: message})
+}
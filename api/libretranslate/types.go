@@ -0,0 +1,51 @@
+package libretranslate
+
+import "transbridge/api/compat"
+
+// TranslateRequest LibreTranslate 翻译请求
+type TranslateRequest struct {
+	Q            compat.StringList `json:"q"`
+	Source       string            `json:"source"`
+	Target       string            `json:"target"`
+	Format       string            `json:"format"`
+	APIKey       string            `json:"api_key"`
+	Alternatives int               `json:"alternatives"`
+}
+
+// DetectRequest LibreTranslate 语言检测请求
+type DetectRequest struct {
+	Q      string `json:"q"`
+	APIKey string `json:"api_key"`
+}
+
+// DetectedLanguage 检测到的语言，confidence 取值 0~100
+type DetectedLanguage struct {
+	Confidence float64 `json:"confidence"`
+	Language   string  `json:"language"`
+}
+
+// TranslateResponse 单条文本的翻译响应
+type TranslateResponse struct {
+	TranslatedText   string            `json:"translatedText"`
+	DetectedLanguage *DetectedLanguage `json:"detectedLanguage,omitempty"`
+	Alternatives     []string          `json:"alternatives,omitempty"`
+}
+
+// BatchTranslateResponse q 为数组时的翻译响应
+type BatchTranslateResponse struct {
+	TranslatedText   []string           `json:"translatedText"`
+	DetectedLanguage []DetectedLanguage `json:"detectedLanguage,omitempty"`
+	Alternatives     [][]string         `json:"alternatives,omitempty"`
+}
+
+// Language 语言列表中的条目
+type Language struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Targets []string `json:"targets"`
+}
+
+// ErrorResponse LibreTranslate 风格的错误响应
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -4,8 +4,11 @@ package openai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"transbridge/translator"
 
@@ -69,15 +72,17 @@ func (h *OpenAIHandler) HandleChatCompletion(w http.ResponseWriter, r *http.Requ
 		model = h.modelManager.GetDefaultModel()
 	}
 
-	// 尝试获取 OpenAI 翻译器
-	openaiTranslator, ok := model.(*translator.OpenAITranslator)
+	// 获取支持聊天完成的实现（OpenAI 直接透传，Ollama 做格式转换）
+	chatCompletion, ok := translator.NewChatCompleter(model)
 	if !ok {
-		h.sendError(w, fmt.Sprintf("Model %s/%s is not an OpenAI model", providerName, modelName), "invalid_model", http.StatusBadRequest)
+		h.sendError(w, fmt.Sprintf("Model %s/%s does not support chat completion", providerName, modelName), "invalid_model", http.StatusBadRequest)
 		return
 	}
 
-	// 创建聊天完成实例
-	chatCompletion := translator.NewOpenAIChatCompletion(openaiTranslator)
+	if req.Stream {
+		h.streamChatCompletion(w, r, chatCompletion, req)
+		return
+	}
 
 	// 处理请求
 	openaiResp, err := chatCompletion.CreateChatCompletion(r.Context(), req)
@@ -91,6 +96,64 @@ func (h *OpenAIHandler) HandleChatCompletion(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(openaiResp)
 }
 
+// streamChatCompletion 以 SSE 方式逐帧转发 chat.completion.chunk，并以 [DONE] 结束
+func (h *OpenAIHandler) streamChatCompletion(w http.ResponseWriter, r *http.Request, chatCompletion translator.ChatCompleter, req openai.ChatCompletionRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.sendError(w, "Streaming not supported", "internal_error", http.StatusInternalServerError)
+		return
+	}
+
+	stream, err := chatCompletion.CreateChatCompletionStream(r.Context(), req)
+	if err != nil {
+		h.sendError(w, err.Error(), "internal_error", http.StatusInternalServerError)
+		return
+	}
+	defer stream.Close()
+
+	// 流式响应的时长不受服务器 WriteTimeout 限制
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to clear write deadline for stream: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		data, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// 响应头已发送，只能通过错误帧告知客户端
+			if r.Context().Err() == nil {
+				log.Printf("Stream error: %v", err)
+				errFrame, _ := json.Marshal(map[string]interface{}{
+					"error": map[string]string{
+						"message": err.Error(),
+						"type":    "upstream_error",
+					},
+				})
+				fmt.Fprintf(w, "data: %s\n\n", errFrame)
+				flusher.Flush()
+			}
+			return
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
 func (h *OpenAIHandler) HandleListModels(w http.ResponseWriter, r *http.Request) {
 	// 验证 API 密钥以保持与其它端点一致
 	authHeader := r.Header.Get("Authorization")
@@ -18,6 +18,11 @@ var (
 type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// Delete 删除指定的键，键不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// Scan 依次返回以 prefix 开头的未过期条目，fn 返回 false 时停止；fn 中可以调用 Delete
+	Scan(ctx context.Context, prefix string, fn func(key, value string) bool) error
+	// Clear 清空译文缓存，不影响共用同一存储的其它数据
 	Clear(ctx context.Context) error
 	Close(ctx context.Context) error
 }
@@ -28,3 +33,41 @@ type CacheEntry struct {
 	APIURL      string `json:"api_url"`
 	Model       string `json:"model"`
 }
+
+// Stats 缓存统计信息
+type Stats struct {
+	Type        string  `json:"type"`             // 缓存类型，如 memory
+	Policy      string  `json:"policy,omitempty"` // 淘汰策略
+	Entries     int     `json:"entries"`
+	Bytes       int64   `json:"bytes"` // 估算的占用字节数
+	MaxEntries  int     `json:"max_entries,omitempty"`
+	MaxBytes    int64   `json:"max_bytes,omitempty"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRate     float64 `json:"hit_rate"`
+	Evictions   uint64  `json:"evictions"`   // 因容量不足淘汰的条目数
+	Expirations uint64  `json:"expirations"` // 过期删除的条目数
+}
+
+// StatsReporter 可以提供统计信息的缓存
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// CollectStats 返回缓存各层的统计信息，不提供统计信息的缓存层跳过
+func CollectStats(c Cache) []Stats {
+	var layers []Cache
+	if multi, ok := c.(*MultiCache); ok {
+		layers = multi.caches
+	} else {
+		layers = []Cache{c}
+	}
+
+	stats := []Stats{}
+	for _, layer := range layers {
+		if reporter, ok := layer.(StatsReporter); ok {
+			stats = append(stats, reporter.Stats())
+		}
+	}
+	return stats
+}
@@ -0,0 +1,691 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// 数据文件中的 bucket：entries 存放 键 -> 过期时间(8 字节) + 值；expiry 为按过期时间排序的索引 过期时间(8 字节) + 键 -> 空
+var (
+	diskEntriesBucket = []byte("entries")
+	diskExpiryBucket  = []byte("expiry")
+)
+
+const (
+	diskScanBatch    = 1000       // Scan 每个读事务读取的条目数
+	diskSweepBatch   = 1000       // 清理过期条目时每个写事务删除的条目数
+	diskCopyBatch    = 1000       // 压缩时每个读事务复制的条目数
+	minCompactSize   = 16 << 20   // 数据文件小于该值时不压缩
+	compactFreeRatio = 0.5        // 空闲页占数据文件的比例达到该值时压缩
+	compactSuffix    = ".compact" // 压缩时写入的临时文件后缀
+)
+
+// DiskCache 基于 bbolt 的磁盘缓存，适用于没有 Redis 的单节点部署，重启后译文不丢失
+// 每次写入都在事务提交时落盘，进程崩溃或断电不会损坏数据文件；并发写入合并为一个事务提交。
+// 后台每分钟删除过期条目，并按 CompactInterval 检查空闲空间，空闲页过多时重写数据文件以回收磁盘空间
+type DiskCache struct {
+	mu         sync.RWMutex // 压缩时替换 db，其余操作持有读锁
+	db         *bolt.DB
+	journal    *compactJournal // 压缩期间记录写入的键，未在压缩时为 nil
+	path       string
+	defaultTTL time.Duration
+	permanent  bool
+	stop       chan struct{}
+	done       sync.WaitGroup
+	closeOnce  sync.Once
+
+	entries     atomic.Int64
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	expirations atomic.Uint64
+}
+
+// NewDiskCache 打开或创建磁盘缓存
+func NewDiskCache(opts DiskCacheOptions) (*DiskCache, error) {
+	if opts.Path == "" {
+		return nil, errors.New("disk cache path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create disk cache directory: %w", err)
+	}
+	// 上次压缩中途退出时留下的临时文件，原数据文件仍然完整
+	os.Remove(opts.Path + compactSuffix)
+
+	db, err := openDiskDB(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &DiskCache{
+		db:         db,
+		path:       opts.Path,
+		defaultTTL: opts.DefaultTTL,
+		permanent:  opts.Permanent,
+		stop:       make(chan struct{}),
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		cache.entries.Store(int64(tx.Bucket(diskEntriesBucket).Stats().KeyN))
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	cache.done.Add(1)
+	go cache.maintain(opts.CompactInterval)
+	return cache, nil
+}
+
+func openDiskDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disk cache %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{diskEntriesBucket, diskExpiryBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize disk cache %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// compactJournal 压缩期间提交的写入：复制数据时读取的是开始复制时的快照，替换数据文件前把这些键的最新值重放到新文件
+type compactJournal struct {
+	mu      sync.Mutex
+	keys    map[string]struct{}
+	cleared bool // 期间清空过缓存
+}
+
+// record 记录已提交的写入，j 为 nil（未在压缩）时不做处理
+func (j *compactJournal) record(keys ...string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, key := range keys {
+		j.keys[key] = struct{}{}
+	}
+}
+
+// clear 记录清空操作，之前记录的键不再需要重放
+func (j *compactJournal) clear() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = make(map[string]struct{})
+	j.cleared = true
+}
+
+// view、update 与 batch 在读锁下访问数据文件，压缩失败导致数据文件不可用时返回 ErrCacheNotAvailable
+// update 与 batch 在提交后、释放读锁前把写入的 keys 记入压缩日志，保证替换数据文件时不会丢失
+func (c *DiskCache) view(fn func(tx *bolt.Tx) error) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return ErrCacheNotAvailable
+	}
+	return c.db.View(fn)
+}
+
+// update 立即提交写事务，用于删除等通常由单个调用方连续发起的操作
+func (c *DiskCache) update(fn func(tx *bolt.Tx) error, keys ...string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return ErrCacheNotAvailable
+	}
+	if err := c.db.Update(fn); err != nil {
+		return err
+	}
+	c.journal.record(keys...)
+	return nil
+}
+
+// batch 将并发的写入合并为一个事务提交，用于翻译结果的写入；fn 可能被重新执行，不能在其中修改事务以外的状态
+func (c *DiskCache) batch(fn func(tx *bolt.Tx) error, keys ...string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return ErrCacheNotAvailable
+	}
+	if err := c.db.Batch(fn); err != nil {
+		return err
+	}
+	c.journal.record(keys...)
+	return nil
+}
+
+func (c *DiskCache) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	found := false
+	err := c.view(func(tx *bolt.Tx) error {
+		data := tx.Bucket(diskEntriesBucket).Get([]byte(key))
+		if len(data) < 8 {
+			return nil
+		}
+		if expireAt := int64(binary.BigEndian.Uint64(data)); expireAt != 0 && expireAt <= time.Now().UnixNano() {
+			return nil // 过期条目由后台清理
+		}
+		value = string(data[8:])
+		found = true
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		c.misses.Add(1)
+		return "", ErrCacheMiss
+	}
+	c.hits.Add(1)
+	return value, nil
+}
+
+func (c *DiskCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	var expireAt int64
+
+	// 处理过期时间，0 表示永不过期
+	if ttl < 0 || c.permanent {
+		// 永不过期
+		expireAt = 0
+	} else if ttl == 0 {
+		// 使用默认过期时间，默认过期时间为0也表示永久
+		if c.defaultTTL > 0 {
+			expireAt = time.Now().Add(c.defaultTTL).UnixNano()
+		}
+	} else {
+		// 使用指定的过期时间
+		expireAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expireAt))
+	copy(data[8:], value)
+
+	created := false
+	err := c.batch(func(tx *bolt.Tx) error {
+		created = false
+		entries := tx.Bucket(diskEntriesBucket)
+		expiry := tx.Bucket(diskExpiryBucket)
+
+		k := []byte(key)
+		if old := entries.Get(k); old != nil {
+			if err := deleteExpiryIndex(expiry, k, old); err != nil {
+				return err
+			}
+		} else {
+			created = true
+		}
+		if err := entries.Put(k, data); err != nil {
+			return err
+		}
+		if expireAt != 0 {
+			return expiry.Put(expiryKey(expireAt, k), []byte{})
+		}
+		return nil
+	}, key)
+	if err != nil {
+		return err
+	}
+	if created {
+		c.entries.Add(1)
+	}
+	return nil
+}
+
+// expiryKey 返回过期索引的键：过期时间在前，按时间顺序排列
+func expiryKey(expireAt int64, key []byte) []byte {
+	k := make([]byte, 8+len(key))
+	binary.BigEndian.PutUint64(k, uint64(expireAt))
+	copy(k[8:], key)
+	return k
+}
+
+// deleteExpiryIndex 删除条目 data 对应的过期索引
+func deleteExpiryIndex(expiry *bolt.Bucket, key, data []byte) error {
+	if len(data) < 8 {
+		return nil
+	}
+	if expireAt := int64(binary.BigEndian.Uint64(data)); expireAt != 0 {
+		return expiry.Delete(expiryKey(expireAt, key))
+	}
+	return nil
+}
+
+// Delete 删除指定的键
+func (c *DiskCache) Delete(ctx context.Context, key string) error {
+	deleted := false
+	err := c.update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(diskEntriesBucket)
+		k := []byte(key)
+		old := entries.Get(k)
+		if old == nil {
+			return nil
+		}
+		if err := deleteExpiryIndex(tx.Bucket(diskExpiryBucket), k, old); err != nil {
+			return err
+		}
+		deleted = true
+		return entries.Delete(k)
+	}, key)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		c.entries.Add(-1)
+	}
+	return nil
+}
+
+// Scan 依次返回以 prefix 开头的未过期条目，按键的字节顺序排列
+// 每个读事务最多读取 diskScanBatch 条，回调期间不持有事务
+func (c *DiskCache) Scan(ctx context.Context, prefix string, fn func(key, value string) bool) error {
+	type kv struct{ key, value string }
+
+	p := []byte(prefix)
+	start := p
+	skip := false // 从上一批的最后一个键继续，跳过该键
+	for {
+		var batch []kv
+		var last []byte
+		more := false
+		err := c.view(func(tx *bolt.Tx) error {
+			now := time.Now().UnixNano()
+			cursor := tx.Bucket(diskEntriesBucket).Cursor()
+			k, v := cursor.Seek(start)
+			if skip && bytes.Equal(k, start) {
+				k, v = cursor.Next()
+			}
+			for ; k != nil && bytes.HasPrefix(k, p); k, v = cursor.Next() {
+				if len(batch) == diskScanBatch {
+					more = true
+					break
+				}
+				last = append(last[:0], k...)
+				if len(v) < 8 {
+					continue
+				}
+				if expireAt := int64(binary.BigEndian.Uint64(v)); expireAt != 0 && expireAt <= now {
+					continue
+				}
+				batch = append(batch, kv{string(k), string(v[8:])})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, item := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !fn(item.key, item.value) {
+				return nil
+			}
+		}
+		if !more {
+			return nil
+		}
+		start, skip = last, true
+	}
+}
+
+// Clear 删除所有条目；数据文件只存放译文缓存
+func (c *DiskCache) Clear(ctx context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return ErrCacheNotAvailable
+	}
+	if err := c.db.Update(resetBuckets); err != nil {
+		return err
+	}
+	c.journal.clear()
+	c.entries.Store(0)
+	return nil
+}
+
+// resetBuckets 删除并重新创建所有 bucket
+func resetBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{diskEntriesBucket, diskExpiryBucket} {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maintain 每分钟删除过期条目，按 compactInterval 检查是否需要压缩，compactInterval 不大于 0 时不压缩
+func (c *DiskCache) maintain(compactInterval time.Duration) {
+	defer c.done.Done()
+
+	sweep := time.NewTicker(time.Minute)
+	defer sweep.Stop()
+
+	var compact <-chan time.Time
+	if compactInterval > 0 {
+		ticker := time.NewTicker(compactInterval)
+		defer ticker.Stop()
+		compact = ticker.C
+	}
+
+	for {
+		select {
+		case <-sweep.C:
+			if err := c.removeExpired(); err != nil {
+				log.Printf("Failed to remove expired disk cache entries: %v", err)
+			}
+		case <-compact:
+			if err := c.compactIfNeeded(); err != nil {
+				log.Printf("Failed to compact disk cache: %v", err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// removeExpired 按过期索引删除已过期的条目，每个写事务最多处理 diskSweepBatch 条索引
+// 与压缩在同一个后台协程中执行，不会与压缩同时进行，删除的键无需记入压缩日志
+func (c *DiskCache) removeExpired() error {
+	for {
+		var scanned, removed int
+		err := c.update(func(tx *bolt.Tx) error {
+			now := uint64(time.Now().UnixNano())
+			entries := tx.Bucket(diskEntriesBucket)
+			expiry := tx.Bucket(diskExpiryBucket)
+
+			var expired [][]byte
+			cursor := expiry.Cursor()
+			for k, _ := cursor.First(); k != nil && len(expired) < diskSweepBatch; k, _ = cursor.Next() {
+				if len(k) < 8 || binary.BigEndian.Uint64(k) > now {
+					break
+				}
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			scanned = len(expired)
+
+			for _, k := range expired {
+				if err := expiry.Delete(k); err != nil {
+					return err
+				}
+				// 只删除过期时间与索引一致的条目，条目可能已被重新写入
+				key := k[8:]
+				if data := entries.Get(key); len(data) >= 8 && bytes.Equal(data[:8], k[:8]) {
+					if err := entries.Delete(key); err != nil {
+						return err
+					}
+					removed++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		c.entries.Add(int64(-removed))
+		c.expirations.Add(uint64(removed))
+		if scanned < diskSweepBatch {
+			return nil
+		}
+	}
+}
+
+// compactIfNeeded 数据文件足够大且空闲页占比达到 compactFreeRatio 时压缩
+func (c *DiskCache) compactIfNeeded() error {
+	var size, free int64
+	err := c.view(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		stats := tx.DB().Stats()
+		free = int64(stats.FreePageN+stats.PendingPageN) * int64(tx.DB().Info().PageSize)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if size < minCompactSize || float64(free) < float64(size)*compactFreeRatio {
+		return nil
+	}
+	return c.compact()
+}
+
+// compact 将数据复制到临时文件后原子地替换原数据文件
+// 复制分批进行，期间读写照常；复制期间提交的写入记在压缩日志中，只在替换数据文件时持有写锁，把这些写入重放到新文件后关闭、替换并重新打开。
+// 复制失败时保留原文件；替换后重新打开失败时缓存不可用，操作返回 ErrCacheNotAvailable
+func (c *DiskCache) compact() error {
+	c.mu.Lock()
+	if c.db == nil {
+		c.mu.Unlock()
+		return ErrCacheNotAvailable
+	}
+	journal := &compactJournal{keys: make(map[string]struct{})}
+	c.journal = journal
+	c.mu.Unlock()
+
+	tmp := c.path + compactSuffix
+	dst, err := c.copyTo(tmp)
+	if err != nil {
+		c.mu.Lock()
+		c.journal = nil
+		c.mu.Unlock()
+		os.Remove(tmp)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.journal = nil
+
+	if err := replayJournal(dst, c.db, journal); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := c.db.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	c.db = nil
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		c.db, _ = openDiskDB(c.path)
+		return err
+	}
+	syncDir(filepath.Dir(c.path))
+
+	db, err := openDiskDB(c.path)
+	if err != nil {
+		return err
+	}
+	c.db = db
+	return nil
+}
+
+// copyTo 把条目分批复制到 path 并重建过期索引，每批在一个读事务中最多读取 diskCopyBatch 条
+// bbolt 的读事务在结束前占用旧页面并阻止数据文件扩容，分批复制使需要扩容的写入最多等待一批；各批读取的不是同一快照，复制期间的写入由压缩日志补齐
+func (c *DiskCache) copyTo(path string) (*bolt.DB, error) {
+	type kv struct{ key, value []byte }
+
+	os.Remove(path)
+	dst, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = dst.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{diskEntriesBucket, diskExpiryBucket} {
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		dst.Close()
+		return nil, err
+	}
+
+	var last []byte // 上一批的最后一个键，下一批从其后继续
+	for {
+		var batch []kv
+		err := c.view(func(tx *bolt.Tx) error {
+			cursor := tx.Bucket(diskEntriesBucket).Cursor()
+			k, v := cursor.First()
+			if last != nil {
+				if k, v = cursor.Seek(last); bytes.Equal(k, last) {
+					k, v = cursor.Next()
+				}
+			}
+			for ; k != nil && len(batch) < diskCopyBatch; k, v = cursor.Next() {
+				batch = append(batch, kv{append([]byte(nil), k...), append([]byte(nil), v...)})
+			}
+			return nil
+		})
+		if err == nil && len(batch) > 0 {
+			err = dst.Update(func(tx *bolt.Tx) error {
+				entries := tx.Bucket(diskEntriesBucket)
+				entries.FillPercent = 1 // 按键的顺序追加，页面填满
+				expiry := tx.Bucket(diskExpiryBucket)
+				for _, item := range batch {
+					if err := putEntry(entries, expiry, item.key, item.value); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+		if err != nil {
+			dst.Close()
+			return nil, err
+		}
+		if len(batch) < diskCopyBatch {
+			return dst, nil
+		}
+		last = batch[len(batch)-1].key
+	}
+}
+
+// putEntry 写入条目及其过期索引
+func putEntry(entries, expiry *bolt.Bucket, key, data []byte) error {
+	if err := entries.Put(key, data); err != nil {
+		return err
+	}
+	if len(data) >= 8 {
+		if expireAt := int64(binary.BigEndian.Uint64(data)); expireAt != 0 {
+			return expiry.Put(expiryKey(expireAt, key), []byte{})
+		}
+	}
+	return nil
+}
+
+// replayJournal 把复制期间写入的键的最新值（含过期索引）从 src 写入 dst，已删除的键在 dst 中同样删除
+func replayJournal(dst, src *bolt.DB, journal *compactJournal) error {
+	if !journal.cleared && len(journal.keys) == 0 {
+		return nil
+	}
+	return src.View(func(srcTx *bolt.Tx) error {
+		srcEntries := srcTx.Bucket(diskEntriesBucket)
+		return dst.Update(func(tx *bolt.Tx) error {
+			if journal.cleared {
+				if err := resetBuckets(tx); err != nil {
+					return err
+				}
+			}
+			entries := tx.Bucket(diskEntriesBucket)
+			expiry := tx.Bucket(diskExpiryBucket)
+
+			for key := range journal.keys {
+				k := []byte(key)
+				if old := entries.Get(k); old != nil {
+					if err := deleteExpiryIndex(expiry, k, old); err != nil {
+						return err
+					}
+					if err := entries.Delete(k); err != nil {
+						return err
+					}
+				}
+
+				if data := srcEntries.Get(k); data != nil {
+					if err := putEntry(entries, expiry, k, append([]byte(nil), data...)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// syncDir 将目录项的修改（文件替换）落盘
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// Stats 返回磁盘缓存的统计信息，Bytes 为数据文件大小
+func (c *DiskCache) Stats() Stats {
+	stats := Stats{
+		Type:        "disk",
+		Entries:     int(c.entries.Load()),
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Expirations: c.expirations.Load(),
+	}
+	c.view(func(tx *bolt.Tx) error {
+		stats.Bytes = tx.Size()
+		return nil
+	})
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// Close 停止后台清理并关闭数据文件
+func (c *DiskCache) Close(ctx context.Context) error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		c.done.Wait()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.db != nil {
+			err = c.db.Close()
+			c.db = nil
+		}
+	})
+	return err
+}
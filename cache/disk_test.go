@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// fillDiskCache 并发写入 n 条 key-<i>，使写入合并为少量事务
+func fillDiskCache(t *testing.T, c *DiskCache, n int, value string, ttl time.Duration) {
+	t.Helper()
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for w := 0; w < 50; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += 50 {
+				if err := c.Set(ctx, fmt.Sprint("key-", i), value, ttl); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestDiskCacheCompact(t *testing.T) {
+	const n = 3 * diskCopyBatch
+	value := strings.Repeat("v", 512)
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{name: "permanent entries", ttl: -1},
+		{name: "expiring entries keep their expiry index", ttl: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, err := NewDiskCache(DiskCacheOptions{Path: filepath.Join(t.TempDir(), "cache.db")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close(ctx)
+			fillDiskCache(t, c, n, value, tt.ttl)
+
+			if err := c.compact(); err != nil {
+				t.Fatalf("compact() error = %v", err)
+			}
+
+			count := 0
+			c.Scan(ctx, "key-", func(key, v string) bool {
+				if v != value {
+					t.Errorf("%s = %q after compaction", key, v)
+				}
+				count++
+				return true
+			})
+			if count != n {
+				t.Errorf("entries after compaction = %d, want %d", count, n)
+			}
+
+			indexed := 0
+			c.view(func(tx *bolt.Tx) error {
+				indexed = tx.Bucket(diskExpiryBucket).Stats().KeyN
+				return nil
+			})
+			wantIndexed := 0
+			if tt.ttl > 0 {
+				wantIndexed = n
+			}
+			if indexed != wantIndexed {
+				t.Errorf("expiry index entries = %d, want %d", indexed, wantIndexed)
+			}
+		})
+	}
+}
+
+func TestDiskCacheCompactConcurrentWrites(t *testing.T) {
+	const n = 3 * diskCopyBatch
+	ctx := context.Background()
+	c, err := NewDiskCache(DiskCacheOptions{Path: filepath.Join(t.TempDir(), "cache.db"), Permanent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close(ctx)
+	fillDiskCache(t, c, n, strings.Repeat("v", 512), 0)
+
+	// 压缩期间写入新条目、覆盖和删除已有条目
+	stop := make(chan struct{})
+	written := make(chan int)
+	go func() {
+		i := 0
+		defer func() { written <- i }()
+		for ; i < n/2; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.Set(ctx, fmt.Sprint("new-", i), "new", time.Hour)
+			c.Set(ctx, fmt.Sprint("key-", 2*i), "updated", 0)
+			c.Delete(ctx, fmt.Sprint("key-", 2*i+1))
+		}
+	}()
+
+	for round := 0; round < 3; round++ {
+		if err := c.compact(); err != nil {
+			t.Fatalf("compact() error = %v", err)
+		}
+	}
+	close(stop)
+	w := <-written
+
+	for i := 0; i < w; i++ {
+		if v, err := c.Get(ctx, fmt.Sprint("new-", i)); err != nil || v != "new" {
+			t.Fatalf("new-%d = %q, %v after compaction", i, v, err)
+		}
+		if v, _ := c.Get(ctx, fmt.Sprint("key-", 2*i)); v != "updated" {
+			t.Fatalf("key-%d = %q, want updated", 2*i, v)
+		}
+		if _, err := c.Get(ctx, fmt.Sprint("key-", 2*i+1)); err != ErrCacheMiss {
+			t.Fatalf("deleted key-%d is back after compaction", 2*i+1)
+		}
+	}
+	for i := 2 * w; i < n; i++ {
+		if _, err := c.Get(ctx, fmt.Sprint("key-", i)); err != nil {
+			t.Fatalf("untouched key-%d lost after compaction: %v", i, err)
+		}
+	}
+}
+
+func TestDiskCacheCompactAfterClear(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewDiskCache(DiskCacheOptions{Path: filepath.Join(t.TempDir(), "cache.db"), Permanent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close(ctx)
+	fillDiskCache(t, c, diskCopyBatch, "v", 0)
+
+	// 模拟复制完成后、替换数据文件前提交的清空与写入
+	journal := &compactJournal{keys: make(map[string]struct{})}
+	c.journal = journal
+	dst, err := c.copyTo(c.path + compactSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	c.Clear(ctx)
+	c.Set(ctx, "after-clear", "v", 0)
+	c.journal = nil
+
+	if err := replayJournal(dst, c.db, journal); err != nil {
+		t.Fatal(err)
+	}
+	keys := 0
+	dst.View(func(tx *bolt.Tx) error {
+		keys = tx.Bucket(diskEntriesBucket).Stats().KeyN
+		if tx.Bucket(diskEntriesBucket).Get([]byte("after-clear")) == nil {
+			t.Error("write after Clear missing from the compacted file")
+		}
+		return nil
+	})
+	if keys != 1 {
+		t.Errorf("entries in compacted file = %d, want 1", keys)
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// lockPrefix 分布式锁的键前缀，锁键为 transbridge:lock:<缓存键去掉 transbridge: 前缀的部分>
+const lockPrefix = "transbridge:lock:"
+
+// releaseScript 只删除自己持有的锁，避免锁过期后误删其它实例重新获取的锁
+var releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// renewScript 只延长自己持有的锁
+var renewScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// RedisLock 基于 Redis SET NX 的分布式锁，多实例部署时保证同一缓存键同时只有一个实例调用模型
+type RedisLock struct {
+	client *redis.Client
+}
+
+// NewRedisLock 创建 Redis 分布式锁
+func NewRedisLock(client *redis.Client) *RedisLock {
+	return &RedisLock{client: client}
+}
+
+// Acquire 尝试获取 key 对应的锁，锁在 ttl 后自动过期；获取成功时返回释放函数
+// 释放之前每隔三分之一 ttl 续期，故障切换或分块翻译耗时超过 ttl 时锁仍由本实例持有；进程异常退出后锁在 ttl 后过期
+func (l *RedisLock) Acquire(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	token := make([]byte, 16)
+	rand.Read(token)
+	value := hex.EncodeToString(token)
+
+	lockKey := lockPrefix + strings.TrimPrefix(key, "transbridge:")
+	ok, err := l.client.SetNX(ctx, lockKey, value, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	done := make(chan struct{})
+	go l.renew(lockKey, value, ttl, done)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(done)
+			// 请求已取消时仍需释放锁，使用独立的上下文
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			releaseScript.Run(releaseCtx, l.client, []string{lockKey}, value)
+		})
+	}
+	return release, true, nil
+}
+
+// renew 每隔三分之一 ttl 延长锁的过期时间，直到 done 关闭；锁已过期或被其它实例获取时停止
+func (l *RedisLock) renew(lockKey, value string, ttl time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+			n, err := renewScript.Run(ctx, l.client, []string{lockKey}, value, ttl.Milliseconds()).Int()
+			cancel()
+			if err != nil {
+				log.Printf("Failed to renew translation lock %s: %v", lockKey, err)
+			} else if n == 0 {
+				log.Printf("Translation lock %s expired before renewal", lockKey)
+				return
+			}
+		}
+	}
+}
+
+// Close 关闭 Redis 连接
+func (l *RedisLock) Close() error {
+	return l.client.Close()
+}
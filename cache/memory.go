@@ -2,24 +2,49 @@ package cache
 
 import (
 	"context"
+	"hash/fnv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// memoryItem 内部缓存项结构
-type memoryItem struct {
-	data       string     // 存储的数据
-	expireTime *time.Time // 过期时间，nil表示永不过期
+// 分片数为 2 的幂，最多 maxShards 个；条目上限较小时减少分片，保证每个分片至少 minShardEntries 条
+const (
+	maxShards       = 32
+	minShardEntries = 256
+)
+
+// entryOverhead 估算单个条目除键和值以外的内存占用（条目结构、链表指针与 map 开销）
+const entryOverhead = 128
+
+// memoryShard 内存缓存的一个分片，拥有独立的锁与淘汰策略
+type memoryShard struct {
+	mu         sync.Mutex
+	entries    map[string]*memoryEntry
+	policy     evictionPolicy
+	bytes      int64
+	maxEntries int
+	maxBytes   int64 // 0 表示不限制
 }
 
 // MemoryCache 实现了内存缓存
+// 键按哈希分布到多个分片以减少锁竞争，容量按条目数与估算的字节数限制，超出时按配置的淘汰策略淘汰
 type MemoryCache struct {
-	sync.RWMutex
-	data       map[string]memoryItem
+	shards     []*memoryShard
+	mask       uint64
+	policy     string
 	maxSize    int
+	maxBytes   int64
 	defaultTTL time.Duration
 	permanent  bool
 	stop       chan struct{} // 用于停止清理 goroutine
+	closeOnce  sync.Once
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
 }
 
 // NewMemoryCache 创建新的内存缓存
@@ -27,18 +52,41 @@ func NewMemoryCache(opts MemoryCacheOptions) *MemoryCache {
 	if opts.MaxSize <= 0 {
 		opts.MaxSize = 10000000
 	}
+	if opts.MaxBytes < 0 {
+		opts.MaxBytes = 0
+	}
+	if opts.Policy != PolicyTinyLFU {
+		opts.Policy = PolicyLRU
+	}
 
 	if opts.DefaultTTL <= 0 && !opts.Permanent {
 		opts.DefaultTTL = time.Hour
 	}
 
+	n := maxShards
+	for n > 1 && opts.MaxSize/n < minShardEntries {
+		n /= 2
+	}
+
 	cache := &MemoryCache{
-		data:       make(map[string]memoryItem),
+		shards:     make([]*memoryShard, n),
+		mask:       uint64(n - 1),
+		policy:     opts.Policy,
 		maxSize:    opts.MaxSize,
+		maxBytes:   opts.MaxBytes,
 		defaultTTL: opts.DefaultTTL,
 		permanent:  opts.Permanent,
 		stop:       make(chan struct{}),
 	}
+	for i := range cache.shards {
+		maxEntries := (opts.MaxSize + n - 1) / n
+		cache.shards[i] = &memoryShard{
+			entries:    make(map[string]*memoryEntry),
+			policy:     newEvictionPolicy(opts.Policy, maxEntries),
+			maxEntries: maxEntries,
+			maxBytes:   (opts.MaxBytes + int64(n) - 1) / int64(n),
+		}
+	}
 
 	// 如果不是永久存储，启动清理过期数据的 goroutine
 	if !opts.Permanent {
@@ -48,6 +96,7 @@ func NewMemoryCache(opts MemoryCacheOptions) *MemoryCache {
 	return cache
 }
 
+// cleanExpired 定期删除过期条目，每次只锁定一个分片
 func (c *MemoryCache) cleanExpired() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
@@ -55,89 +104,209 @@ func (c *MemoryCache) cleanExpired() {
 	for {
 		select {
 		case <-ticker.C:
-			c.Lock()
-			now := time.Now()
-			for key, item := range c.data {
-				if item.expireTime != nil && item.expireTime.Before(now) {
-					delete(c.data, key)
+			for _, shard := range c.shards {
+				shard.mu.Lock()
+				now := time.Now().UnixNano()
+				for _, e := range shard.entries {
+					if e.expired(now) {
+						shard.remove(e)
+						c.expirations.Add(1)
+					}
 				}
+				shard.mu.Unlock()
 			}
-			c.Unlock()
 		case <-c.stop:
 			return
 		}
 	}
 }
 
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+func (c *MemoryCache) shard(hash uint64) *memoryShard {
+	return c.shards[hash&c.mask]
+}
+
 func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
-	c.RLock()
-	defer c.RUnlock()
+	hash := hashKey(key)
+	shard := c.shard(hash)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
-	item, ok := c.data[key]
+	shard.policy.record(hash)
+	e, ok := shard.entries[key]
 	if !ok {
+		c.misses.Add(1)
 		return "", ErrCacheMiss
 	}
 
 	// 检查是否过期
-	if item.expireTime != nil && item.expireTime.Before(time.Now()) {
-		delete(c.data, key)
+	if e.expired(time.Now().UnixNano()) {
+		shard.remove(e)
+		c.expirations.Add(1)
+		c.misses.Add(1)
 		return "", ErrCacheMiss
 	}
 
-	return item.data, nil
+	shard.policy.access(e)
+	c.hits.Add(1)
+	return e.value, nil
 }
 
 func (c *MemoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
-	c.Lock()
-	defer c.Unlock()
+	var expireAt int64
 
-	// 如果达到最大容量，删除一个随机条目
-	if len(c.data) >= c.maxSize {
-		for k := range c.data {
-			delete(c.data, k)
-			break
-		}
-	}
-
-	var expireTime *time.Time
-
-	// 处理过期时间
+	// 处理过期时间，0 表示永不过期
 	if ttl < 0 || c.permanent {
 		// 永不过期
-		expireTime = nil
+		expireAt = 0
 	} else if ttl == 0 {
 		// 使用默认过期时间
 		if c.defaultTTL > 0 {
-			t := time.Now().Add(c.defaultTTL)
-			expireTime = &t
-		} else {
-			expireTime = nil // 默认过期时间为0也表示永久
+			expireAt = time.Now().Add(c.defaultTTL).UnixNano()
 		}
 	} else {
 		// 使用指定的过期时间
-		t := time.Now().Add(ttl)
-		expireTime = &t
+		expireAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	hash := hashKey(key)
+	size := int64(len(key)+len(value)) + entryOverhead
+	shard := c.shard(hash)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.policy.record(hash)
+	e, ok := shard.entries[key]
+
+	// 单个条目超出分片的字节上限时不缓存，同时删除旧值
+	if shard.maxBytes > 0 && size > shard.maxBytes {
+		if ok {
+			shard.remove(e)
+		}
+		return nil
+	}
+
+	if ok {
+		shard.bytes += size - e.size
+		e.value = value
+		e.size = size
+		e.expireAt = expireAt
+		shard.policy.access(e)
+	} else {
+		e = &memoryEntry{key: key, value: value, hash: hash, expireAt: expireAt, size: size}
+		shard.entries[key] = e
+		shard.bytes += size
+		shard.policy.add(e)
 	}
 
-	c.data[key] = memoryItem{
-		data:       value,
-		expireTime: expireTime,
+	// 超出容量时按淘汰策略淘汰，已过期的条目记为过期
+	now := time.Now().UnixNano()
+	for len(shard.entries) > shard.maxEntries || (shard.maxBytes > 0 && shard.bytes > shard.maxBytes) {
+		victim := shard.policy.victim()
+		if victim == nil {
+			break
+		}
+		shard.remove(victim)
+		if victim.expired(now) {
+			c.expirations.Add(1)
+		} else {
+			c.evictions.Add(1)
+		}
+	}
+	return nil
+}
+
+// remove 删除条目，调用方需持有分片的锁
+func (s *memoryShard) remove(e *memoryEntry) {
+	s.policy.remove(e)
+	delete(s.entries, e.key)
+	s.bytes -= e.size
+}
+
+// Delete 删除指定的键
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	shard := c.shard(hashKey(key))
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if e, ok := shard.entries[key]; ok {
+		shard.remove(e)
+	}
+	return nil
+}
+
+// Scan 依次返回以 prefix 开头的未过期条目；逐个分片复制匹配的条目再回调，回调期间不持有锁
+func (c *MemoryCache) Scan(ctx context.Context, prefix string, fn func(key, value string) bool) error {
+	type kv struct{ key, value string }
+
+	for _, shard := range c.shards {
+		var matched []kv
+		shard.mu.Lock()
+		now := time.Now().UnixNano()
+		for key, e := range shard.entries {
+			if strings.HasPrefix(key, prefix) && !e.expired(now) {
+				matched = append(matched, kv{key, e.value})
+			}
+		}
+		shard.mu.Unlock()
+
+		for _, item := range matched {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !fn(item.key, item.value) {
+				return nil
+			}
+		}
 	}
 	return nil
 }
 
 func (c *MemoryCache) Clear(ctx context.Context) error {
-	c.Lock()
-	defer c.Unlock()
-	c.data = make(map[string]memoryItem)
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		shard.entries = make(map[string]*memoryEntry)
+		shard.policy.reset()
+		shard.bytes = 0
+		shard.mu.Unlock()
+	}
 	return nil
 }
 
+// Stats 返回内存缓存的统计信息
+func (c *MemoryCache) Stats() Stats {
+	stats := Stats{
+		Type:        "memory",
+		Policy:      c.policy,
+		MaxEntries:  c.maxSize,
+		MaxBytes:    c.maxBytes,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+	}
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		stats.Entries += len(shard.entries)
+		stats.Bytes += shard.bytes
+		shard.mu.Unlock()
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
 // Close 实现 Cache 接口
 func (c *MemoryCache) Close(ctx context.Context) error {
-	close(c.stop) // 停止清理 goroutine
-	c.Lock()
-	c.data = nil // 清空数据
-	c.Unlock()
-	return nil
+	c.closeOnce.Do(func() {
+		close(c.stop) // 停止清理 goroutine
+	})
+	return c.Clear(ctx) // 清空数据
 }
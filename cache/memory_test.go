@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheScanResistance(t *testing.T) {
+	const capacity = 100
+
+	tests := []struct {
+		policy      string
+		wantSurvive bool
+	}{
+		{policy: PolicyTinyLFU, wantSurvive: true},
+		{policy: PolicyLRU, wantSurvive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ctx := context.Background()
+			c := NewMemoryCache(MemoryCacheOptions{MaxSize: capacity, Policy: tt.policy, Permanent: true})
+			defer c.Close(ctx)
+
+			c.Set(ctx, "hot", "v", 0)
+			for i := 0; i < capacity-1; i++ {
+				c.Set(ctx, fmt.Sprint("warm-", i), "v", 0)
+			}
+			for i := 0; i < 20; i++ {
+				if _, err := c.Get(ctx, "hot"); err != nil {
+					t.Fatalf("hot key missing before the scan: %v", err)
+				}
+			}
+
+			// 大量只写入一次的键
+			for i := 0; i < 10*capacity; i++ {
+				c.Set(ctx, fmt.Sprint("scan-", i), "v", 0)
+			}
+
+			_, err := c.Get(ctx, "hot")
+			if survived := err == nil; survived != tt.wantSurvive {
+				t.Errorf("hot key survived the scan = %v, want %v", survived, tt.wantSurvive)
+			}
+			if stats := c.Stats(); stats.Entries > capacity {
+				t.Errorf("entries = %d, want at most %d", stats.Entries, capacity)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(MemoryCacheOptions{MaxSize: 3, Policy: PolicyLRU, Permanent: true})
+	defer c.Close(ctx)
+
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(ctx, key, key, 0)
+	}
+	// 读取 a 后 b 成为最久未读取的键
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	c.Set(ctx, "d", "d", 0)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, err := c.Get(ctx, key); (err == nil) != want {
+			t.Errorf("%s cached = %v, want %v", key, err == nil, want)
+		}
+	}
+}
+
+func TestMemoryCacheMaxBytes(t *testing.T) {
+	value := strings.Repeat("v", 100)
+	entrySize := int64(len("key-00")+len(value)) + entryOverhead
+
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{name: "lru", policy: PolicyLRU},
+		{name: "tinylfu", policy: PolicyTinyLFU},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			maxBytes := 10 * entrySize
+			c := NewMemoryCache(MemoryCacheOptions{MaxSize: 1000, MaxBytes: maxBytes, Policy: tt.policy, Permanent: true})
+			defer c.Close(ctx)
+
+			for i := 0; i < 50; i++ {
+				c.Set(ctx, fmt.Sprintf("key-%02d", i), value, 0)
+				if stats := c.Stats(); stats.Bytes > maxBytes {
+					t.Fatalf("after %d writes bytes = %d, want at most %d", i+1, stats.Bytes, maxBytes)
+				}
+			}
+			stats := c.Stats()
+			if stats.Entries != 10 {
+				t.Errorf("entries = %d, want 10", stats.Entries)
+			}
+			if stats.Bytes != int64(stats.Entries)*entrySize {
+				t.Errorf("bytes = %d, want %d for %d entries", stats.Bytes, int64(stats.Entries)*entrySize, stats.Entries)
+			}
+
+			// 单个条目超出上限时不缓存
+			c.Set(ctx, "huge", strings.Repeat("v", int(maxBytes)), 0)
+			if _, err := c.Get(ctx, "huge"); err != ErrCacheMiss {
+				t.Errorf("entry larger than MaxBytes was cached")
+			}
+		})
+	}
+}
+
+func TestMemoryCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(MemoryCacheOptions{MaxSize: 5, Policy: PolicyLRU})
+	defer c.Close(ctx)
+
+	for i := 0; i < 8; i++ {
+		c.Set(ctx, fmt.Sprint("key-", i), "v", 0)
+	}
+	c.Set(ctx, "short", "v", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	var hits, misses uint64
+	for _, key := range []string{"key-0", "key-1", "key-2", "key-3", "key-4", "key-5", "key-6", "key-7", "short", "missing"} {
+		if _, err := c.Get(ctx, key); err == nil {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	stats := c.Stats()
+	if stats.Hits != hits || stats.Misses != misses {
+		t.Errorf("hits/misses = %d/%d, want %d/%d", stats.Hits, stats.Misses, hits, misses)
+	}
+	if want := float64(hits) / float64(hits+misses); stats.HitRate != want {
+		t.Errorf("hit rate = %v, want %v", stats.HitRate, want)
+	}
+	// 9 次写入中 4 条被淘汰、1 条过期，其余仍在缓存中
+	if stats.Evictions != 4 || stats.Expirations != 1 {
+		t.Errorf("evictions/expirations = %d/%d, want 4/1", stats.Evictions, stats.Expirations)
+	}
+	if got := uint64(stats.Entries) + stats.Evictions + stats.Expirations; got != 9 {
+		t.Errorf("entries + evictions + expirations = %d, want 9 writes", got)
+	}
+	if stats.Entries != int(hits) {
+		t.Errorf("entries = %d, want %d", stats.Entries, hits)
+	}
+}
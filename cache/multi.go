@@ -55,6 +55,43 @@ func (m *MultiCache) Set(ctx context.Context, key string, value string, ttl time
 	return lastErr
 }
 
+// Delete 从每一层缓存中删除指定的键
+func (m *MultiCache) Delete(ctx context.Context, key string) error {
+	var lastErr error
+	for _, cache := range m.caches {
+		if err := cache.Delete(ctx, key); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Scan 依次扫描每一层缓存，同一个键只返回一次，取最上层的值
+func (m *MultiCache) Scan(ctx context.Context, prefix string, fn func(key, value string) bool) error {
+	seen := make(map[string]bool)
+	stopped := false
+	for _, cache := range m.caches {
+		err := cache.Scan(ctx, prefix, func(key, value string) bool {
+			if seen[key] {
+				return true
+			}
+			seen[key] = true
+			if !fn(key, value) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
 // Clear 清除所有缓存
 func (m *MultiCache) Clear(ctx context.Context) error {
 	var lastErr error
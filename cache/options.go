@@ -5,6 +5,8 @@ import "time"
 // MemoryCacheOptions 内存缓存选项
 type MemoryCacheOptions struct {
 	MaxSize    int           // 最大缓存条目数
+	MaxBytes   int64         // 最大占用字节数（按键与值的长度估算），0 表示不限制
+	Policy     string        // 淘汰策略：lru（默认）或 tinylfu
 	DefaultTTL time.Duration // 默认过期时间
 	Permanent  bool          // 是否永久存储
 }
@@ -18,3 +20,11 @@ type RedisCacheOptions struct {
 	DefaultTTL time.Duration // 默认过期时间
 	Permanent  bool          // 是否永久存储
 }
+
+// DiskCacheOptions 磁盘缓存选项
+type DiskCacheOptions struct {
+	Path            string        // 数据文件路径
+	DefaultTTL      time.Duration // 默认过期时间，0 表示永不过期
+	Permanent       bool          // 是否永久存储
+	CompactInterval time.Duration // 检查是否需要压缩数据文件的间隔，0 表示不压缩
+}
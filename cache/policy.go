@@ -0,0 +1,210 @@
+package cache
+
+// 内存缓存的淘汰策略
+const (
+	PolicyLRU     = "lru"     // 淘汰最久未访问的条目
+	PolicyTinyLFU = "tinylfu" // W-TinyLFU：新条目先进入小窗口，离开窗口时与主区域的淘汰候选比较访问频率，频率更高者留下
+)
+
+// 链表段，标记条目当前所在的链表
+const (
+	segmentLRU uint8 = iota
+	segmentWindow
+	segmentProbation
+	segmentProtected
+)
+
+// memoryEntry 内存缓存中的条目，同时是淘汰策略链表中的节点
+type memoryEntry struct {
+	key       string
+	value     string
+	hash      uint64
+	expireAt  int64 // 过期时间（UnixNano），0 表示永不过期
+	size      int64 // 估算的占用字节数
+	segment   uint8
+	candidate bool // 刚从窗口移入试用区、尚未与淘汰候选比较过
+	prev      *memoryEntry
+	next      *memoryEntry
+}
+
+func (e *memoryEntry) expired(now int64) bool {
+	return e.expireAt != 0 && e.expireAt <= now
+}
+
+// entryList 侵入式双向链表，front 为最近访问的条目
+type entryList struct {
+	root memoryEntry
+	len  int
+}
+
+func (l *entryList) init() {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+}
+
+func (l *entryList) pushFront(e *memoryEntry) {
+	e.prev = &l.root
+	e.next = l.root.next
+	l.root.next.prev = e
+	l.root.next = e
+	l.len++
+}
+
+func (l *entryList) remove(e *memoryEntry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+	e.next = nil
+	l.len--
+}
+
+func (l *entryList) moveToFront(e *memoryEntry) {
+	if l.root.next == e {
+		return
+	}
+	l.remove(e)
+	l.pushFront(e)
+}
+
+func (l *entryList) front() *memoryEntry {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+func (l *entryList) back() *memoryEntry {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// evictionPolicy 单个分片的淘汰策略，调用方需持有分片的锁
+type evictionPolicy interface {
+	record(hash uint64)    // 记录一次访问（包括未命中），用于估算访问频率
+	add(e *memoryEntry)    // 写入新条目
+	access(e *memoryEntry) // 条目被读取或覆盖
+	remove(e *memoryEntry) // 条目被删除、淘汰或过期
+	victim() *memoryEntry  // 需要腾出空间时下一个淘汰的条目
+	reset()                // 清空所有条目与频率
+}
+
+// newEvictionPolicy 创建淘汰策略，capacity 为分片的条目上限，用于确定频率统计的规模
+func newEvictionPolicy(name string, capacity int) evictionPolicy {
+	if name == PolicyTinyLFU {
+		p := &tinyLFUPolicy{sketch: newCountMinSketch(capacity)}
+		p.reset()
+		return p
+	}
+	p := &lruPolicy{}
+	p.reset()
+	return p
+}
+
+// lruPolicy 最近最少使用
+type lruPolicy struct {
+	list entryList
+}
+
+func (p *lruPolicy) record(hash uint64) {}
+
+func (p *lruPolicy) add(e *memoryEntry) {
+	e.segment = segmentLRU
+	p.list.pushFront(e)
+}
+
+func (p *lruPolicy) access(e *memoryEntry) { p.list.moveToFront(e) }
+
+func (p *lruPolicy) remove(e *memoryEntry) { p.list.remove(e) }
+
+func (p *lruPolicy) victim() *memoryEntry { return p.list.back() }
+
+func (p *lruPolicy) reset() { p.list.init() }
+
+// tinyLFUPolicy W-TinyLFU 淘汰策略
+// 新条目进入约占 1% 的 LRU 窗口，离开窗口后进入试用区；试用区中再次被访问的条目升入约占主区域 80% 的保护区。
+// 需要淘汰时，刚离开窗口的候选与试用区最久未访问的条目比较估算的访问频率，频率低者被淘汰，
+// 偶尔访问的条目因此无法挤掉热门译文
+type tinyLFUPolicy struct {
+	sketch    *countMinSketch
+	window    entryList
+	probation entryList
+	protected entryList
+}
+
+func (p *tinyLFUPolicy) record(hash uint64) { p.sketch.increment(hash) }
+
+func (p *tinyLFUPolicy) add(e *memoryEntry) {
+	e.segment = segmentWindow
+	p.window.pushFront(e)
+
+	// 窗口超出份额时，最久未访问的条目进入试用区，等待淘汰时与主区域比较
+	total := p.window.len + p.probation.len + p.protected.len
+	for p.window.len > max(1, total/100) {
+		c := p.window.back()
+		p.window.remove(c)
+		c.segment = segmentProbation
+		c.candidate = true
+		p.probation.pushFront(c)
+	}
+}
+
+func (p *tinyLFUPolicy) access(e *memoryEntry) {
+	switch e.segment {
+	case segmentWindow:
+		p.window.moveToFront(e)
+	case segmentProtected:
+		p.protected.moveToFront(e)
+	case segmentProbation:
+		p.probation.remove(e)
+		e.candidate = false
+		e.segment = segmentProtected
+		p.protected.pushFront(e)
+
+		// 保护区超出份额时，最久未访问的条目降回试用区
+		for p.protected.len > max(1, (p.probation.len+p.protected.len)*8/10) {
+			d := p.protected.back()
+			p.protected.remove(d)
+			d.segment = segmentProbation
+			p.probation.pushFront(d)
+		}
+	}
+}
+
+func (p *tinyLFUPolicy) remove(e *memoryEntry) {
+	switch e.segment {
+	case segmentWindow:
+		p.window.remove(e)
+	case segmentProbation:
+		p.probation.remove(e)
+	case segmentProtected:
+		p.protected.remove(e)
+	}
+}
+
+func (p *tinyLFUPolicy) victim() *memoryEntry {
+	victim := p.probation.back()
+	if victim == nil {
+		victim = p.protected.back()
+	}
+	if victim == nil {
+		return p.window.back()
+	}
+
+	if c := p.probation.front(); c != nil && c.candidate && c != victim {
+		if p.sketch.estimate(c.hash) <= p.sketch.estimate(victim.hash) {
+			return c
+		}
+		c.candidate = false
+	}
+	return victim
+}
+
+func (p *tinyLFUPolicy) reset() {
+	p.window.init()
+	p.probation.init()
+	p.protected.init()
+	p.sketch.reset()
+}
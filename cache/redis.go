@@ -3,24 +3,41 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// scanBatchSize SCAN 每批返回的键数，也是 MGET/DEL 每批处理的键数
+const scanBatchSize = 500
+
+// translationKeyPatterns Clear 删除的译文缓存键：v2 缓存键、v1 缓存键 transbridge:<md5> 与旧前缀 transbrige:，
+// 同一数据库中的任务、锁等其它键不受影响
+var translationKeyPatterns = []string{
+	"transbridge:v2:*",
+	"transbridge:" + strings.Repeat("[0-9a-f]", 32),
+	"transbrige:*",
+}
+
 type RedisCache struct {
 	client     *redis.Client
 	defaultTTL time.Duration
 	permanent  bool
 }
 
-// NewRedisCache 创建一个新的Redis缓存
-func NewRedisCache(opts RedisCacheOptions) *RedisCache {
-	client := redis.NewClient(&redis.Options{
+// NewRedisClient 按缓存的 Redis 配置创建客户端，供需要共用同一 Redis 的其它组件（如异步任务存储）使用
+func NewRedisClient(opts RedisCacheOptions) *redis.Client {
+	return redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", opts.Host, opts.Port),
 		Password: opts.Password,
 		DB:       opts.DB,
 	})
+}
+
+// NewRedisCache 创建一个新的Redis缓存
+func NewRedisCache(opts RedisCacheOptions) *RedisCache {
+	client := NewRedisClient(opts)
 
 	// 设置默认TTL
 	defaultTTL := opts.DefaultTTL
@@ -67,9 +84,77 @@ func (c *RedisCache) Set(ctx context.Context, key string, value string, ttl time
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
-// Clear 清空缓存
+// Delete 删除指定的键
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
+// Scan 依次返回以 prefix 开头的条目，使用 SCAN 分批遍历，不阻塞 Redis
+// 遍历期间写入或删除的键可能返回也可能不返回
+func (c *RedisCache) Scan(ctx context.Context, prefix string, fn func(key, value string) bool) error {
+	return c.scanKeys(ctx, escapePattern(prefix)+"*", func(keys []string) (bool, error) {
+		values, err := c.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return false, err
+		}
+		for i, v := range values {
+			value, ok := v.(string)
+			if !ok {
+				continue // 遍历期间已过期或被删除
+			}
+			if !fn(keys[i], value) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// Clear 删除所有译文缓存键，不再使用 FLUSHDB，见 translationKeyPatterns
 func (c *RedisCache) Clear(ctx context.Context) error {
-	return c.client.FlushDB(ctx).Err()
+	for _, pattern := range translationKeyPatterns {
+		err := c.scanKeys(ctx, pattern, func(keys []string) (bool, error) {
+			return true, c.client.Del(ctx, keys...).Err()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanKeys 按 pattern 分批遍历键，fn 返回 false 或错误时停止
+func (c *RedisCache) scanKeys(ctx context.Context, pattern string, fn func(keys []string) (bool, error)) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			more, err := fn(keys)
+			if err != nil || !more {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// escapePattern 转义 SCAN MATCH 中的通配字符
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
 }
 
 // Close 关闭Redis连接
@@ -0,0 +1,81 @@
+package cache
+
+// 频率统计的规模：每行计数器数量取分片容量对应的 2 的幂，并限制在该范围内
+const (
+	minSketchWidth = 64
+	maxSketchWidth = 1 << 16
+)
+
+// 计数器上限，与 4 位计数器一致
+const maxSketchCount = 15
+
+// sketchSeeds 各行的哈希种子
+var sketchSeeds = [4]uint64{0xc3a5c85c97cb3127, 0xb492b66fbe98f273, 0x9ae16a3b2f90404f, 0xcbf29ce484222325}
+
+// countMinSketch 估算键访问频率的 Count-Min Sketch
+// 累计记录次数达到计数器总数的 10 倍时所有计数减半，使频率反映近期的访问
+type countMinSketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+func newCountMinSketch(capacity int) *countMinSketch {
+	width := minSketchWidth
+	for width < capacity && width < maxSketchWidth {
+		width <<= 1
+	}
+	s := &countMinSketch{
+		mask:    uint64(width - 1),
+		resetAt: 10 * width,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *countMinSketch) index(hash uint64, row int) uint64 {
+	h := (hash ^ sketchSeeds[row]) * 0x9e3779b97f4a7c15
+	return (h ^ h>>31) & s.mask
+}
+
+func (s *countMinSketch) increment(hash uint64) {
+	for i := range s.rows {
+		idx := s.index(hash, i)
+		if s.rows[i][idx] < maxSketchCount {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.halve()
+	}
+}
+
+func (s *countMinSketch) estimate(hash uint64) uint8 {
+	count := uint8(maxSketchCount)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(hash, i)]; v < count {
+			count = v
+		}
+	}
+	return count
+}
+
+func (s *countMinSketch) halve() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.additions = 0
+}
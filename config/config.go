@@ -14,6 +14,140 @@ type Config struct {
 	OpenAI    OpenAIConfig     `yaml:"openai"`   // 新增 OpenAI 配置
 	TransAPI  TransAPI         `yaml:"transapi"` // 新增认证配置
 	Log       LogConfig        `yaml:"log"`      // 新增日志配置
+	Failover  FailoverConfig   `yaml:"failover"` // 模型故障转移配置
+
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"` // 模型熔断配置
+	Admin          AdminConfig          `yaml:"admin"`           // 管理接口配置
+	LibreTranslate LibreTranslateConfig `yaml:"libretranslate"`  // LibreTranslate 兼容接口配置
+	Detection      DetectionConfig      `yaml:"detection"`       // 源语言自动检测配置
+	Translation    TranslationConfig    `yaml:"translation"`     // 翻译行为配置
+	Glossary       GlossaryConfig       `yaml:"glossary"`        // 术语表配置
+	Jobs           JobsConfig           `yaml:"jobs"`            // 异步翻译任务配置
+
+	TranslationMemory TranslationMemoryConfig `yaml:"translation_memory"` // 翻译记忆配置
+}
+
+// JobsConfig 异步翻译任务配置
+type JobsConfig struct {
+	Enabled     bool   `yaml:"enabled"`     // 是否启用 /jobs 接口
+	Store       string `yaml:"store"`       // 任务存储：memory 或 redis（使用 cache.redis 的连接配置），默认 memory
+	Workers     int    `yaml:"workers"`     // 同时执行的任务数，默认 2
+	QueueSize   int    `yaml:"queue_size"`  // 排队任务数上限，默认 100
+	Concurrency int    `yaml:"concurrency"` // 单个任务内并发翻译的文本数，默认 4
+	Retention   TTL    `yaml:"retention"`   // 任务结束后保留的时间，默认 1d
+	LeaseTTL    int    `yaml:"lease_ttl"`   // 执行任务、投递回调的租约时长（秒），默认 30；多实例共用 redis 存储时防止重复执行
+
+	Webhook WebhookConfig `yaml:"webhook"` // 任务结束后的回调
+}
+
+// WebhookConfig 任务回调配置
+type WebhookConfig struct {
+	Timeout     int               `yaml:"timeout"`      // 单次投递超时（秒），默认 10
+	MaxAttempts int               `yaml:"max_attempts"` // 最多投递次数（含首次），默认 5
+	Backoff     int               `yaml:"backoff"`      // 首次重试前的等待时间（秒），之后每次翻倍，最长 5 分钟，默认 5
+	Secrets     map[string]string `yaml:"secrets"`      // API 密钥到签名密钥的映射，未配置的密钥使用 API 密钥本身签名
+	Allowlist   []string          `yaml:"allowlist"`    // 允许回调的主机名、IP 或 CIDR，为空时只禁止回环、链路本地与内网地址
+}
+
+// GlossaryConfig 术语表配置
+type GlossaryConfig struct {
+	Enabled     bool                 `yaml:"enabled"`     // 是否启用术语表
+	Enforcement string               `yaml:"enforcement"` // 译文校验方式：off/flag/repair，默认 flag
+	Files       []GlossaryFileConfig `yaml:"files"`       // 启动时加载的术语文件
+}
+
+// GlossaryFileConfig 术语文件，CSV 或 TSV（按扩展名区分），语言可填 "*" 表示任意语言
+type GlossaryFileConfig struct {
+	Path       string `yaml:"path"`
+	SourceLang string `yaml:"source_lang"`
+	TargetLang string `yaml:"target_lang"`
+}
+
+// TranslationMemoryConfig 翻译记忆配置
+type TranslationMemoryConfig struct {
+	Enabled            bool               `yaml:"enabled"`             // 是否启用翻译记忆
+	ReuseThreshold     float64            `yaml:"reuse_threshold"`     // 相似度（0~1）不低于该值时直接采用记忆中的译文，默认 1 即只采用原文相同的条目，大于 1 表示从不直接采用
+	ReferenceThreshold float64            `yaml:"reference_threshold"` // 相似度不低于该值的条目作为参考译文附加到提示词，默认 0.7
+	MaxReferences      int                `yaml:"max_references"`      // 单次翻译最多附带的参考译文数，默认 3
+	Learn              bool               `yaml:"learn"`               // 模型给出的纯文本译文写入翻译记忆（仅保存在内存中）
+	MaxLearned         int                `yaml:"max_learned"`         // 自动写入条目数的上限，达到后不再写入，0 表示不限制
+	Files              []MemoryFileConfig `yaml:"files"`               // 启动时加载的 TMX 文件
+}
+
+// MemoryFileConfig TMX 文件，语言为空时使用文件中的语言
+type MemoryFileConfig struct {
+	Path       string `yaml:"path"`
+	SourceLang string `yaml:"source_lang"`
+	TargetLang string `yaml:"target_lang"`
+}
+
+// TranslationConfig 翻译行为配置
+type TranslationConfig struct {
+	SkipSameLanguage          bool           `yaml:"skip_same_language"`           // 源语言与目标语言相同时直接返回原文
+	SameLanguageMinConfidence float64        `yaml:"same_language_min_confidence"` // 源语言由自动检测得出时，置信度不低于该值才直接返回原文，默认 0.9
+	BatchConcurrency          int            `yaml:"batch_concurrency"`            // 批量接口同时翻译的文本数（合并调用时为同时进行的调用数），默认 5
+	Chunking                  ChunkingConfig `yaml:"chunking"`                     // 长文本分块翻译
+	Batching                  BatchingConfig `yaml:"batching"`                     // 批量翻译合并调用
+
+	DistributedLock DistributedLockConfig `yaml:"distributed_lock"` // 多实例部署时的翻译锁
+}
+
+// DistributedLockConfig 多实例部署时的分布式翻译锁配置
+type DistributedLockConfig struct {
+	Enabled     bool `yaml:"enabled"`      // 通过 Redis 锁协调各实例，同一文本只有一个实例调用模型（需要 cache.types 包含 redis）
+	TTL         int  `yaml:"ttl"`          // 锁的过期时间（秒），持有期间自动续期，默认 60
+	WaitTimeout int  `yaml:"wait_timeout"` // 等待其它实例翻译结果的最长时间（秒），超时后自行翻译，默认 30
+}
+
+// BatchingConfig 批量翻译合并调用配置
+type BatchingConfig struct {
+	Enabled     bool `yaml:"enabled"`      // 批量接口中未命中缓存的文本合并为一次模型调用
+	MaxSegments int  `yaml:"max_segments"` // 单次调用最多包含的文本数，默认 20
+	MaxTokens   int  `yaml:"max_tokens"`   // 单次调用原文的最大 token 数，0 表示取模型 max_tokens 的一半
+}
+
+// ChunkingConfig 长文本分块翻译配置
+type ChunkingConfig struct {
+	Enabled       bool `yaml:"enabled"`        // 超出模型输出上限的文本按段落、句子拆分后分块翻译
+	MaxTokens     int  `yaml:"max_tokens"`     // 每块原文的最大 token 数，0 表示取模型 max_tokens 的一半
+	ContextTokens int  `yaml:"context_tokens"` // 携带上一块原文与译文作为上下文的 token 数，默认 200，负数表示不携带
+}
+
+// DetectionConfig 源语言自动检测配置
+type DetectionConfig struct {
+	MinConfidence float64 `yaml:"min_confidence"` // 本地检测结果的最低置信度（0~1），默认 0.6
+	LLMFallback   bool    `yaml:"llm_fallback"`   // 本地检测置信度不足时是否改用模型识别
+}
+
+// LibreTranslateConfig LibreTranslate 兼容接口配置
+type LibreTranslateConfig struct {
+	Enabled        bool   `yaml:"enabled"`         // 是否启用
+	Path           string `yaml:"path"`            // 路径前缀，默认 /libre
+	AllowAnonymous bool   `yaml:"allow_anonymous"` // 是否允许不带 api_key 访问
+}
+
+// CircuitBreakerConfig 按模型的熔断配置
+type CircuitBreakerConfig struct {
+	Enabled            bool    `yaml:"enabled"`              // 是否启用熔断
+	FailureThreshold   int     `yaml:"failure_threshold"`    // 连续失败多少次后熔断，默认 5
+	ErrorRateThreshold float64 `yaml:"error_rate_threshold"` // 窗口内错误率阈值（0~1），默认 0.5
+	MinRequests        int     `yaml:"min_requests"`         // 计算错误率所需的最少请求数，默认 10
+	WindowSize         int     `yaml:"window_size"`          // 滚动窗口大小，默认 20
+	OpenTimeout        int     `yaml:"open_timeout"`         // 熔断持续时间（秒），默认 30
+	HalfOpenProbes     int     `yaml:"half_open_probes"`     // 半开状态下的探测请求数，默认 1
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Enabled bool     `yaml:"enabled"` // 是否启用管理接口
+	Path    string   `yaml:"path"`    // 路径前缀，默认 /admin
+	Tokens  []string `yaml:"tokens"`  // 管理接口令牌列表
+}
+
+// FailoverConfig 模型故障转移配置
+type FailoverConfig struct {
+	Enabled     bool `yaml:"enabled"`      // 是否启用故障转移
+	MaxAttempts int  `yaml:"max_attempts"` // 单次翻译最多尝试的模型数（含首选模型），默认 3
 }
 
 // LogConfig 日志配置
@@ -29,6 +163,10 @@ type LogConfig struct {
 type ServerConfig struct {
 	Port int    `yaml:"port"`
 	Host string `yaml:"host"`
+
+	ReadTimeout  int `yaml:"read_timeout"`  // 读取请求的超时时间（秒），默认 15
+	WriteTimeout int `yaml:"write_timeout"` // 写入响应的超时时间（秒），默认 15；长文本同步翻译可调大或改用 /jobs
+	IdleTimeout  int `yaml:"idle_timeout"`  // 空闲连接的超时时间（秒），默认 60
 }
 
 type ProviderConfig struct {
@@ -52,15 +190,34 @@ type ModelConfig struct {
 // CacheConfig 缓存配置
 type CacheConfig struct {
 	Enabled bool         `yaml:"enabled"`
-	Types   []string     `yaml:"types"`  // 支持的缓存类型：["memory", "redis"]
+	Types   []string     `yaml:"types"`  // 支持的缓存类型：["memory", "redis", "disk"]
 	Memory  MemoryConfig `yaml:"memory"` // 内存缓存特定配置
 	Redis   RedisConfig  `yaml:"redis"`  // Redis缓存特定配置
+	Disk    DiskConfig   `yaml:"disk"`   // 磁盘缓存特定配置
+
+	Key CacheKeyConfig `yaml:"key"` // 缓存键配置
+}
+
+// CacheKeyConfig 缓存键配置
+type CacheKeyConfig struct {
+	Version      string   `yaml:"version"`       // 缓存键版本：v1（transbridge:<md5>）或 v2（transbridge:v2:<源语言>:<目标语言>:<md5>），默认 v2
+	Dimensions   []string `yaml:"dimensions"`    // 参与 v2 缓存键计算的维度：prompt、model、temperature、formality、glossary，未配置时全部启用
+	LegacyLookup bool     `yaml:"legacy_lookup"` // v2 缓存键未命中时查找 v1 缓存键，未启用 prompt 维度时命中后写入新键，默认关闭
 }
 
 // MemoryConfig 内存缓存特定配置
 type MemoryConfig struct {
-	TTL     TTL `yaml:"ttl"`      // 缓存过期时间
-	MaxSize int `yaml:"max_size"` // 内存缓存最大条目数
+	TTL       TTL    `yaml:"ttl"`        // 缓存过期时间
+	MaxSize   int    `yaml:"max_size"`   // 内存缓存最大条目数
+	MaxMemory int    `yaml:"max_memory"` // 内存缓存最大占用（MB），按原文与译文的长度估算，0 表示不限制
+	Policy    string `yaml:"policy"`     // 淘汰策略：lru（默认）或 tinylfu
+}
+
+// DiskConfig 磁盘缓存特定配置
+type DiskConfig struct {
+	Path            string `yaml:"path"`             // 数据文件路径，默认 data/cache.db
+	TTL             TTL    `yaml:"ttl"`              // 缓存过期时间，默认永久
+	CompactInterval TTL    `yaml:"compact_interval"` // 检查是否需要压缩数据文件的间隔，默认 1h，permanent 表示不压缩
 }
 
 // RedisConfig Redis缓存特定配置
@@ -73,6 +230,15 @@ type RedisConfig struct {
 }
 
 type PromptConfig struct {
+	Template string             `yaml:"template"`
+	System   string             `yaml:"system"` // 可选的系统提示词
+	Pairs    []PromptPairConfig `yaml:"pairs"`  // 按语言对覆盖的提示词模板
+}
+
+// PromptPairConfig 语言对提示词模板，pair 形如 "zh->en"、"*->ja"，未填写的字段沿用默认值
+type PromptPairConfig struct {
+	Pair     string `yaml:"pair"`
+	System   string `yaml:"system"`
 	Template string `yaml:"template"`
 }
 
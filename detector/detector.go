@@ -0,0 +1,129 @@
+// detector/detector.go
+package detector
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Result 语言检测结果
+type Result struct {
+	Language   string  // BCP 47 语言代码，例如 "en"、"zh-Hans"
+	Confidence float64 // 置信度（0~1）
+}
+
+// minLetters 少于该字母数的文本置信度会被按比例降低（中日韩文字除外）
+const minLetters = 20
+
+// LocalDetector 基于文字系统与字符三元组的本地语言检测器，不依赖外部服务
+type LocalDetector struct {
+	profiles map[string]*profile
+}
+
+// NewLocalDetector 创建本地语言检测器，内置的三元组语料在创建时完成统计
+func NewLocalDetector() *LocalDetector {
+	return &LocalDetector{
+		profiles: buildProfiles(),
+	}
+}
+
+// Detect 检测文本语言；无法判断时返回空语言与 0 置信度
+func (d *LocalDetector) Detect(text string) Result {
+	counts := countScripts(text)
+	if counts.letters == 0 {
+		return Result{}
+	}
+
+	result := d.detectByScript(text, counts)
+
+	// 文本过短时结果不可靠，按字母数降低置信度；中日韩文字单字即可确定文字系统，不降低
+	if counts.letters < minLetters && !counts.cjk() {
+		result.Confidence *= float64(counts.letters) / minLetters
+	}
+	return result
+}
+
+// detectByScript 先根据主要文字系统判断，拉丁与西里尔字母再交给三元组模型区分
+func (d *LocalDetector) detectByScript(text string, counts scriptCounts) Result {
+	script, n := counts.dominant()
+	share := float64(n) / float64(counts.letters)
+
+	switch script {
+	case scriptHan:
+		// 日文混用汉字与假名，只要出现足够假名即判为日文
+		if counts.byScript[scriptKana]*10 >= counts.letters {
+			return Result{Language: "ja", Confidence: 0.99}
+		}
+		return Result{Language: chineseVariant(text), Confidence: 0.95 * share}
+	case scriptKana:
+		return Result{Language: "ja", Confidence: 0.99}
+	case scriptLatin:
+		return d.detectByNgrams(text, latinLanguages, share)
+	case scriptCyrillic:
+		return d.detectByNgrams(text, cyrillicLanguages, share)
+	case scriptArabic:
+		return Result{Language: arabicVariant(text), Confidence: 0.9 * share}
+	default:
+		if lang, ok := scriptLanguages[script]; ok {
+			return Result{Language: lang, Confidence: 0.95 * share}
+		}
+		return Result{}
+	}
+}
+
+// detectByNgrams 在候选语言中选出三元组似然最高的语言，文本中出现某语言不使用的字母时降低该语言的得分
+func (d *LocalDetector) detectByNgrams(text string, candidates []string, share float64) Result {
+	grams := extractTrigrams(text)
+	if len(grams) == 0 {
+		return Result{}
+	}
+
+	scores := make(map[string]float64, len(candidates))
+	for _, lang := range candidates {
+		if p, ok := d.profiles[lang]; ok {
+			scores[lang] = p.score(grams) - foreignLetterPenalty*float64(foreignLetters(text, lang))
+		}
+	}
+
+	best, confidence := softmaxBest(scores)
+	return Result{Language: best, Confidence: confidence * share}
+}
+
+// chineseVariant 根据简繁特有字判断中文书写形式
+func chineseVariant(text string) string {
+	var simplified, traditional int
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(simplifiedOnly, r):
+			simplified++
+		case strings.ContainsRune(traditionalOnly, r):
+			traditional++
+		}
+	}
+
+	switch {
+	case traditional > simplified:
+		return "zh-Hant"
+	case simplified > traditional:
+		return "zh-Hans"
+	default:
+		return "zh"
+	}
+}
+
+// arabicVariant 根据波斯语、乌尔都语特有字母区分阿拉伯字母书写的语言
+func arabicVariant(text string) string {
+	switch {
+	case strings.ContainsAny(text, "ٹڈڑںے"):
+		return "ur"
+	case strings.ContainsAny(text, "پچژگ"):
+		return "fa"
+	default:
+		return "ar"
+	}
+}
+
+// isLetter 判断是否为参与检测的字母（忽略数字、标点与空白）
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r)
+}
@@ -0,0 +1,314 @@
+package detector
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// softmaxScale 计算置信度时参与缩放的三元组数量上限，避免长文本置信度过度饱和
+const softmaxScale = 30
+
+// latinLanguages 使用拉丁字母、由三元组模型区分的语言
+var latinLanguages = []string{
+	"en", "de", "fr", "es", "it", "pt", "nl", "sv", "da", "nb",
+	"fi", "pl", "cs", "ro", "hu", "tr", "id", "vi",
+}
+
+// cyrillicLanguages 使用西里尔字母、由三元组模型区分的语言
+var cyrillicLanguages = []string{"ru", "uk", "bg"}
+
+// foreignLetterPenalty 文本每出现一个候选语言字母表之外的字母，该语言得分扣减的值
+const foreignLetterPenalty = 4
+
+// alphabets 各语言使用的字母：拉丁字母语言只列出 a~z 以外的字母，西里尔字母语言为完整字母表
+// 某些字母只有个别语言使用（俄语 ё、ы、э，乌克兰语 і、ї、є，葡萄牙语 ã、õ 等），出现时据此排除相近的语言
+var alphabets = map[string]string{
+	"en": "",
+	"de": "äöüß",
+	"fr": "àâæçéèêëîïôœùûüÿ",
+	"es": "áéíñóúü",
+	"it": "àèéìíîòóù",
+	"pt": "áâãàçéêíóôõú",
+	"nl": "éèëïóöü",
+	"sv": "åäöé",
+	"da": "æøåé",
+	"nb": "æøåéóòô",
+	"fi": "äöå",
+	"pl": "ąćęłńóśźż",
+	"cs": "áčďéěíňóřšťúůýž",
+	"ro": "ăâîșțşţ",
+	"hu": "áéíóöőúüű",
+	"tr": "çğıöşüâî",
+	"id": "",
+	"vi": "àáâãèéêìíòóôõùúýăđĩũơưạảấầẩẫậắằẳẵặẹẻẽếềểễệỉịọỏốồổỗộớờởỡợụủứừửữựỳỵỷỹ",
+	"ru": "абвгдеёжзийклмнопрстуфхцчшщъыьэюя",
+	"uk": "абвгґдеєжзиіїйклмнопрстуфхцчшщьюя",
+	"bg": "абвгдежзийклмнопрстуфхцчшщъьюя",
+}
+
+// foreignLetters 统计文本中不属于语言 lang 字母表的字母数，基本拉丁字母不计
+func foreignLetters(text, lang string) int {
+	alphabet := alphabets[lang]
+	n := 0
+	for _, r := range strings.ToLower(text) {
+		if r > unicode.MaxASCII && unicode.IsLetter(r) && !strings.ContainsRune(alphabet, r) {
+			n++
+		}
+	}
+	return n
+}
+
+// profile 单个语言的三元组频次统计
+type profile struct {
+	counts map[string]int
+	total  int
+}
+
+// score 返回文本三元组在该语言下的平均对数似然（加一平滑）
+func (p *profile) score(grams []string) float64 {
+	vocabulary := float64(len(p.counts) + 1000)
+	var sum float64
+	for _, g := range grams {
+		sum += math.Log(float64(p.counts[g]+1) / (float64(p.total) + vocabulary))
+	}
+	return sum / float64(len(grams)) * math.Min(float64(len(grams)), softmaxScale)
+}
+
+// buildProfiles 从内置语料统计各语言的三元组频次
+func buildProfiles() map[string]*profile {
+	profiles := make(map[string]*profile, len(corpus))
+	for lang, text := range corpus {
+		p := &profile{counts: make(map[string]int)}
+		for _, g := range extractTrigrams(text) {
+			p.counts[g]++
+			p.total++
+		}
+		profiles[lang] = p
+	}
+	return profiles
+}
+
+// extractTrigrams 将文本转为小写后按单词（两侧补空格）切分出字符三元组
+func extractTrigrams(text string) []string {
+	var grams []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+3]))
+		}
+	}
+	return grams
+}
+
+// softmaxBest 返回得分最高的语言及其 softmax 概率
+func softmaxBest(scores map[string]float64) (string, float64) {
+	best := ""
+	maxScore := math.Inf(-1)
+	for lang, s := range scores {
+		if s > maxScore || (s == maxScore && lang < best) {
+			best, maxScore = lang, s
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - maxScore)
+	}
+	return best, 1 / sum
+}
+
+// corpus 各语言的训练语料（世界人权宣言第一条及常用句子、界面文案与日常对话）
+var corpus = map[string]string{
+	"en": "All human beings are born free and equal in dignity and rights. They are endowed with reason and conscience and should act towards one another in a spirit of brotherhood. " +
+		"I do not know what we are going to do with this problem, but we have to find a solution for everyone who is here. " +
+		"The quick brown fox jumps over the lazy dog. Please click the button below to continue, and let us know if you have any questions about the new features. " +
+		"This is the first time that they have been able to share their work with the rest of the world, which would not have happened without your help. " +
+		"Thank you for your order. We will send you an email as soon as your package has shipped. If you forgot your password, you can reset it on the login page at any time. " +
+		"Today the weather is nice, so my friends and I are going to the market and then to the beach. " +
+		"Our team works every day to make the service faster, safer and easier to use. Where is the station? " +
+		"Tomorrow I have to call my brother, because we have not seen each other for a long time and the children miss him.",
+	"de": "Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind mit Vernunft und Gewissen begabt und sollen einander im Geist der Brüderlichkeit begegnen. " +
+		"Ich weiß nicht, was wir mit diesem Problem machen werden, aber wir müssen eine Lösung für alle finden, die hier sind. " +
+		"Bitte klicken Sie auf die Schaltfläche unten, um fortzufahren, und lassen Sie uns wissen, wenn Sie Fragen zu den neuen Funktionen haben. " +
+		"Das ist das erste Mal, dass sie ihre Arbeit mit dem Rest der Welt teilen konnten, was ohne Ihre Hilfe nicht möglich gewesen wäre. " +
+		"Vielen Dank für Ihre Bestellung. Wir senden Ihnen eine E-Mail, sobald Ihr Paket verschickt wurde. " +
+		"Wenn Sie Ihr Passwort vergessen haben, können Sie es jederzeit auf der Anmeldeseite zurücksetzen. " +
+		"Heute ist schönes Wetter, deshalb gehen meine Freunde und ich auf den Markt und danach an den Strand. " +
+		"Unser Team arbeitet jeden Tag daran, den Dienst schneller, sicherer und einfacher zu machen. Wo ist der Bahnhof? " +
+		"Morgen muss ich meinen Bruder anrufen, weil wir uns lange nicht gesehen haben und die Kinder ihn vermissen.",
+	"fr": "Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils sont doués de raison et de conscience et doivent agir les uns envers les autres dans un esprit de fraternité. " +
+		"Je ne sais pas ce que nous allons faire avec ce problème, mais nous devons trouver une solution pour tous ceux qui sont ici. " +
+		"Veuillez cliquer sur le bouton ci-dessous pour continuer, et faites-nous savoir si vous avez des questions sur les nouvelles fonctionnalités. " +
+		"C'est la première fois qu'ils ont pu partager leur travail avec le reste du monde, ce qui n'aurait pas été possible sans votre aide. " +
+		"Merci pour votre commande. Nous vous enverrons un courriel dès que votre colis aura été expédié. " +
+		"Si vous avez oublié votre mot de passe, vous pouvez le réinitialiser à tout moment sur la page de connexion. " +
+		"Aujourd'hui il fait beau, alors mes amis et moi allons au marché puis à la plage. " +
+		"Notre équipe travaille chaque jour pour rendre le service plus rapide, plus sûr et plus facile à utiliser. Où est la gare ? " +
+		"Demain je dois appeler mon frère, parce que nous ne nous sommes pas vus depuis longtemps et que les enfants s'ennuient de lui.",
+	"es": "Todos los seres humanos nacen libres e iguales en dignidad y derechos y, dotados como están de razón y conciencia, deben comportarse fraternalmente los unos con los otros. " +
+		"No sé qué vamos a hacer con este problema, pero tenemos que encontrar una solución para todos los que están aquí. " +
+		"Por favor, haga clic en el botón de abajo para continuar y háganos saber si tiene alguna pregunta sobre las nuevas funciones. " +
+		"Es la primera vez que han podido compartir su trabajo con el resto del mundo, lo que no habría sido posible sin su ayuda. " +
+		"Gracias por su pedido. Le enviaremos un correo electrónico en cuanto su paquete haya sido enviado. " +
+		"Si olvidó su contraseña, puede restablecerla en cualquier momento en la página de inicio de sesión. " +
+		"Hoy hace buen tiempo, así que mis amigos y yo vamos al mercado y después a la playa. " +
+		"Nuestro equipo trabaja todos los días para que el servicio sea más rápido, más seguro y más fácil de usar. ¿Dónde está la estación? " +
+		"Mañana tengo que llamar a mi hermano, porque hace mucho que no nos vemos y los niños lo echan de menos.",
+	"it": "Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi sono dotati di ragione e di coscienza e devono agire gli uni verso gli altri in spirito di fratellanza. " +
+		"Non so cosa faremo con questo problema, ma dobbiamo trovare una soluzione per tutti quelli che sono qui. " +
+		"Si prega di fare clic sul pulsante qui sotto per continuare e di farci sapere se avete domande sulle nuove funzionalità. " +
+		"È la prima volta che sono riusciti a condividere il loro lavoro con il resto del mondo, cosa che non sarebbe stata possibile senza il vostro aiuto. " +
+		"Grazie per il suo ordine. Le invieremo un'e-mail non appena il pacco sarà stato spedito. " +
+		"Se ha dimenticato la password, può reimpostarla in qualsiasi momento nella pagina di accesso. " +
+		"Oggi il tempo è bello, quindi io e i miei amici andiamo al mercato e poi in spiaggia. " +
+		"Il nostro gruppo lavora ogni giorno per rendere il servizio più veloce, più sicuro e più facile da usare. Dov'è la stazione? " +
+		"Domani devo chiamare mio fratello, perché non ci vediamo da molto tempo e i bambini sentono la sua mancanza. Ciao, come stai? Sto bene, grazie, e tu? " +
+		"Questa sera ceniamo tutti insieme a casa di mia sorella.",
+	"pt": "Todos os seres humanos nascem livres e iguais em dignidade e em direitos. Dotados de razão e de consciência, devem agir uns para com os outros em espírito de fraternidade. " +
+		"Não sei o que vamos fazer com este problema, mas precisamos encontrar uma solução para todos que estão aqui. " +
+		"Por favor, clique no botão abaixo para continuar e nos avise se tiver alguma dúvida sobre os novos recursos. " +
+		"É a primeira vez que eles conseguiram compartilhar o seu trabalho com o resto do mundo, o que não teria sido possível sem a sua ajuda. " +
+		"Obrigado pelo seu pedido. Enviaremos um e-mail assim que a sua encomenda for despachada. " +
+		"Se você esqueceu a sua senha, pode redefini-la a qualquer momento na página de login. Hoje o tempo está bom, então eu e os meus amigos vamos à feira e depois à praia. " +
+		"A nossa equipe trabalha todos os dias para tornar o serviço mais rápido, mais seguro e mais fácil de usar. Onde fica a estação? " +
+		"Amanhã preciso ligar para o meu irmão, porque não nos vemos há muito tempo e as crianças estão com saudades dele. Olá, tudo bem? Estou bem, obrigado, e você? " +
+		"Esta noite vamos jantar juntos em casa da minha irmã, não é?",
+	"nl": "Alle mensen worden vrij en gelijk in waardigheid en rechten geboren. Zij zijn begiftigd met verstand en geweten, en behoren zich jegens elkander in een geest van broederschap te gedragen. " +
+		"Ik weet niet wat we met dit probleem gaan doen, maar we moeten een oplossing vinden voor iedereen die hier is. " +
+		"Klik op de onderstaande knop om door te gaan en laat het ons weten als u vragen heeft over de nieuwe functies. " +
+		"Het is de eerste keer dat zij hun werk met de rest van de wereld konden delen, wat zonder uw hulp niet mogelijk was geweest. " +
+		"Bedankt voor uw bestelling. We sturen u een e-mail zodra uw pakket is verzonden. " +
+		"Als u uw wachtwoord bent vergeten, kunt u het op elk moment opnieuw instellen op de inlogpagina. " +
+		"Vandaag is het mooi weer, dus mijn vrienden en ik gaan naar de markt en daarna naar het strand. " +
+		"Ons team werkt elke dag om de dienst sneller, veiliger en gemakkelijker te maken. Waar is het station? " +
+		"Morgen moet ik mijn broer bellen, omdat we elkaar lang niet hebben gezien en de kinderen hem missen.",
+	"sv": "Alla människor är födda fria och lika i värde och rättigheter. De har utrustats med förnuft och samvete och bör handla gentemot varandra i en anda av broderskap. " +
+		"Jag vet inte vad vi ska göra med det här problemet, men vi måste hitta en lösning för alla som är här. " +
+		"Klicka på knappen nedan för att fortsätta och låt oss veta om du har några frågor om de nya funktionerna. " +
+		"Det är första gången som de har kunnat dela sitt arbete med resten av världen, vilket inte hade varit möjligt utan din hjälp. " +
+		"Tack för din beställning. Vi skickar ett e-postmeddelande till dig så snart ditt paket har skickats. " +
+		"Om du har glömt ditt lösenord kan du när som helst återställa det på inloggningssidan. " +
+		"Idag är det fint väder, så mina vänner och jag går till torget och sedan till stranden. " +
+		"Vårt team arbetar varje dag för att göra tjänsten snabbare, säkrare och enklare att använda. Var ligger stationen? " +
+		"I morgon måste jag ringa min bror, eftersom vi inte har träffats på länge och barnen saknar honom.",
+	"da": "Alle mennesker er født frie og lige i værdighed og rettigheder. De er udstyret med fornuft og samvittighed, og de bør handle mod hverandre i en broderskabets ånd. " +
+		"Jeg ved ikke, hvad vi skal gøre med dette problem, men vi skal finde en løsning for alle, der er her. " +
+		"Klik på knappen nedenfor for at fortsætte, og lad os vide, hvis du har spørgsmål om de nye funktioner. " +
+		"Det er første gang, at de har kunnet dele deres arbejde med resten af verden, hvilket ikke havde været muligt uden din hjælp. " +
+		"Tak for din bestilling. Vi sender dig en e-mail, så snart din pakke er blevet afsendt. " +
+		"Hvis du har glemt din adgangskode, kan du til enhver tid nulstille den på login-siden. " +
+		"I dag er vejret godt, så mine venner og jeg går på torvet og derefter til stranden. " +
+		"Vores team arbejder hver dag på at gøre tjenesten hurtigere, sikrere og nemmere at bruge. Hvor ligger stationen? " +
+		"I morgen skal jeg ringe til min bror, fordi vi ikke har set hinanden længe, og børnene savner ham.",
+	"nb": "Alle mennesker er født frie og med samme menneskeverd og menneskerettigheter. De er utstyrt med fornuft og samvittighet og bør handle mot hverandre i brorskapets ånd. " +
+		"Jeg vet ikke hva vi skal gjøre med dette problemet, men vi må finne en løsning for alle som er her. " +
+		"Klikk på knappen nedenfor for å fortsette, og gi oss beskjed hvis du har spørsmål om de nye funksjonene. " +
+		"Det er første gang de har kunnet dele arbeidet sitt med resten av verden, noe som ikke hadde vært mulig uten din hjelp. " +
+		"Takk for bestillingen din. Vi sender deg en e-post så snart pakken din er sendt. " +
+		"Hvis du har glemt passordet ditt, kan du når som helst tilbakestille det på innloggingssiden. " +
+		"I dag er været fint, så vennene mine og jeg går på torget og deretter til stranden. " +
+		"Teamet vårt jobber hver dag for å gjøre tjenesten raskere, tryggere og enklere å bruke. Hvor ligger stasjonen? " +
+		"I morgen må jeg ringe broren min, fordi vi ikke har sett hverandre på lenge og barna savner ham.",
+	"fi": "Kaikki ihmiset syntyvät vapaina ja tasavertaisina arvoltaan ja oikeuksiltaan. Heille on annettu järki ja omatunto, ja heidän on toimittava toisiaan kohtaan veljeyden hengessä. " +
+		"En tiedä, mitä teemme tämän ongelman kanssa, mutta meidän täytyy löytää ratkaisu kaikille, jotka ovat täällä. " +
+		"Napsauta alla olevaa painiketta jatkaaksesi ja kerro meille, jos sinulla on kysyttävää uusista ominaisuuksista. " +
+		"Tämä on ensimmäinen kerta, kun he ovat voineet jakaa työnsä muun maailman kanssa, mikä ei olisi ollut mahdollista ilman apuasi. " +
+		"Kiitos tilauksestasi. Lähetämme sinulle sähköpostia heti, kun pakettisi on lähetetty. " +
+		"Jos olet unohtanut salasanasi, voit palauttaa sen milloin tahansa kirjautumissivulla. Tänään on kaunis sää, joten menen ystävieni kanssa torille ja sen jälkeen rannalle. " +
+		"Tiimimme tekee töitä joka päivä, jotta palvelu olisi nopeampi, turvallisempi ja helpompi käyttää. Missä asema on? " +
+		"Huomenna minun täytyy soittaa veljelleni, koska emme ole nähneet pitkään aikaan ja lapset kaipaavat häntä.",
+	"pl": "Wszyscy ludzie rodzą się wolni i równi pod względem swej godności i swych praw. Są oni obdarzeni rozumem i sumieniem i powinni postępować wobec innych w duchu braterstwa. " +
+		"Nie wiem, co zrobimy z tym problemem, ale musimy znaleźć rozwiązanie dla wszystkich, którzy tu są. " +
+		"Kliknij przycisk poniżej, aby kontynuować, i daj nam znać, jeśli masz pytania dotyczące nowych funkcji. " +
+		"To pierwszy raz, kiedy mogli podzielić się swoją pracą z resztą świata, co nie byłoby możliwe bez twojej pomocy. " +
+		"Dziękujemy za zamówienie. Wyślemy Ci wiadomość e-mail, gdy tylko Twoja paczka zostanie wysłana. " +
+		"Jeśli zapomniałeś hasła, możesz je w każdej chwili zresetować na stronie logowania. Dziś jest ładna pogoda, więc razem z przyjaciółmi idziemy na targ, a potem na plażę. " +
+		"Nasz zespół codziennie pracuje nad tym, aby usługa była szybsza, bezpieczniejsza i łatwiejsza w użyciu. Gdzie jest dworzec? " +
+		"Jutro muszę zadzwonić do brata, bo dawno się nie widzieliśmy, a dzieci bardzo za nim tęsknią.",
+	"cs": "Všichni lidé rodí se svobodní a sobě rovní co do důstojnosti a práv. Jsou nadáni rozumem a svědomím a mají spolu jednat v duchu bratrství. " +
+		"Nevím, co uděláme s tímto problémem, ale musíme najít řešení pro všechny, kteří jsou tady. " +
+		"Klikněte na tlačítko níže pro pokračování a dejte nám vědět, pokud máte nějaké otázky ohledně nových funkcí. " +
+		"Je to poprvé, co mohli sdílet svou práci se zbytkem světa, což by bez vaší pomoci nebylo možné. " +
+		"Děkujeme za vaši objednávku. Jakmile bude váš balík odeslán, pošleme vám e-mail. Pokud jste zapomněli heslo, můžete si ho kdykoli obnovit na přihlašovací stránce. " +
+		"Dnes je hezké počasí, takže s přáteli jdeme na trh a potom na pláž. Náš tým každý den pracuje na tom, aby byla služba rychlejší, bezpečnější a snadněji použitelná. " +
+		"Kde je nádraží? Zítra musím zavolat bratrovi, protože jsme se dlouho neviděli a děti se mu stýská.",
+	"ro": "Toate ființele umane se nasc libere și egale în demnitate și în drepturi. Ele sunt înzestrate cu rațiune și conștiință și trebuie să se comporte unele față de altele în spiritul fraternității. " +
+		"Nu știu ce vom face cu această problemă, dar trebuie să găsim o soluție pentru toți cei care sunt aici. " +
+		"Vă rugăm să faceți clic pe butonul de mai jos pentru a continua și să ne anunțați dacă aveți întrebări despre noile funcții. " +
+		"Este prima dată când au putut să își împărtășească munca cu restul lumii, ceea ce nu ar fi fost posibil fără ajutorul dumneavoastră. " +
+		"Vă mulțumim pentru comandă. Vă vom trimite un e-mail imediat ce coletul dumneavoastră va fi expediat. " +
+		"Dacă ați uitat parola, o puteți reseta oricând pe pagina de autentificare. Astăzi vremea este frumoasă, așa că eu și prietenii mei mergem la piață și apoi la plajă. " +
+		"Echipa noastră lucrează în fiecare zi pentru ca serviciul să fie mai rapid, mai sigur și mai ușor de folosit. Unde este gara? " +
+		"Mâine trebuie să îl sun pe fratele meu, pentru că nu ne-am văzut de mult timp și copiilor le este dor de el.",
+	"hu": "Minden emberi lény szabadon születik és egyenlő méltósága és joga van. Az emberek, ésszel és lelkiismerettel bírván, egymással szemben testvéri szellemben kell hogy viseltessenek. " +
+		"Nem tudom, mit fogunk kezdeni ezzel a problémával, de meg kell találnunk a megoldást mindenki számára, aki itt van. " +
+		"Kattintson az alábbi gombra a folytatáshoz, és tudassa velünk, ha kérdése van az új funkciókkal kapcsolatban. " +
+		"Ez az első alkalom, hogy meg tudták osztani a munkájukat a világ többi részével, ami nem lett volna lehetséges az Ön segítsége nélkül. " +
+		"Köszönjük a rendelését. E-mailt küldünk, amint a csomagját feladtuk. Ha elfelejtette a jelszavát, a bejelentkezési oldalon bármikor visszaállíthatja. " +
+		"Ma szép idő van, ezért a barátaimmal a piacra megyünk, utána pedig a strandra. " +
+		"Csapatunk minden nap azon dolgozik, hogy a szolgáltatás gyorsabb, biztonságosabb és könnyebben használható legyen. Hol van az állomás? " +
+		"Holnap fel kell hívnom a bátyámat, mert régóta nem láttuk egymást, és a gyerekek nagyon hiányolják.",
+	"tr": "Bütün insanlar hür, haysiyet ve haklar bakımından eşit doğarlar. Akıl ve vicdana sahiptirler ve birbirlerine karşı kardeşlik zihniyeti ile hareket etmelidirler. " +
+		"Bu sorunla ne yapacağımızı bilmiyorum, ama burada olan herkes için bir çözüm bulmamız gerekiyor. " +
+		"Devam etmek için lütfen aşağıdaki düğmeye tıklayın ve yeni özellikler hakkında sorularınız varsa bize bildirin. " +
+		"Bu, çalışmalarını dünyanın geri kalanıyla paylaşabildikleri ilk sefer ve sizin yardımınız olmadan bu mümkün olmazdı. " +
+		"Siparişiniz için teşekkür ederiz. Paketiniz kargoya verilir verilmez size bir e-posta göndereceğiz. " +
+		"Şifrenizi unuttuysanız, giriş sayfasından istediğiniz zaman sıfırlayabilirsiniz. Bugün hava güzel, bu yüzden arkadaşlarımla pazara, sonra da plaja gidiyoruz. " +
+		"Ekibimiz hizmeti daha hızlı, daha güvenli ve kullanımı daha kolay hale getirmek için her gün çalışıyor. İstasyon nerede? " +
+		"Yarın kardeşimi aramam gerekiyor, çünkü uzun zamandır görüşmedik ve çocuklar onu çok özlüyor.",
+	"id": "Semua orang dilahirkan merdeka dan mempunyai martabat dan hak-hak yang sama. Mereka dikaruniai akal dan hati nurani dan hendaknya bergaul satu sama lain dalam semangat persaudaraan. " +
+		"Saya tidak tahu apa yang akan kita lakukan dengan masalah ini, tetapi kita harus menemukan solusi untuk semua orang yang ada di sini. " +
+		"Silakan klik tombol di bawah ini untuk melanjutkan dan beri tahu kami jika Anda memiliki pertanyaan tentang fitur-fitur baru. " +
+		"Ini adalah pertama kalinya mereka dapat membagikan karya mereka dengan seluruh dunia, yang tidak akan mungkin terjadi tanpa bantuan Anda. " +
+		"Terima kasih atas pesanan Anda. Kami akan mengirimkan email kepada Anda segera setelah paket Anda dikirim. " +
+		"Jika Anda lupa kata sandi, Anda dapat mengaturnya ulang kapan saja di halaman masuk. Hari ini cuacanya cerah, jadi saya dan teman-teman pergi ke pasar lalu ke pantai. " +
+		"Tim kami bekerja setiap hari untuk membuat layanan ini lebih cepat, lebih aman, dan lebih mudah digunakan. Di mana stasiunnya? " +
+		"Besok saya harus menelepon kakak saya, karena kami sudah lama tidak bertemu dan anak-anak sangat merindukannya.",
+	"vi": "Tất cả mọi người sinh ra đều được tự do và bình đẳng về nhân phẩm và quyền lợi. Mọi con người đều được tạo hóa ban cho lý trí và lương tâm và cần phải đối xử với nhau trong tình anh em. " +
+		"Tôi không biết chúng ta sẽ làm gì với vấn đề này, nhưng chúng ta phải tìm ra giải pháp cho tất cả mọi người ở đây. " +
+		"Vui lòng nhấp vào nút bên dưới để tiếp tục và cho chúng tôi biết nếu bạn có bất kỳ câu hỏi nào về các tính năng mới. " +
+		"Đây là lần đầu tiên họ có thể chia sẻ công việc của mình với phần còn lại của thế giới, điều này sẽ không thể xảy ra nếu không có sự giúp đỡ của bạn. " +
+		"Cảm ơn bạn đã đặt hàng. Chúng tôi sẽ gửi email cho bạn ngay khi gói hàng của bạn được gửi đi. " +
+		"Nếu bạn quên mật khẩu, bạn có thể đặt lại bất cứ lúc nào trên trang đăng nhập. Hôm nay trời đẹp, nên tôi và các bạn đi chợ rồi ra biển. " +
+		"Đội ngũ của chúng tôi làm việc mỗi ngày để dịch vụ nhanh hơn, an toàn hơn và dễ sử dụng hơn. Nhà ga ở đâu? " +
+		"Ngày mai tôi phải gọi điện cho anh trai, vì chúng tôi đã lâu không gặp nhau và bọn trẻ rất nhớ anh ấy.",
+	"ru": "Все люди рождаются свободными и равными в своем достоинстве и правах. Они наделены разумом и совестью и должны поступать в отношении друг друга в духе братства. " +
+		"Я не знаю, что мы будем делать с этой проблемой, но мы должны найти решение для всех, кто здесь находится. " +
+		"Пожалуйста, нажмите кнопку ниже, чтобы продолжить, и сообщите нам, если у вас есть вопросы о новых функциях. " +
+		"Это первый раз, когда они смогли поделиться своей работой с остальным миром, что было бы невозможно без вашей помощи. " +
+		"Спасибо за ваш заказ. Мы отправим вам письмо, как только ваша посылка будет отправлена. Если вы забыли пароль, вы можете сбросить его в любое время на странице входа. " +
+		"Сегодня хорошая погода, поэтому мы с друзьями идём на рынок, а потом на пляж. Наша команда каждый день работает над тем, чтобы сервис был быстрее, безопаснее и удобнее. " +
+		"Где находится вокзал? Завтра мне нужно позвонить брату, потому что мы давно не виделись, и дети очень скучают по нему. " +
+		"Её ответ был простым: это ещё не всё, вы увидите сами. Объявление о новых правилах появится на этой неделе. " +
+		"Этот товар есть в наличии. Эта страница сейчас недоступна, попробуйте позже. Что вы хотите сделать? Я живу в этом городе уже десять лет и очень его люблю.",
+	"uk": "Всі люди народжуються вільними і рівними у своїй гідності та правах. Вони наділені розумом і совістю і повинні діяти у відношенні один до одного в дусі братерства. " +
+		"Я не знаю, що ми будемо робити з цією проблемою, але ми повинні знайти рішення для всіх, хто тут є. " +
+		"Будь ласка, натисніть кнопку нижче, щоб продовжити, і повідомте нам, якщо у вас є питання щодо нових функцій. " +
+		"Це перший раз, коли вони змогли поділитися своєю роботою з рештою світу, що було б неможливо без вашої допомоги. " +
+		"Дякуємо за ваше замовлення. Ми надішлемо вам листа, щойно ваша посилка буде відправлена. Якщо ви забули пароль, ви можете скинути його будь-коли на сторінці входу. " +
+		"Сьогодні гарна погода, тому ми з друзями йдемо на ринок, а потім на пляж. Наша команда щодня працює над тим, щоб сервіс був швидшим, безпечнішим і зручнішим. " +
+		"Де знаходиться вокзал? Завтра мені потрібно зателефонувати братові, бо ми давно не бачилися, і діти дуже сумують за ним. " +
+		"Її відповідь була простою: це ще не все, ви побачите самі. Оголошення про нові правила з'явиться цього тижня. " +
+		"Цей товар є в наявності. Ця сторінка зараз недоступна, спробуйте пізніше. Що ви хочете зробити? Я живу в цьому місті вже десять років і дуже його люблю.",
+	"bg": "Всички хора се раждат свободни и равни по достойнство и права. Те са надарени с разум и съвест и следва да се отнасят помежду си в дух на братство. " +
+		"Не знам какво ще правим с този проблем, но трябва да намерим решение за всички, които са тук. " +
+		"Моля, натиснете бутона по-долу, за да продължите, и ни уведомете, ако имате въпроси относно новите функции. " +
+		"Това е първият път, когато те успяха да споделят работата си с останалата част от света, което нямаше да бъде възможно без вашата помощ. " +
+		"Благодарим ви за поръчката. Ще ви изпратим имейл веднага щом пратката ви бъде изпратена. " +
+		"Ако сте забравили паролата си, можете да я възстановите по всяко време от страницата за вход. " +
+		"Днес времето е хубаво, затова с приятелите ми отиваме на пазара, а после на плажа. " +
+		"Нашият екип работи всеки ден, за да направи услугата по-бърза, по-сигурна и по-лесна за използване. Къде се намира гарата? " +
+		"Утре трябва да се обадя на брат си, защото отдавна не сме се виждали и децата много му липсват. Нейният отговор беше прост: това още не е всичко, ще видите сами. " +
+		"Обявата за новите правила ще се появи тази седмица. " +
+		"Този продукт е наличен. Тази страница в момента не е достъпна, опитайте по-късно. Какво искате да направите? Живея в този град от десет години и много го обичам.",
+}
+
+// simplifiedOnly 常见的简体特有字
+const simplifiedOnly = "这们说为会时国对学经过发后么个见还进长开问关东车书电门现间实让认语话请两应样点业买卖网钱听写边运处务节总体龙马鸟鱼风飞页题头种报场将气万与无于产从议论设计记许诗读谁难热爱机动类"
+
+// traditionalOnly 常见的繁体特有字
+const traditionalOnly = "這們說為會時國對學經過發後麼個見還進長開問關東車書電門現間實讓認語話請兩應樣點業買賣網錢聽寫邊運處務節總體龍馬鳥魚風飛頁題頭種報場將氣萬與無於產從議論設計記許詩讀誰難熱愛機動類"
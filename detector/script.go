@@ -0,0 +1,100 @@
+package detector
+
+import "unicode"
+
+type script int
+
+const (
+	scriptOther script = iota
+	scriptLatin
+	scriptCyrillic
+	scriptHan
+	scriptKana
+	scriptHangul
+	scriptArabic
+	scriptHebrew
+	scriptGreek
+	scriptThai
+	scriptDevanagari
+	scriptBengali
+	scriptTamil
+	scriptGeorgian
+	scriptArmenian
+)
+
+// scriptLanguages 由文字系统即可确定语言的映射
+var scriptLanguages = map[script]string{
+	scriptHangul:     "ko",
+	scriptHebrew:     "he",
+	scriptGreek:      "el",
+	scriptThai:       "th",
+	scriptDevanagari: "hi",
+	scriptBengali:    "bn",
+	scriptTamil:      "ta",
+	scriptGeorgian:   "ka",
+	scriptArmenian:   "hy",
+}
+
+var scriptTables = []struct {
+	script script
+	table  *unicode.RangeTable
+}{
+	{scriptLatin, unicode.Latin},
+	{scriptCyrillic, unicode.Cyrillic},
+	{scriptHan, unicode.Han},
+	{scriptKana, unicode.Hiragana},
+	{scriptKana, unicode.Katakana},
+	{scriptHangul, unicode.Hangul},
+	{scriptArabic, unicode.Arabic},
+	{scriptHebrew, unicode.Hebrew},
+	{scriptGreek, unicode.Greek},
+	{scriptThai, unicode.Thai},
+	{scriptDevanagari, unicode.Devanagari},
+	{scriptBengali, unicode.Bengali},
+	{scriptTamil, unicode.Tamil},
+	{scriptGeorgian, unicode.Georgian},
+	{scriptArmenian, unicode.Armenian},
+}
+
+// scriptCounts 文本中各文字系统的字母数
+type scriptCounts struct {
+	letters  int
+	byScript map[script]int
+}
+
+func countScripts(text string) scriptCounts {
+	counts := scriptCounts{byScript: make(map[script]int)}
+	for _, r := range text {
+		if !isLetter(r) {
+			continue
+		}
+		counts.letters++
+		counts.byScript[scriptOf(r)]++
+	}
+	return counts
+}
+
+// dominant 返回字母数最多的文字系统；汉字与假名混排时假名单独统计，由调用方处理
+func (c scriptCounts) dominant() (script, int) {
+	best, n := scriptOther, 0
+	for s, count := range c.byScript {
+		if s != scriptOther && (count > n || (count == n && s < best)) {
+			best, n = s, count
+		}
+	}
+	return best, n
+}
+
+// cjk 判断文本是否以汉字、假名或谚文为主
+func (c scriptCounts) cjk() bool {
+	return (c.byScript[scriptHan]+c.byScript[scriptKana]+c.byScript[scriptHangul])*2 > c.letters
+}
+
+func scriptOf(r rune) script {
+	for _, st := range scriptTables {
+		if unicode.Is(st.table, r) {
+			return st.script
+		}
+	}
+	return scriptOther
+}
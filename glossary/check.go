@@ -0,0 +1,64 @@
+package glossary
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 术语校验方式
+const (
+	EnforcementOff    = "off"    // 只注入提示词，不校验译文
+	EnforcementFlag   = "flag"   // 校验译文并记录违规
+	EnforcementRepair = "repair" // 校验译文，违规时指明错误重新翻译一次
+)
+
+// Violation 译文中未按术语表翻译的术语
+type Violation struct {
+	Term Term
+}
+
+// String 返回便于记录的违规描述，例如 "Workspace => Workspace"
+func (v Violation) String() string {
+	return v.Term.Source + " => " + v.Term.Target
+}
+
+// Instructions 生成注入提示词的术语表说明
+func Instructions(terms []Term) string {
+	if len(terms) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Use the following glossary. Translate each term exactly as given, keeping the given spelling and case:\n")
+	for _, term := range terms {
+		fmt.Fprintf(&b, "- %q => %q\n", term.Source, term.Target)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// RepairInstructions 生成重新翻译时指明违规术语的说明
+func RepairInstructions(violations []Violation) string {
+	if len(violations) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("A previous translation did not follow the glossary. The following terms MUST appear in the translation exactly as given:\n")
+	for _, v := range violations {
+		fmt.Fprintf(&b, "- %q must be translated as %q\n", v.Term.Source, v.Term.Target)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Check 检查译文是否使用了术语表中的译法，返回未使用的术语（忽略大小写）
+func Check(translation string, terms []Term) []Violation {
+	lowerTranslation := strings.ToLower(translation)
+
+	var violations []Violation
+	for _, term := range terms {
+		if !strings.Contains(lowerTranslation, strings.ToLower(term.Target)) {
+			violations = append(violations, Violation{Term: term})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,306 @@
+// glossary/glossary.go
+package glossary
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"transbridge/internal/utils"
+)
+
+// Wildcard 表示任意语言，例如 "*->*" 的术语表适用于所有语言对
+const Wildcard = "*"
+
+// Term 术语条目
+type Term struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Note   string `json:"note,omitempty"` // 可选的备注，不参与提示词
+}
+
+// PairInfo 单个语言对术语表的概况
+type PairInfo struct {
+	SourceLang string    `json:"source_lang"`
+	TargetLang string    `json:"target_lang"`
+	Terms      int       `json:"terms"`
+	Version    string    `json:"version"` // 术语内容的指纹，内容变化时随之变化
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// list 单个语言对的术语表，按小写原文索引
+type list struct {
+	terms     map[string]Term
+	updatedAt time.Time
+}
+
+// Store 按语言对存放术语表，可并发读写
+type Store struct {
+	mu    sync.RWMutex
+	lists map[string]*list
+}
+
+// NewStore 创建术语表存储
+func NewStore() *Store {
+	return &Store{
+		lists: make(map[string]*list),
+	}
+}
+
+// Upsert 新增或更新指定语言对的术语，返回写入的条目数
+func (s *Store) Upsert(sourceLang, targetLang string, terms []Term) (int, error) {
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lists[key]
+	if !ok {
+		l = &list{terms: make(map[string]Term)}
+		s.lists[key] = l
+	}
+
+	count := 0
+	for _, term := range terms {
+		term.Source = strings.TrimSpace(term.Source)
+		term.Target = strings.TrimSpace(term.Target)
+		if term.Source == "" || term.Target == "" {
+			continue
+		}
+		l.terms[strings.ToLower(term.Source)] = term
+		count++
+	}
+	l.updatedAt = time.Now()
+	return count, nil
+}
+
+// Delete 删除指定语言对中的术语；sources 为空时删除整个语言对，返回删除的条目数
+func (s *Store) Delete(sourceLang, targetLang string, sources []string) (int, error) {
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lists[key]
+	if !ok {
+		return 0, nil
+	}
+
+	if len(sources) == 0 {
+		delete(s.lists, key)
+		return len(l.terms), nil
+	}
+
+	count := 0
+	for _, source := range sources {
+		k := strings.ToLower(strings.TrimSpace(source))
+		if _, ok := l.terms[k]; ok {
+			delete(l.terms, k)
+			count++
+		}
+	}
+	if len(l.terms) == 0 {
+		delete(s.lists, key)
+	} else {
+		l.updatedAt = time.Now()
+	}
+	return count, nil
+}
+
+// Terms 返回指定语言对（不含通配与基础语言的术语表）的全部术语，按原文排序
+func (s *Store) Terms(sourceLang, targetLang string) ([]Term, error) {
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.lists[key]
+	if !ok {
+		return []Term{}, nil
+	}
+	return sortedTerms(l.terms), nil
+}
+
+// Pairs 返回所有语言对术语表的概况
+func (s *Store) Pairs() []PairInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pairs := make([]PairInfo, 0, len(s.lists))
+	for key, l := range s.lists {
+		sourceLang, targetLang, _ := strings.Cut(key, "->")
+		pairs = append(pairs, PairInfo{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			Terms:      len(l.terms),
+			Version:    Fingerprint(sortedTerms(l.terms)),
+			UpdatedAt:  l.updatedAt,
+		})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].SourceLang != pairs[j].SourceLang {
+			return pairs[i].SourceLang < pairs[j].SourceLang
+		}
+		return pairs[i].TargetLang < pairs[j].TargetLang
+	})
+	return pairs
+}
+
+// Match 返回适用于该语言对、且原文出现在 text 中的术语
+// 依次合并 "*"、基础语言、完整语言代码的术语表，更具体的语言对覆盖通用的同名术语；
+// 以空格分词的文字按整词匹配（"art" 不匹配 "start"），中日韩等不分词的文字按子串匹配；
+// 结果按原文长度降序排列，长术语优先
+func (s *Store) Match(sourceLang, targetLang, text string) []Term {
+	if s == nil || text == "" {
+		return nil
+	}
+
+	sources := langCandidates(sourceLang)
+	targets := langCandidates(targetLang)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	merged := make(map[string]Term)
+	for _, src := range sources {
+		for _, tgt := range targets {
+			l, ok := s.lists[src+"->"+tgt]
+			if !ok {
+				continue
+			}
+			for k, term := range l.terms {
+				merged[k] = term
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	lowerText := strings.ToLower(text)
+	var matched []Term
+	for k, term := range merged {
+		if containsTerm(lowerText, k) {
+			matched = append(matched, term)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if len(matched[i].Source) != len(matched[j].Source) {
+			return len(matched[i].Source) > len(matched[j].Source)
+		}
+		return matched[i].Source < matched[j].Source
+	})
+	return matched
+}
+
+// containsTerm 判断 text 中是否出现术语 term（均为小写）
+// 术语首尾是字母或数字时，相邻字符不能是同样以空格分词的文字中的字母或数字；首尾或相邻字符属于不分词的文字时不限制
+func containsTerm(text, term string) bool {
+	first, _ := utf8.DecodeRuneInString(term)
+	last, _ := utf8.DecodeLastRuneInString(term)
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !joins(before, first)) && (end == len(text) || !joins(last, after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// joins 判断相邻的两个字符是否属于同一个词
+func joins(a, b rune) bool {
+	return wordRune(a) && wordRune(b)
+}
+
+// wordRune 判断字符是否为以空格分词的文字中的字母或数字
+func wordRune(r rune) bool {
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return false
+	}
+	return !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
+
+// Fingerprint 返回术语列表的指纹，术语相同则指纹相同（与顺序无关）
+func Fingerprint(terms []Term) string {
+	lines := make([]string, len(terms))
+	for i, term := range terms {
+		lines[i] = term.Source + "\t" + term.Target
+	}
+	sort.Strings(lines)
+
+	hasher := md5.New()
+	hasher.Write([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(hasher.Sum(nil))[:12]
+}
+
+// pairKey 规范化语言对，语言为 "*" 时表示任意语言
+func pairKey(sourceLang, targetLang string) (string, error) {
+	src, err := normalizeLang(sourceLang)
+	if err != nil {
+		return "", err
+	}
+	tgt, err := normalizeLang(targetLang)
+	if err != nil {
+		return "", err
+	}
+	return src + "->" + tgt, nil
+}
+
+func normalizeLang(lang string) (string, error) {
+	lang = strings.TrimSpace(lang)
+	if lang == Wildcard {
+		return lang, nil
+	}
+	code := utils.NormalizeLanguageCode(lang)
+	if code == "" || !utils.IsValidLanguageCode(utils.BaseLanguage(code)) {
+		return "", fmt.Errorf("invalid language code %q", lang)
+	}
+	return code, nil
+}
+
+// langCandidates 返回从通用到具体的候选语言键，例如 "zh-TW" -> ["*", "zh", "zh-TW"]
+func langCandidates(lang string) []string {
+	candidates := []string{Wildcard}
+	code := utils.NormalizeLanguageCode(lang)
+	if code == "" {
+		return candidates
+	}
+	if base := utils.BaseLanguage(code); base != code {
+		candidates = append(candidates, base)
+	}
+	return append(candidates, code)
+}
+
+func sortedTerms(terms map[string]Term) []Term {
+	result := make([]Term, 0, len(terms))
+	for _, term := range terms {
+		result = append(result, term)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Source) < strings.ToLower(result[j].Source)
+	})
+	return result
+}
@@ -0,0 +1,69 @@
+package glossary
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadFile 从 CSV/TSV 文件加载术语到指定语言对，返回加载的条目数
+// 每行格式为 "原文,译文[,备注]"，扩展名为 .tsv 时使用制表符分隔；首行为 source,target 表头时跳过
+func (s *Store) LoadFile(path, sourceLang, targetLang string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open glossary file: %w", err)
+	}
+	defer f.Close()
+
+	comma := ','
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		comma = '\t'
+	}
+
+	terms, err := Parse(f, comma)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse glossary file %s: %w", path, err)
+	}
+	return s.Upsert(sourceLang, targetLang, terms)
+}
+
+// Parse 解析 CSV/TSV 格式的术语列表，comma 为分隔符
+func Parse(r io.Reader, comma rune) ([]Term, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if comma == '\t' {
+		// TSV 中的引号按普通字符处理
+		reader.LazyQuotes = true
+	}
+
+	var terms []Term
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected at least 2 columns, got %d", line, len(record))
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "source") &&
+			strings.EqualFold(strings.TrimSpace(record[1]), "target") {
+			continue
+		}
+
+		term := Term{Source: record[0], Target: record[1]}
+		if len(record) > 2 {
+			term.Note = strings.TrimSpace(record[2])
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PromptTemplate 提示词模板，System 为可选的系统提示词，Template 为用户消息模板
+// 两者都支持 {{input}}、{{source_lang}}、{{target_lang}} 占位符，{{input}} 只要求出现在 Template 中
+type PromptTemplate struct {
+	Name     string // 命中的语言对规则，例如 "*->ja"；默认模板为空
+	System   string
+	Template string
+}
+
+// Fingerprint 返回模板内容的短哈希，用于区分不同模板产生的缓存
+func (t PromptTemplate) Fingerprint() string {
+	hasher := md5.New()
+	hasher.Write([]byte(t.System + "\x00" + t.Template))
+	return hex.EncodeToString(hasher.Sum(nil))[:12]
+}
+
+// Render 将占位符替换为实际值，返回系统提示词与用户消息
+func (t PromptTemplate) Render(input, sourceLang, targetLang string) (system, user string, err error) {
+	user, err = ApplyPromptTemplate(t.Template, input, sourceLang, targetLang)
+	if err != nil {
+		return "", "", err
+	}
+	if t.System != "" {
+		system = strings.NewReplacer(
+			"{{input}}", input,
+			"{{source_lang}}", sourceLang,
+			"{{target_lang}}", targetLang,
+		).Replace(t.System)
+	}
+	return system, user, nil
+}
+
+// PromptSet 按语言对选择提示词模板，未命中任何规则时使用默认模板
+type PromptSet struct {
+	defaultTemplate PromptTemplate
+	rules           []promptRule
+}
+
+// promptRule 语言对规则，source/target 为 "*" 表示任意语言
+type promptRule struct {
+	source   string
+	target   string
+	template PromptTemplate
+}
+
+// NewPromptSet 创建提示词模板集合
+func NewPromptSet(defaultTemplate PromptTemplate) *PromptSet {
+	defaultTemplate.Name = ""
+	return &PromptSet{defaultTemplate: defaultTemplate}
+}
+
+// AddRule 添加语言对规则，pair 形如 "zh->en"、"*->ja"、"en->*"
+// 规则中未填写的 System/Template 沿用默认模板
+func (p *PromptSet) AddRule(pair string, template PromptTemplate) error {
+	parts := strings.Split(pair, "->")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid prompt pair %q: expected format source->target", pair)
+	}
+
+	source, err := normalizePairSide(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid prompt pair %q: %w", pair, err)
+	}
+	target, err := normalizePairSide(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid prompt pair %q: %w", pair, err)
+	}
+
+	if template.Template == "" {
+		template.Template = p.defaultTemplate.Template
+	}
+	if template.System == "" {
+		template.System = p.defaultTemplate.System
+	}
+	if !strings.Contains(template.Template, "{{input}}") {
+		return fmt.Errorf("invalid prompt pair %q: %w", pair, ErrInvalidPromptTemplate)
+	}
+	template.Name = source + "->" + target
+
+	p.rules = append(p.rules, promptRule{source: source, target: target, template: template})
+	return nil
+}
+
+// Select 返回与语言对最匹配的模板
+// 每一侧的匹配程度：完整语言代码相同 > 基础语言相同（规则 "zh" 匹配 "zh-TW"）> 通配符；
+// 总分相同时目标语言更具体的规则优先，仍相同时按配置顺序取第一条
+func (p *PromptSet) Select(sourceLang, targetLang string) PromptTemplate {
+	if p == nil {
+		return PromptTemplate{}
+	}
+
+	sourceLang = NormalizeLanguageCode(sourceLang)
+	targetLang = NormalizeLanguageCode(targetLang)
+
+	best := p.defaultTemplate
+	bestScore, bestTargetScore := -1, -1
+	for _, rule := range p.rules {
+		sourceScore := matchPairSide(rule.source, sourceLang)
+		targetScore := matchPairSide(rule.target, targetLang)
+		if sourceScore < 0 || targetScore < 0 {
+			continue
+		}
+
+		score := sourceScore + targetScore
+		if score > bestScore || (score == bestScore && targetScore > bestTargetScore) {
+			best = rule.template
+			bestScore, bestTargetScore = score, targetScore
+		}
+	}
+	return best
+}
+
+// normalizePairSide 规范化规则中的一侧语言代码
+func normalizePairSide(side string) (string, error) {
+	side = strings.TrimSpace(side)
+	if side == "*" {
+		return side, nil
+	}
+	if side == "" {
+		return "", fmt.Errorf("language is empty")
+	}
+	code := NormalizeLanguageCode(side)
+	if !IsValidLanguageCode(BaseLanguage(code)) {
+		return "", fmt.Errorf("unknown language %q", side)
+	}
+	return code, nil
+}
+
+// matchPairSide 返回规则一侧与实际语言的匹配程度，不匹配时返回 -1
+func matchPairSide(ruleLang, lang string) int {
+	switch {
+	case ruleLang == "*":
+		return 0
+	case lang == "":
+		return -1
+	case ruleLang == BaseLanguage(ruleLang):
+		// 规则只写了基础语言，匹配该语言的所有地区与书写形式
+		if BaseLanguage(lang) == ruleLang {
+			return 1
+		}
+	case lang != BaseLanguage(lang) && SameLanguage(ruleLang, lang):
+		// 规则写了地区或书写形式时，只匹配同样写明了地区或书写形式的语言
+		return 2
+	}
+	return -1
+}
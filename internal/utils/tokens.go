@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// EstimateTokens 粗略估算文本的 token 数，用于长文本分块
+// 汉字、假名、谚文按每字 1 个 token 计算，其它文字按每 4 个字节 1 个 token 计算，结果略偏保守
+func EstimateTokens(text string) int {
+	ideographs, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			ideographs++
+			continue
+		}
+		other += utf8.RuneLen(r)
+	}
+	return ideographs + (other+3)/4
+}
@@ -13,21 +13,59 @@ import (
 	"golang.org/x/text/language/display"
 )
 
-// GenerateCacheKey 生成缓存键
-func GenerateCacheKey(text, sourceLang, targetLang string) string {
+// ErrInvalidPromptTemplate 提示词模板缺少 {{input}} 占位符
+var ErrInvalidPromptTemplate = errors.New("Invalid prompt template: must contain {{input}}")
+
+// CacheKeyPrefix 缓存键前缀；v2 起缓存键带版本号，例如 transbridge:v2:en:zh:<md5>
+const CacheKeyPrefix = "transbridge:"
+
+// GenerateCacheKey 生成 v1 缓存键 transbridge:<md5>
+// options 为影响译文的附加选项（如语气、标签处理方式），为空时与旧版缓存键保持一致
+func GenerateCacheKey(text, sourceLang, targetLang string, options ...string) string {
 	// 组合键的各个部分
-	key := strings.Join([]string{
-		sourceLang,
-		targetLang,
-		text,
-	}, ":")
+	parts := []string{sourceLang, targetLang}
+	for _, opt := range options {
+		if opt != "" {
+			parts = append(parts, opt)
+		}
+	}
+	key := strings.Join(append(parts, text), ":")
 
 	// 计算MD5哈希
 	hasher := md5.New()
 	hasher.Write([]byte(key))
 	md5string := hex.EncodeToString(hasher.Sum(nil))
 
-	return "transbridge:" + md5string
+	return CacheKeyPrefix + md5string
+}
+
+// DetectCacheKey 生成模型语言检测结果的缓存键 transbridge:detect:<md5>，与译文缓存键分开，缓存管理接口按条件删除时不会当作译文
+func DetectCacheKey(text string) string {
+	hasher := md5.New()
+	hasher.Write([]byte(text))
+	return CacheKeyPrefix + "detect:" + hex.EncodeToString(hasher.Sum(nil))
+}
+
+// VersionedCacheKey 生成 v2 缓存键 transbridge:v2:<源语言>:<目标语言>:<md5>
+// 语言对以明文保留在键中，便于按语言对查找；dimensions 为参与计算的各维度指纹（形如 "prompt=..."），与原文一起计算 MD5
+func VersionedCacheKey(text, sourceLang, targetLang string, dimensions ...string) string {
+	hasher := md5.New()
+	for _, dim := range dimensions {
+		if dim != "" {
+			hasher.Write([]byte(dim + "\n"))
+		}
+	}
+	hasher.Write([]byte(text))
+	return CacheKeyLangPrefix(sourceLang, targetLang) + hex.EncodeToString(hasher.Sum(nil))
+}
+
+// CacheKeyLangPrefix 返回某个语言对的 v2 缓存键前缀，例如 transbridge:v2:en:zh-CN:；源语言为空时记为 auto
+func CacheKeyLangPrefix(sourceLang, targetLang string) string {
+	src := NormalizeLanguageCode(sourceLang)
+	if src == "" {
+		src = "auto"
+	}
+	return CacheKeyPrefix + "v2:" + src + ":" + NormalizeLanguageCode(targetLang) + ":"
 }
 
 // IsValidLanguageCode 检查语言代码是否有效
@@ -36,6 +74,68 @@ func IsValidLanguageCode(code string) bool {
 	return iso639.ValidCode(strings.ToLower(code))
 }
 
+// SupportedLanguages 兼容接口（DeepL、Google、LibreTranslate 等）对外公布的语言代码
+var SupportedLanguages = []string{
+	"ar", "bg", "cs", "da", "de", "el", "en", "es", "et", "fi",
+	"fr", "he", "hi", "hu", "id", "it", "ja", "ko", "lt", "lv",
+	"ms", "nb", "nl", "pl", "pt", "ro", "ru", "sk", "sl", "sv",
+	"th", "tr", "uk", "vi", "zh",
+}
+
+// NormalizeLanguageCode 将语言代码规范化为 BCP 47 形式
+// 例如: "ZH-HANS" -> "zh-Hans", "EN" -> "en"；空值与 "auto" 返回空字符串，表示需要自动检测
+func NormalizeLanguageCode(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" || strings.EqualFold(code, "auto") {
+		return ""
+	}
+
+	tag, err := language.Parse(code)
+	if err != nil {
+		return strings.ToLower(code)
+	}
+	return tag.String()
+}
+
+// BaseLanguage 返回语言代码的基础语言部分（小写），例如 "zh-TW" -> "zh"
+func BaseLanguage(code string) string {
+	parts := strings.FieldsFunc(code, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.ToLower(parts[0])
+}
+
+// SameLanguage 判断源语言与目标语言是否相同，无需翻译
+// 基础语言与书写形式（可由地区推断）都相同才视为相同，例如 "zh-Hans" 与 "zh-CN" 相同，"zh-CN" 与 "zh-TW" 不同；
+// 双方都明确指定了地区且地区不同时（如 "en-US" 与 "en-GB"、"pt-BR" 与 "pt-PT"）仍视为不同
+func SameLanguage(a, b string) bool {
+	tagA, errA := language.Parse(strings.TrimSpace(a))
+	tagB, errB := language.Parse(strings.TrimSpace(b))
+	if errA != nil || errB != nil {
+		return a != "" && strings.EqualFold(a, b)
+	}
+
+	baseA, _ := tagA.Base()
+	baseB, _ := tagB.Base()
+	if baseA != baseB {
+		return false
+	}
+
+	scriptA, _ := tagA.Script()
+	scriptB, _ := tagB.Script()
+	if scriptA != scriptB {
+		return false
+	}
+
+	regionA, confA := tagA.Region()
+	regionB, confB := tagB.Region()
+	if confA == language.Exact && confB == language.Exact && regionA != regionB {
+		return false
+	}
+	return true
+}
+
 // TruncateText 截断文本到指定长度
 func TruncateText(text string, maxLength int) string {
 	if len(text) <= maxLength {
@@ -81,7 +181,7 @@ func ApplyPromptTemplate(template, input, sourceLang, targetLang string) (string
 	// Validate the template: must contain {{input}} to be meaningful
 	if !strings.Contains(template, "{{input}}") {
 		log.Println("Invalid prompt template: must contain {{input}}")
-		return "", errors.New("Invalid prompt template: must contain {{input}}")
+		return "", ErrInvalidPromptTemplate
 	}
 
 	replacer := strings.NewReplacer(
@@ -106,3 +206,19 @@ func GetLanguageName(langCode string) (string, error) {
 	// 获取 tag 的中文名称
 	return display.Name(tag), nil
 }
+
+// GetLanguageNameIn 以指定语言返回语言名称，例如 ("ja", "zh") -> "日文"
+// displayLang 无法解析时回退为英文名称
+func GetLanguageNameIn(langCode, displayLang string) (string, error) {
+	tag, err := language.Parse(langCode)
+	if err != nil {
+		return langCode, fmt.Errorf("invalid language code: %w", err)
+	}
+
+	displayTag, err := language.Parse(displayLang)
+	if err != nil {
+		displayTag = language.English
+	}
+
+	return display.Tags(displayTag).Name(tag), nil
+}
@@ -0,0 +1,181 @@
+// jobs/callback.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"transbridge/webhook"
+)
+
+// CallbackPayload 任务结束后 POST 到 callback_url 的内容
+type CallbackPayload struct {
+	Event      string           `json:"event"` // job.completed、job.failed 或 job.canceled
+	JobID      string           `json:"job_id"`
+	Type       string           `json:"type"`
+	Status     Status           `json:"status"`
+	Total      int              `json:"total"`
+	Completed  int              `json:"completed"`
+	Error      string           `json:"error,omitempty"`
+	Records    []CallbackRecord `json:"records"` // 与 texts 一一对应，文档类任务只有一条；未完成的文本不包含在内
+	CreatedAt  time.Time        `json:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+// CallbackRecord 单条文本的翻译记录，字段与翻译日志一致
+type CallbackRecord struct {
+	Index      int    `json:"index"`
+	SourceText string `json:"source_text"`
+	TargetText string `json:"target_text"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Model      string `json:"model,omitempty"` // provider/model
+	CacheHit   bool   `json:"cache_hit"`
+
+	ProcessTime        float64  `json:"process_time_ms"`
+	Attempts           int      `json:"attempts,omitempty"`
+	GlossaryViolations []string `json:"glossary_violations,omitempty"`
+	Fuzzy              []string `json:"fuzzy,omitempty"` // localization 任务：标记为待审校的条目
+	Error              string   `json:"error,omitempty"`
+}
+
+// callbackPayload 由已结束的任务构造回调内容
+func callbackPayload(job *Job) CallbackPayload {
+	payload := CallbackPayload{
+		Event:      "job." + string(job.Status),
+		JobID:      job.ID,
+		Type:       job.Request.Type,
+		Status:     job.Status,
+		Total:      job.Total,
+		Completed:  job.Completed,
+		Error:      job.Error,
+		Records:    []CallbackRecord{},
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+
+	for i, result := range job.Results {
+		if result == nil {
+			continue
+		}
+		source := job.Request.Text
+		if job.Request.Type == TypeText {
+			source = job.Request.Texts[i]
+		}
+		sourceLang := result.SourceLang
+		if sourceLang == "" {
+			sourceLang = job.Request.SourceLang
+		}
+		payload.Records = append(payload.Records, CallbackRecord{
+			Index:              i,
+			SourceText:         source,
+			TargetText:         result.Text,
+			SourceLang:         sourceLang,
+			TargetLang:         job.Request.TargetLang,
+			Model:              result.Model,
+			CacheHit:           result.CacheHit,
+			ProcessTime:        result.ProcessTime,
+			Attempts:           result.Attempts,
+			GlossaryViolations: result.GlossaryViolations,
+			Fuzzy:              result.Fuzzy,
+			Error:              result.Error,
+		})
+	}
+	return payload
+}
+
+// notify 任务结束后投递回调；未请求回调或未配置回调客户端时不做处理
+func (m *Manager) notify(job *Job) {
+	if job.Request.CallbackURL == "" || m.opts.Webhook == nil {
+		return
+	}
+	if job.Callback == nil {
+		job.Callback = &Callback{Status: CallbackPending}
+		if err := m.store.Save(context.Background(), job); err != nil {
+			log.Printf("Failed to save job %s: %v", job.ID, err)
+			return
+		}
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		// 其它实例正在投递时跳过；获取租约后重新读取，对方可能已投递完成
+		release, ok := m.hold(job.ID+callbackLease, nil)
+		if !ok {
+			return
+		}
+		defer release()
+
+		// 投递使用存储中的副本，调用方持有的任务可能仍在被读取
+		copied, err := m.store.Get(context.Background(), job.ID)
+		if err != nil {
+			log.Printf("Failed to load job %s for callback: %v", job.ID, err)
+			return
+		}
+		if copied.Callback == nil || copied.Callback.Status != CallbackPending {
+			return
+		}
+		m.deliver(copied)
+	}()
+}
+
+// deliver 签名并投递回调，每次投递后保存投递状态；服务关闭时中断，状态保持 pending，重启后重新投递
+func (m *Manager) deliver(job *Job) {
+	save := func() {
+		if err := m.store.Save(context.Background(), job); err != nil {
+			log.Printf("Failed to save callback status of job %s: %v", job.ID, err)
+		}
+	}
+
+	secret, ok := m.opts.Secrets[job.Owner]
+	if !ok {
+		// 提交任务的令牌已从配置中移除
+		job.Callback.Status = CallbackFailed
+		job.Callback.Error = "no signing secret for the token that submitted the job"
+		save()
+		return
+	}
+
+	body, err := json.Marshal(callbackPayload(job))
+	if err != nil {
+		job.Callback.Status = CallbackFailed
+		job.Callback.Error = err.Error()
+		save()
+		return
+	}
+
+	err = m.opts.Webhook.Deliver(m.ctx, webhook.Message{
+		URL:      job.Request.CallbackURL,
+		Secret:   secret,
+		Event:    "job." + string(job.Status),
+		Delivery: job.ID,
+		Body:     body,
+	}, func(attempt webhook.Attempt) {
+		job.Callback.Attempts++
+		job.Callback.StatusCode = attempt.StatusCode
+		job.Callback.Error = ""
+		if attempt.Err != nil {
+			job.Callback.Error = attempt.Err.Error()
+		}
+		if attempt.Err == nil {
+			now := time.Now()
+			job.Callback.Status = CallbackDelivered
+			job.Callback.DeliveredAt = &now
+		}
+		save()
+	})
+
+	switch {
+	case err == nil:
+	case m.ctx.Err() != nil:
+		// 服务关闭，保持 pending
+	default:
+		job.Callback.Status = CallbackFailed
+		save()
+		log.Printf("Failed to deliver callback of job %s after %d attempts: %v", job.ID, job.Callback.Attempts, err)
+	}
+}
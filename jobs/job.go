@@ -0,0 +1,195 @@
+// jobs/job.go
+package jobs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"transbridge/webhook"
+)
+
+var (
+	ErrNotFound  = errors.New("job not found")
+	ErrQueueFull = errors.New("job queue is full")
+	ErrFinished  = errors.New("job already finished")
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusQueued    Status = "queued"    // 等待执行
+	StatusRunning   Status = "running"   // 执行中
+	StatusCompleted Status = "completed" // 已完成，单条文本的失败记录在对应结果中
+	StatusFailed    Status = "failed"    // 执行失败
+	StatusCanceled  Status = "canceled"  // 已取消
+)
+
+// Finished 任务是否已经结束
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCanceled
+}
+
+// 任务类型
+const (
+	TypeText         = "text"         // 逐条翻译 texts 中的文本
+	TypeMarkdown     = "markdown"     // 翻译 Markdown 文档
+	TypeSubtitles    = "subtitles"    // 翻译 SRT/WebVTT 字幕
+	TypeLocalization = "localization" // 翻译 PO、i18n JSON 或 XLIFF 文件
+)
+
+// Request 任务参数
+type Request struct {
+	Type  string   `json:"type"`            // 任务类型，默认 text
+	Texts []string `json:"texts,omitempty"` // text 任务：待翻译的文本
+	Text  string   `json:"text,omitempty"`  // 文档类任务：文档内容
+
+	SourceLang  string `json:"source_lang,omitempty"`
+	TargetLang  string `json:"target_lang"`
+	Provider    string `json:"provider,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Formality   string `json:"formality,omitempty"`
+	TagHandling string `json:"tag_handling,omitempty"` // text 任务：html 或 xml
+
+	FrontMatterKeys []string `json:"front_matter_keys,omitempty"` // markdown 任务：需要翻译的 front matter 字段
+	Bilingual       bool     `json:"bilingual,omitempty"`         // subtitles 任务：输出双语字幕
+	Format          string   `json:"format,omitempty"`            // localization 任务：po、json 或 xliff
+	Existing        string   `json:"existing,omitempty"`          // localization 任务：JSON 目标语言的现有翻译
+	IncludeFuzzy    bool     `json:"include_fuzzy,omitempty"`     // localization 任务：同时翻译待审校的条目
+	Retranslate     bool     `json:"retranslate,omitempty"`       // localization 任务：重新翻译所有条目
+
+	CallbackURL string `json:"callback_url,omitempty"` // 任务结束后 POST 结果的地址
+}
+
+// Validate 校验任务参数
+func (r *Request) Validate() error {
+	if r.Type == "" {
+		r.Type = TypeText
+	}
+
+	switch r.Type {
+	case TypeText:
+		if len(r.Texts) == 0 {
+			return errors.New("texts is required")
+		}
+		switch r.TagHandling {
+		case "", "html", "xml":
+		default:
+			return errors.New("tag_handling must be html or xml")
+		}
+	case TypeMarkdown, TypeSubtitles, TypeLocalization:
+		if r.Text == "" {
+			return errors.New("text is required")
+		}
+	default:
+		return errors.New("type must be text, markdown, subtitles or localization")
+	}
+
+	if r.TargetLang == "" && r.Type != TypeLocalization {
+		return errors.New("target_lang is required")
+	}
+	if r.CallbackURL != "" {
+		return webhook.ValidateURL(r.CallbackURL)
+	}
+	return nil
+}
+
+// Result 单条文本（或整篇文档）的翻译结果
+type Result struct {
+	Text       string   `json:"text,omitempty"`
+	SourceLang string   `json:"source_lang,omitempty"` // 实际使用的源语言（含自动检测结果）
+	Model      string   `json:"model,omitempty"`       // 给出译文的模型（provider/model）
+	CacheHit   bool     `json:"cache_hit,omitempty"`
+	Fuzzy      []string `json:"fuzzy,omitempty"` // localization 任务：标记为待审校的条目
+	Error      string   `json:"error,omitempty"`
+
+	ProcessTime        float64  `json:"process_time_ms,omitempty"`
+	Attempts           int      `json:"attempts,omitempty"`            // 尝试过的模型数，缓存命中时为 0
+	GlossaryViolations []string `json:"glossary_violations,omitempty"` // 译文中未按术语表翻译的术语
+}
+
+// CallbackStatus 回调投递状态
+type CallbackStatus string
+
+const (
+	CallbackPending   CallbackStatus = "pending"   // 等待投递或重试中
+	CallbackDelivered CallbackStatus = "delivered" // 回调地址返回 2xx
+	CallbackFailed    CallbackStatus = "failed"    // 重试次数用尽或返回不可重试的状态码
+)
+
+// Callback 任务结束后的回调投递记录
+type Callback struct {
+	Status      CallbackStatus `json:"status"`
+	Attempts    int            `json:"attempts"`              // 已投递次数
+	StatusCode  int            `json:"status_code,omitempty"` // 最近一次投递的响应状态码
+	Error       string         `json:"error,omitempty"`       // 最近一次投递的错误
+	DeliveredAt *time.Time     `json:"delivered_at,omitempty"`
+}
+
+// Job 异步翻译任务
+type Job struct {
+	ID      string  `json:"id"`
+	Owner   string  `json:"owner"` // 提交任务的令牌的摘要，只有同一令牌可以查询、取消
+	Status  Status  `json:"status"`
+	Request Request `json:"request"`
+
+	Total     int       `json:"total"`     // 需要翻译的文本数，文档类任务为 1
+	Completed int       `json:"completed"` // 已完成（含失败）的文本数
+	Results   []*Result `json:"results"`   // 与 texts 一一对应，尚未完成的为 null
+	Error     string    `json:"error,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	Callback *Callback `json:"callback,omitempty"` // 请求了回调的任务结束后记录投递状态
+}
+
+// newJob 创建排队中的任务
+func newJob(owner string, req Request) *Job {
+	total := 1
+	if req.Type == TypeText {
+		total = len(req.Texts)
+	}
+
+	now := time.Now()
+	return &Job{
+		ID:        newID(),
+		Owner:     owner,
+		Status:    StatusQueued,
+		Request:   req,
+		Total:     total,
+		Results:   make([]*Result, total),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// finish 以指定状态结束任务
+func (j *Job) finish(status Status, errMsg string) {
+	now := time.Now()
+	j.Status = status
+	j.Error = errMsg
+	j.UpdatedAt = now
+	j.FinishedAt = &now
+}
+
+// active 任务尚未结束或回调尚未送达，服务重启后需要继续处理
+func (j *Job) active() bool {
+	return !j.Status.Finished() || (j.Callback != nil && j.Callback.Status == CallbackPending)
+}
+
+// Owner 返回 API Key 的摘要，作为任务的归属；任务中不保存令牌原文
+func Owner(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,550 @@
+// jobs/manager.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"transbridge/internal/utils"
+	"transbridge/l10n"
+	"transbridge/markdown"
+	"transbridge/service"
+	"transbridge/webhook"
+)
+
+// saveInterval 执行中的任务保存进度的最短间隔
+const saveInterval = time.Second
+
+// callbackLease 投递回调时持有的租约键后缀，与执行任务的租约（任务 ID）区分
+const callbackLease = ":callback"
+
+// errLeaseLost 任务租约被其它实例接手，本实例停止执行且不再保存任务状态
+var errLeaseLost = errors.New("job lease lost")
+
+// Options 任务管理器选项
+type Options struct {
+	Workers     int           // 同时执行的任务数，默认 2
+	QueueSize   int           // 排队任务数上限，默认 100
+	Concurrency int           // text 任务内并发翻译的文本数，默认 4
+	Retention   time.Duration // 任务结束后保留的时间，默认 24 小时
+	LeaseTTL    time.Duration // 执行任务、投递回调时持有的租约时长，默认 30 秒；实例异常退出后其它实例等租约过期再接手
+
+	Webhook *webhook.Client   // 回调投递客户端，为空时忽略 callback_url
+	Secrets map[string]string // 回调签名密钥，按任务归属（见 Owner）索引
+}
+
+// Manager 异步翻译任务管理器：任务排队后由工作协程通过 TranslationService 执行，状态与部分结果保存在 Store 中
+// 服务关闭时执行中的任务恢复为排队状态，使用持久化存储时重启后从未完成的文本继续执行；
+// 多个实例共用存储时，执行任务与投递回调都需要先获取存储中的租约，同一任务不会被两个实例同时处理
+type Manager struct {
+	service  *service.TranslationService
+	prompts  *utils.PromptSet
+	store    Store
+	opts     Options
+	instance string // 本实例的租约持有者标识
+
+	queue   chan string
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc // 执行中任务的取消函数
+
+	ctx  context.Context // 服务关闭时取消
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewManager 创建任务管理器，调用 Start 后开始执行任务
+func NewManager(translationService *service.TranslationService, prompts *utils.PromptSet, store Store, opts Options) *Manager {
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = 24 * time.Hour
+	}
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = 30 * time.Second
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	return &Manager{
+		service:  translationService,
+		prompts:  prompts,
+		store:    store,
+		opts:     opts,
+		instance: newID(),
+		queue:    make(chan string, opts.QueueSize),
+		running:  make(map[string]context.CancelCauseFunc),
+		ctx:      ctx,
+		stop:     stop,
+	}
+}
+
+// Start 启动工作协程，并重新排队上次未完成的任务；其它实例仍持有租约的任务在租约过期前不会执行
+func (m *Manager) Start(ctx context.Context) error {
+	pending, err := m.store.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load pending jobs: %w", err)
+	}
+
+	for i := 0; i < m.opts.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+
+	var unfinished []*Job
+	for _, job := range pending {
+		if job.Status.Finished() {
+			m.notify(job) // 上次关闭时回调尚未送达
+		} else {
+			unfinished = append(unfinished, job)
+		}
+	}
+
+	if len(unfinished) > 0 {
+		log.Printf("Resuming %d unfinished translation jobs", len(unfinished))
+		// 未完成的任务可能超过队列容量，在后台依次排队
+		go func() {
+			for _, job := range unfinished {
+				select {
+				case m.queue <- job.ID:
+				case <-m.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Submit 创建任务并排队，owner 标识提交者
+func (m *Manager) Submit(ctx context.Context, owner string, req Request) (*Job, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	job := newJob(owner, req)
+	if err := m.store.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case m.queue <- job.ID:
+		return job, nil
+	default:
+		job.finish(StatusFailed, ErrQueueFull.Error())
+		m.store.Save(ctx, job)
+		return nil, ErrQueueFull
+	}
+}
+
+// Get 读取任务状态与已完成的结果
+func (m *Manager) Get(ctx context.Context, id string) (*Job, error) {
+	return m.store.Get(ctx, id)
+}
+
+// Cancel 取消任务：排队中的任务直接标记为已取消，执行中的任务在当前文本翻译结束后停止，已完成的部分结果保留
+// 任务由其它实例执行时在存储中记录取消请求，该实例续期租约时读取并停止任务
+func (m *Manager) Cancel(ctx context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.Finished() {
+		return job, ErrFinished
+	}
+
+	if cancel, ok := m.running[id]; ok {
+		// 由执行任务的工作协程写入最终状态
+		cancel(nil)
+		return job, nil
+	}
+
+	ok, err := m.store.Lease(ctx, id, m.instance, m.opts.LeaseTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// 其它实例正在执行，由该实例写入最终状态
+		if err := m.store.RequestCancel(ctx, id); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+	defer func() {
+		if err := m.store.Release(context.Background(), id, m.instance); err != nil {
+			log.Printf("Failed to release job lease %s: %v", id, err)
+		}
+	}()
+
+	// 获取租约前任务可能已由其它实例执行完毕
+	if job, err = m.store.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if job.Status.Finished() {
+		return job, ErrFinished
+	}
+	job.finish(StatusCanceled, "")
+	if err := m.store.Save(ctx, job); err != nil {
+		return nil, err
+	}
+	m.notify(job)
+	return job, nil
+}
+
+// Close 停止工作协程；执行中的任务恢复为排队状态，以便重启后继续执行
+func (m *Manager) Close() error {
+	m.stop()
+	m.wg.Wait()
+	return m.store.Close()
+}
+
+func (m *Manager) worker() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case id := <-m.queue:
+			m.run(id)
+		}
+	}
+}
+
+// run 执行一个任务
+func (m *Manager) run(id string) {
+	job, ctx, release, ok := m.begin(id)
+	if !ok {
+		return
+	}
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, id)
+		m.mu.Unlock()
+		release()
+	}()
+
+	p := &progress{manager: m, ctx: ctx, job: job}
+	var err error
+	switch job.Request.Type {
+	case TypeText:
+		err = m.translateTexts(ctx, p)
+	default:
+		err = m.translateDocument(ctx, p)
+	}
+
+	switch {
+	case errors.Is(context.Cause(ctx), errLeaseLost):
+		// 由接手租约的实例继续执行并写入状态
+		return
+	case m.ctx.Err() != nil:
+		// 服务关闭，恢复为排队状态
+		job.Status = StatusQueued
+		job.UpdatedAt = time.Now()
+		if err := m.store.Save(context.Background(), job); err != nil {
+			log.Printf("Failed to save job %s: %v", id, err)
+		}
+		return
+	case ctx.Err() != nil:
+		job.finish(StatusCanceled, "")
+	case err != nil:
+		job.finish(StatusFailed, err.Error())
+	default:
+		job.finish(StatusCompleted, "")
+	}
+	if err := m.store.Save(context.Background(), job); err != nil {
+		log.Printf("Failed to save job %s: %v", id, err)
+	}
+	m.notify(job)
+}
+
+// begin 获取任务的租约并将排队中的任务标记为执行中，返回释放租约的函数
+// 任务已结束或不存在时返回 false；租约被其它实例持有时返回 false，并在租约时长后重新排队，持有租约的实例异常退出时由本实例接手。
+// 执行期间续期租约时检查取消请求；租约被其它实例接手时以 errLeaseLost 取消任务的 context
+func (m *Manager) begin(id string) (*Job, context.Context, func(), bool) {
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	release, ok := m.hold(id, func(held bool) {
+		if !held {
+			cancel(errLeaseLost)
+			return
+		}
+		canceled, err := m.store.CancelRequested(context.Background(), id)
+		if err != nil {
+			log.Printf("Failed to check cancellation of job %s: %v", id, err)
+		} else if canceled {
+			cancel(nil)
+		}
+	})
+	if !ok {
+		cancel(nil)
+		m.requeueLater(id)
+		return nil, nil, nil, false
+	}
+	stop := func() {
+		release()
+		cancel(nil)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.store.Get(m.ctx, id)
+	if err != nil {
+		if err != ErrNotFound {
+			log.Printf("Failed to load job %s: %v", id, err)
+		}
+		stop()
+		return nil, nil, nil, false
+	}
+	if job.Status.Finished() {
+		stop()
+		return nil, nil, nil, false
+	}
+	// 其它实例收到取消请求时本实例尚未执行，或此前执行的实例异常退出
+	if canceled, err := m.store.CancelRequested(m.ctx, id); err != nil {
+		log.Printf("Failed to check cancellation of job %s: %v", id, err)
+	} else if canceled {
+		job.finish(StatusCanceled, "")
+		if err := m.store.Save(m.ctx, job); err != nil {
+			log.Printf("Failed to save job %s: %v", id, err)
+		}
+		stop()
+		m.notify(job)
+		return nil, nil, nil, false
+	}
+
+	job.Status = StatusRunning
+	job.UpdatedAt = time.Now()
+	if err := m.store.Save(m.ctx, job); err != nil {
+		log.Printf("Failed to save job %s: %v", id, err)
+		stop()
+		return nil, nil, nil, false
+	}
+
+	m.running[id] = cancel
+	return job, ctx, stop, true
+}
+
+// hold 获取 key 的租约，并在释放之前每隔三分之一租约时长续期；租约被其它实例持有或存储不可用时返回 false
+// renewed 不为空时在每次续期后调用，held 为 false 表示租约已被其它实例接手，此后不再续期
+func (m *Manager) hold(key string, renewed func(held bool)) (func(), bool) {
+	ok, err := m.store.Lease(m.ctx, key, m.instance, m.opts.LeaseTTL)
+	if err != nil {
+		if m.ctx.Err() == nil {
+			log.Printf("Failed to acquire job lease %s: %v", key, err)
+		}
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(m.opts.LeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ok, err := m.store.Lease(context.Background(), key, m.instance, m.opts.LeaseTTL)
+				if err != nil {
+					log.Printf("Failed to renew job lease %s: %v", key, err)
+					continue
+				}
+				if !ok {
+					log.Printf("Job lease %s was taken over by another instance", key)
+				}
+				if renewed != nil {
+					renewed(ok)
+				}
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		if err := m.store.Release(context.Background(), key, m.instance); err != nil {
+			log.Printf("Failed to release job lease %s: %v", key, err)
+		}
+	}, true
+}
+
+// requeueLater 租约时长后重新排队任务；服务关闭时放弃
+func (m *Manager) requeueLater(id string) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		select {
+		case <-time.After(m.opts.LeaseTTL):
+		case <-m.ctx.Done():
+			return
+		}
+		select {
+		case m.queue <- id:
+		case <-m.ctx.Done():
+		}
+	}()
+}
+
+// translateTexts 并发翻译 text 任务中尚未完成的文本，每条完成后记录结果；单条失败不影响其它文本
+func (m *Manager) translateTexts(ctx context.Context, p *progress) error {
+	job := p.job
+	sem := make(chan struct{}, m.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, text := range job.Request.Texts {
+		if job.Results[i] != nil {
+			continue // 重启前已完成
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, text string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			res, err := m.service.TranslateWithResult(ctx, m.prompts, job.Request.translateRequest(text))
+			if ctx.Err() != nil {
+				return // 取消后的结果不记录，重启后重新翻译
+			}
+
+			result := &Result{ProcessTime: float64(time.Since(start).Milliseconds())}
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Text = res.Text
+				result.SourceLang = res.SourceLang
+				result.Model = res.ModelName()
+				result.CacheHit = res.CacheHit
+				result.Attempts = res.Attempts
+				result.GlossaryViolations = res.GlossaryViolations
+			}
+			p.complete(i, result)
+		}(i, text)
+	}
+	wg.Wait()
+
+	p.flush()
+	if job.Completed == job.Total && job.Total > 0 {
+		for _, r := range job.Results {
+			if r.Error == "" {
+				return nil
+			}
+		}
+		return fmt.Errorf("all %d texts failed to translate", job.Total)
+	}
+	return nil
+}
+
+// translateDocument 翻译文档类任务
+func (m *Manager) translateDocument(ctx context.Context, p *progress) error {
+	r := p.job.Request
+	req := r.translateRequest(r.Text)
+
+	start := time.Now()
+	result := &Result{}
+	switch r.Type {
+	case TypeMarkdown:
+		doc, err := m.service.TranslateMarkdown(ctx, m.prompts, req, markdown.Options{FrontMatterKeys: r.FrontMatterKeys})
+		if err != nil {
+			return err
+		}
+		result.Text, result.SourceLang = doc.Text, doc.SourceLang
+	case TypeSubtitles:
+		doc, err := m.service.TranslateSubtitles(ctx, m.prompts, req, r.Bilingual)
+		if err != nil {
+			return err
+		}
+		result.Text, result.SourceLang = doc.Text, doc.SourceLang
+	case TypeLocalization:
+		doc, err := m.service.TranslateLocalization(ctx, m.prompts, req, l10n.Options{
+			Format:       r.Format,
+			Existing:     r.Existing,
+			IncludeFuzzy: r.IncludeFuzzy,
+			Retranslate:  r.Retranslate,
+		})
+		if err != nil {
+			return err
+		}
+		result.Text, result.SourceLang, result.Fuzzy = doc.Text, doc.SourceLang, doc.Fuzzy
+	}
+
+	result.ProcessTime = float64(time.Since(start).Milliseconds())
+	p.complete(0, result)
+	return nil
+}
+
+// translateRequest 由任务参数构造单条文本的翻译请求
+func (r Request) translateRequest(text string) service.TranslateRequest {
+	return service.TranslateRequest{
+		Text:        text,
+		SourceLang:  r.SourceLang,
+		TargetLang:  r.TargetLang,
+		Provider:    r.Provider,
+		Model:       r.Model,
+		Formality:   r.Formality,
+		TagHandling: r.TagHandling,
+	}
+}
+
+// progress 记录执行中任务的进度，按 saveInterval 节流保存
+type progress struct {
+	manager *Manager
+	ctx     context.Context // 任务的 context，租约被其它实例接手后不再保存
+
+	mu    sync.Mutex
+	job   *Job
+	saved time.Time
+}
+
+func (p *progress) complete(i int, result *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.job.Results[i] = result
+	p.job.Completed++
+	p.job.UpdatedAt = time.Now()
+	if time.Since(p.saved) >= saveInterval {
+		p.save()
+	}
+}
+
+func (p *progress) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.save()
+}
+
+// save 保存进度，调用方需持有锁
+func (p *progress) save() {
+	if errors.Is(context.Cause(p.ctx), errLeaseLost) {
+		return
+	}
+	p.saved = time.Now()
+	if err := p.manager.store.Save(context.Background(), p.job); err != nil {
+		log.Printf("Failed to save progress of job %s: %v", p.job.ID, err)
+	}
+}
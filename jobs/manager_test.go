@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestManager 创建不执行任务的管理器，与其它实例共用 store
+func newTestManager(store Store) *Manager {
+	return NewManager(nil, nil, store, Options{LeaseTTL: 30 * time.Millisecond})
+}
+
+// saveTestJob 保存一个排队中的任务
+func saveTestJob(t *testing.T, store Store) *Job {
+	t.Helper()
+	job := newJob("owner", Request{Type: TypeText, Texts: []string{"hello"}, TargetLang: "zh"})
+	if err := store.Save(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestManagerCancel(t *testing.T) {
+	tests := []struct {
+		name        string
+		otherHolds  bool // 其它实例持有任务租约
+		wantStatus  Status
+		wantRequest bool // 取消请求记录在存储中
+	}{
+		{name: "queued job is canceled directly", wantStatus: StatusCanceled},
+		{name: "job running elsewhere gets a cancel request", otherHolds: true, wantStatus: StatusQueued, wantRequest: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryStore(time.Hour)
+			m := newTestManager(store)
+			job := saveTestJob(t, store)
+			if tt.otherHolds {
+				store.Lease(ctx, job.ID, "other", time.Minute)
+			}
+
+			if _, err := m.Cancel(ctx, job.ID); err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+			saved, _ := store.Get(ctx, job.ID)
+			if saved.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", saved.Status, tt.wantStatus)
+			}
+			if requested, _ := store.CancelRequested(ctx, job.ID); requested != tt.wantRequest {
+				t.Errorf("cancel requested = %v, want %v", requested, tt.wantRequest)
+			}
+			if _, err := m.Cancel(ctx, job.ID); tt.wantStatus == StatusCanceled && err != ErrFinished {
+				t.Errorf("second Cancel() error = %v, want %v", err, ErrFinished)
+			}
+		})
+	}
+}
+
+func TestManagerBeginSkipsCancelRequested(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(time.Hour)
+	m := newTestManager(store)
+	job := saveTestJob(t, store)
+	store.RequestCancel(ctx, job.ID)
+
+	if _, _, _, ok := m.begin(job.ID); ok {
+		t.Fatal("begin() started a job with a cancel request")
+	}
+	saved, _ := store.Get(ctx, job.ID)
+	if saved.Status != StatusCanceled {
+		t.Errorf("status = %s, want %s", saved.Status, StatusCanceled)
+	}
+}
+
+func TestManagerLeaseRenewal(t *testing.T) {
+	tests := []struct {
+		name      string
+		interfere func(store *MemoryStore, m *Manager, id string)
+		wantCause error
+	}{
+		{
+			name: "cancel request from another instance",
+			interfere: func(store *MemoryStore, m *Manager, id string) {
+				store.RequestCancel(context.Background(), id)
+			},
+			wantCause: context.Canceled,
+		},
+		{
+			name: "lease taken over by another instance",
+			interfere: func(store *MemoryStore, m *Manager, id string) {
+				store.Release(context.Background(), id, m.instance)
+				store.Lease(context.Background(), id, "other", time.Minute)
+			},
+			wantCause: errLeaseLost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore(time.Hour)
+			m := newTestManager(store)
+			job := saveTestJob(t, store)
+
+			_, ctx, release, ok := m.begin(job.ID)
+			if !ok {
+				t.Fatal("begin() = false")
+			}
+			defer release()
+
+			other := newTestManager(store)
+			if _, _, _, ok := other.begin(job.ID); ok {
+				t.Fatal("another instance started a job whose lease is held")
+			}
+			other.Close()
+
+			tt.interfere(store, m, job.ID)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+				t.Fatal("job context was not canceled after lease renewal")
+			}
+			if cause := context.Cause(ctx); !errors.Is(cause, tt.wantCause) {
+				t.Errorf("cause = %v, want %v", cause, tt.wantCause)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// jobs/redis.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	redisJobPrefix    = "transbridge:job:"
+	redisPendingKey   = "transbridge:jobs:pending"
+	redisLeasePrefix  = "transbridge:jobs:lease:"
+	redisCancelPrefix = "transbridge:jobs:cancel:"
+)
+
+// leaseScript 租约不存在或由同一 holder 持有时写入并设置过期时间
+var leaseScript = redis.NewScript(`
+local holder = redis.call("get", KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call("set", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseScript 只删除自己持有的租约
+var releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// RedisStore Redis 任务存储，服务重启后未结束的任务继续执行
+// 任务以 JSON 保存在 transbridge:job:<id>，未结束或回调尚未送达的任务 ID 记录在集合 transbridge:jobs:pending 中；
+// 执行任务、投递回调的实例持有 transbridge:jobs:lease:<key> 租约，多个实例共用存储时同一任务只由一个实例处理；
+// 其它实例收到的取消请求记录在 transbridge:jobs:cancel:<id>，由持有租约的实例续期时读取
+type RedisStore struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+// NewRedisStore 创建 Redis 任务存储，client 通常与缓存共用同一 Redis 配置
+func NewRedisStore(client *redis.Client, retention time.Duration) *RedisStore {
+	return &RedisStore{client: client, retention: retention}
+}
+
+func (s *RedisStore) Save(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	key := redisJobPrefix + job.ID
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if job.Status.Finished() {
+			pipe.Set(ctx, key, data, s.retention)
+		} else {
+			pipe.Set(ctx, key, data, 0)
+		}
+		if job.active() {
+			pipe.SAdd(ctx, redisPendingKey, job.ID)
+		} else {
+			pipe.SRem(ctx, redisPendingKey, job.ID)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (*Job, error) {
+	data, err := s.client.Get(ctx, redisJobPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *RedisStore) Pending(ctx context.Context) ([]*Job, error) {
+	ids, err := s.client.SMembers(ctx, redisPendingKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*Job
+	for _, id := range ids {
+		job, err := s.Get(ctx, id)
+		if err == ErrNotFound {
+			s.client.SRem(ctx, redisPendingKey, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if job.active() {
+			pending = append(pending, job)
+		}
+	}
+	return pending, nil
+}
+
+func (s *RedisStore) Lease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	n, err := leaseScript.Run(ctx, s.client, []string{redisLeasePrefix + key}, holder, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (s *RedisStore) Release(ctx context.Context, key, holder string) error {
+	return releaseScript.Run(ctx, s.client, []string{redisLeasePrefix + key}, holder).Err()
+}
+
+func (s *RedisStore) RequestCancel(ctx context.Context, id string) error {
+	return s.client.Set(ctx, redisCancelPrefix+id, "1", s.retention).Err()
+}
+
+func (s *RedisStore) CancelRequested(ctx context.Context, id string) (bool, error) {
+	n, err := s.client.Exists(ctx, redisCancelPrefix+id).Result()
+	return n > 0, err
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,163 @@
+// jobs/store.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Store 任务状态存储
+type Store interface {
+	// Save 保存任务；已结束的任务保留 retention 后删除
+	Save(ctx context.Context, job *Job) error
+	// Get 读取任务，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*Job, error)
+	// Pending 返回尚未结束（排队中或执行中）或回调尚未送达的任务，用于重启后恢复执行
+	Pending(ctx context.Context) ([]*Job, error)
+	// Lease 以 holder 的名义获取或续期 key 的租约，租约在 ttl 后过期；被其它 holder 持有且未过期时返回 false
+	Lease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+	// Release 释放 holder 持有的租约，租约已被其它 holder 获取时不做处理
+	Release(ctx context.Context, key, holder string) error
+	// RequestCancel 记录任务的取消请求，由持有任务租约的实例读取后停止执行；记录保留 retention
+	RequestCancel(ctx context.Context, id string) error
+	// CancelRequested 判断任务是否有取消请求
+	CancelRequested(ctx context.Context, id string) (bool, error)
+	Close() error
+}
+
+// lease 内存存储中的租约
+type lease struct {
+	holder  string
+	expires time.Time
+}
+
+// MemoryStore 内存任务存储，进程重启后任务丢失
+// 任务以 JSON 保存，读写的都是副本，调用方修改返回的任务不影响存储内容
+type MemoryStore struct {
+	mu        sync.Mutex
+	jobs      map[string][]byte
+	expires   map[string]time.Time
+	leases    map[string]lease
+	cancels   map[string]bool // 有取消请求的任务
+	retention time.Duration
+}
+
+// NewMemoryStore 创建内存任务存储
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{
+		jobs:      make(map[string][]byte),
+		expires:   make(map[string]time.Time),
+		leases:    make(map[string]lease),
+		cancels:   make(map[string]bool),
+		retention: retention,
+	}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+	s.jobs[job.ID] = data
+	if job.Status.Finished() && s.retention > 0 {
+		s.expires[job.ID] = time.Now().Add(s.retention)
+	} else {
+		delete(s.expires, job.ID)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	data, ok := s.jobs[id]
+	if exp, expiring := s.expires[id]; expiring && time.Now().After(exp) {
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *MemoryStore) Pending(ctx context.Context) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []*Job
+	for _, data := range s.jobs {
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, err
+		}
+		if job.active() {
+			pending = append(pending, &job)
+		}
+	}
+	return pending, nil
+}
+
+func (s *MemoryStore) Lease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if l, ok := s.leases[key]; ok && l.holder != holder && now.Before(l.expires) {
+		return false, nil
+	}
+	s.leases[key] = lease{holder: holder, expires: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.leases[key]; ok && l.holder == holder {
+		delete(s.leases, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) RequestCancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancels[id] = true
+	return nil
+}
+
+func (s *MemoryStore) CancelRequested(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cancels[id], nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// purge 删除已过期的任务，调用方需持有锁
+func (s *MemoryStore) purge() {
+	now := time.Now()
+	for id, exp := range s.expires {
+		if now.After(exp) {
+			delete(s.jobs, id)
+			delete(s.expires, id)
+			delete(s.cancels, id)
+		}
+	}
+}
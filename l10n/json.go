@@ -0,0 +1,215 @@
+// l10n/json.go
+package l10n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonNode 保留键顺序的 JSON 值
+type jsonNode struct {
+	keys     []string    // 对象的键，与 children 一一对应
+	children []*jsonNode // 对象的值或数组元素
+	array    bool
+	object   bool
+	value    interface{} // 字符串以外的标量：json.Number、bool 或 nil
+	str      *string
+	entry    *Entry
+}
+
+// jsonFile i18n JSON 文件，支持扁平（"a.b": "..."）与嵌套的键
+// 写回时保持键的顺序与原文件的缩进，所有字符串值都是待翻译的条目
+type jsonFile struct {
+	root    *jsonNode
+	entries []*Entry
+	indent  string
+	newline string
+}
+
+// ParseJSON 解析 i18n JSON 文件；existing 为目标语言的现有翻译（可以为空），其中非空的同名键视为已翻译
+// 写回时已翻译的键使用现有译文，未翻译也未能翻译的键保留原文
+func ParseJSON(text, existing string) (File, error) {
+	root, err := decodeJSON(text)
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make(map[string]string)
+	if strings.TrimSpace(existing) != "" {
+		node, err := decodeJSON(existing)
+		if err != nil {
+			return nil, fmt.Errorf("existing translations: %w", err)
+		}
+		node.walk("", func(key string, n *jsonNode) {
+			translations[key] = *n.str
+		})
+	}
+
+	f := &jsonFile{root: root, indent: detectIndent(text), newline: "\n"}
+	if strings.Contains(text, "\r\n") {
+		f.newline = "\r\n"
+	}
+	root.walk("", func(key string, n *jsonNode) {
+		n.entry = &Entry{Key: key, Source: *n.str}
+		if t := translations[key]; t != "" {
+			n.entry.Translations = []string{t}
+			n.entry.State = Translated
+		}
+		f.entries = append(f.entries, n.entry)
+	})
+	return f, nil
+}
+
+func decodeJSON(text string) (*jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	dec.UseNumber()
+
+	root, err := readJSONNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if !root.object {
+		return nil, fmt.Errorf("invalid JSON: top-level value must be an object")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level object")
+	}
+	return root, nil
+}
+
+func readJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		node := &jsonNode{object: v == '{', array: v == '['}
+		for dec.More() {
+			if node.object {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, keyTok.(string))
+			}
+			child, err := readJSONNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, child)
+		}
+		// 读取对应的结束符
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &jsonNode{str: &v}, nil
+	default:
+		return &jsonNode{value: v}, nil
+	}
+}
+
+// walk 按文档顺序遍历字符串值；键路径以 "." 连接，数组元素为 "[i]"
+func (n *jsonNode) walk(path string, fn func(key string, n *jsonNode)) {
+	switch {
+	case n.str != nil:
+		fn(path, n)
+	case n.object:
+		for i, key := range n.keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			n.children[i].walk(child, fn)
+		}
+	case n.array:
+		for i, c := range n.children {
+			c.walk(path+"["+strconv.Itoa(i)+"]", fn)
+		}
+	}
+}
+
+// detectIndent 取第一个缩进行的缩进作为单层缩进，默认两个空格
+func detectIndent(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+func (f *jsonFile) Format() string           { return FormatJSON }
+func (f *jsonFile) Entries() []*Entry        { return f.entries }
+func (f *jsonFile) SourceLanguage() string   { return "" }
+func (f *jsonFile) TargetLanguage() string   { return "" }
+func (f *jsonFile) SetTargetLanguage(string) {}
+
+func (f *jsonFile) Render() (string, error) {
+	var b bytes.Buffer
+	if err := f.write(&b, f.root, ""); err != nil {
+		return "", err
+	}
+	b.WriteString("\n")
+	return strings.ReplaceAll(b.String(), "\n", f.newline), nil
+}
+
+func (f *jsonFile) write(b *bytes.Buffer, n *jsonNode, indent string) error {
+	switch {
+	case n.object || n.array:
+		open, close := "{", "}"
+		if n.array {
+			open, close = "[", "]"
+		}
+		b.WriteString(open)
+		if len(n.children) == 0 {
+			b.WriteString(close)
+			return nil
+		}
+		for i, child := range n.children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n" + indent + f.indent)
+			if n.object {
+				writeJSONValue(b, n.keys[i])
+				b.WriteString(": ")
+			}
+			if err := f.write(b, child, indent+f.indent); err != nil {
+				return err
+			}
+		}
+		b.WriteString("\n" + indent + close)
+		return nil
+	case n.str != nil:
+		text := *n.str
+		switch e := n.entry; {
+		case e.updated && !e.fuzzy:
+			text = e.translations[0]
+		case len(e.Translations) > 0:
+			text = e.Translations[0]
+		}
+		return writeJSONValue(b, text)
+	default:
+		return writeJSONValue(b, n.value)
+	}
+}
+
+// writeJSONValue 编码单个值，不转义 HTML 字符
+func writeJSONValue(b *bytes.Buffer, v interface{}) error {
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	b.Truncate(b.Len() - 1) // Encode 末尾的换行
+	return nil
+}
@@ -0,0 +1,110 @@
+// l10n/l10n.go
+package l10n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 支持的本地化文件格式
+const (
+	FormatPO    = "po"
+	FormatJSON  = "json"
+	FormatXLIFF = "xliff"
+)
+
+// State 条目的翻译状态
+type State int
+
+const (
+	Untranslated State = iota // 没有译文
+	Fuzzy                     // 有译文但待审校（PO 的 fuzzy 标记、XLIFF 的 needs-review 状态）
+	Translated                // 已翻译
+)
+
+// Entry 需要翻译的一个条目
+type Entry struct {
+	Key          string   // 条目标识：PO 为 msgid（有 msgctxt 时为 "msgctxt|msgid"），JSON 为键路径，XLIFF 为 unit id
+	Source       string   // 原文
+	SourcePlural string   // 复数形式的原文，仅 PO 的 msgid_plural
+	Translations []string // 现有译文，PO 复数条目有多个
+	State        State
+	XML          bool // 原文为 XML 片段（XLIFF），其中的实体与内联标签需要保留
+
+	updated      bool
+	translations []string
+	fuzzy        bool
+}
+
+// SetTranslation 写入译文；fuzzy 为 true 时标记为待审校（JSON 不支持该标记，保留原有内容）
+// PO 复数条目 translations 依次对应 msgstr[0]、msgstr[1]…
+func (e *Entry) SetTranslation(translations []string, fuzzy bool) {
+	e.updated = true
+	e.translations = translations
+	e.fuzzy = fuzzy
+}
+
+// File 解析后的本地化文件，Render 按原格式写回，未修改的部分保持原样
+type File interface {
+	Format() string
+	Entries() []*Entry
+	SourceLanguage() string        // 文件中声明的源语言，未声明时为空
+	TargetLanguage() string        // 文件中声明的目标语言，未声明时为空
+	SetTargetLanguage(lang string) // 文件要求声明目标语言且尚未声明时写入，其它格式忽略
+	Render() (string, error)
+}
+
+// Options 本地化文件的解析与翻译选项
+type Options struct {
+	Format       string // po、json 或 xliff，为空时按内容推断
+	Existing     string // 仅用于 JSON：目标语言的现有翻译文件，其中已有的键视为已翻译
+	IncludeFuzzy bool   // 同时翻译待审校的条目
+	Retranslate  bool   // 重新翻译所有条目，包括已翻译的条目
+}
+
+// Selects 条目是否需要翻译：默认只翻译没有译文的条目；原文为空或只有空白的条目（i18n JSON 中常见的占位键）不翻译，写回时保持原样
+func (o Options) Selects(e *Entry) bool {
+	if strings.TrimSpace(e.Source) == "" {
+		return false
+	}
+	switch e.State {
+	case Translated:
+		return o.Retranslate
+	case Fuzzy:
+		return o.IncludeFuzzy || o.Retranslate
+	default:
+		return true
+	}
+}
+
+// Parse 解析本地化文件
+func Parse(text string, opts Options) (File, error) {
+	format := opts.Format
+	if format == "" {
+		format = DetectFormat(text)
+	}
+
+	switch strings.ToLower(format) {
+	case FormatPO:
+		return ParsePO(text)
+	case FormatJSON:
+		return ParseJSON(text, opts.Existing)
+	case FormatXLIFF, "xlf":
+		return ParseXLIFF(text)
+	default:
+		return nil, fmt.Errorf("unsupported localization format: %s", format)
+	}
+}
+
+// DetectFormat 按内容推断文件格式：以 "<" 开头为 XLIFF，以 "{" 开头为 JSON，其余为 PO
+func DetectFormat(text string) string {
+	trimmed := strings.TrimLeft(strings.TrimPrefix(text, "\ufeff"), " \t\r\n")
+	switch {
+	case strings.HasPrefix(trimmed, "<"):
+		return FormatXLIFF
+	case strings.HasPrefix(trimmed, "{"):
+		return FormatJSON
+	default:
+		return FormatPO
+	}
+}
@@ -0,0 +1,252 @@
+// l10n/message.go
+package l10n
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"transbridge/markup"
+)
+
+// printfPattern printf 风格的占位符：%s、%1$d、%.2f、%@（iOS）、%(name)s（Python）与 %%
+// 不支持空格标志，避免把 "50% off" 之类的文本误认为占位符
+var printfPattern = regexp.MustCompile(`%(?:\d+\$)?[-+0#']*(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:hh|h|ll|l|L|q|j|z|t)?[diouxXeEfFgGaAcspn@]|%\([A-Za-z_]\w*\)[-+0#]*\d*(?:\.\d+)?[diouxXeEfFgGcrs]|%%`)
+
+// positionalPattern 带参数位置的 printf 占位符，例如 %1$s
+var positionalPattern = regexp.MustCompile(`^%\d+\$`)
+
+// icuSelectPattern ICU 复数、选择参数的开头，例如 "{count, plural," 中的 "count, plural,"
+var icuSelectPattern = regexp.MustCompile(`^\s*[\w.]+\s*,\s*(plural|selectordinal|select)\s*,`)
+
+// icuSelectorPattern ICU 分支的选择器，例如 " one {"、"=0 {"、" offset:1 other {"
+var icuSelectorPattern = regexp.MustCompile(`^\s*(?:offset:\d+\s+)?(?:=\d+|[\w-]+)\s*\{`)
+
+// xliffNativeElements XLIFF 1.2 中内容为原始格式代码的内联元素，连同内容整体保留
+var xliffNativeElements = map[string]bool{
+	"ph": true, "bpt": true, "ept": true, "it": true,
+}
+
+// ParseMessage 解析界面文案，返回可保护占位符的文档
+// ICU 参数（{count}、{{name}}、${var}）、printf 占位符与 HTML 标签都替换为占位符；
+// ICU 复数、选择参数只保护语法部分，各分支的文本仍然翻译。xml 为 true 时按 XLIFF 片段解析并保留字符实体
+func ParseMessage(text string, xml bool) *markup.Document {
+	return markup.NewDocument(messageTokens(text, xml), xml)
+}
+
+// CheckPlaceholders 校验译文中的占位符与原文一致：每个占位符出现的次数相同，且没有多出的占位符；
+// 不带参数位置的 printf 占位符按顺序取参数，译文中的顺序也必须与原文相同，否则运行时参数类型会错位
+func CheckPlaceholders(source, translation string, xml bool) error {
+	sourceTokens, translationTokens := messageTokens(source, xml), messageTokens(translation, xml)
+
+	counts := make(map[string]int)
+	for _, tok := range sourceTokens {
+		if tok.Type != markup.TextToken {
+			counts[placeholderKey(tok.Raw)]++
+		}
+	}
+	for _, tok := range translationTokens {
+		if tok.Type == markup.TextToken {
+			continue
+		}
+		key := placeholderKey(tok.Raw)
+		if counts[key] == 0 {
+			return fmt.Errorf("unexpected placeholder %q in translation", tok.Raw)
+		}
+		counts[key]--
+	}
+	for key, n := range counts {
+		if n > 0 {
+			return fmt.Errorf("placeholder %q missing from translation", key)
+		}
+	}
+
+	want, got := sequentialPrintf(sourceTokens), sequentialPrintf(translationTokens)
+	for i := range want {
+		if want[i] != got[i] {
+			return fmt.Errorf("placeholders %s are reordered as %s in translation, use positional placeholders such as %%1$s to reorder",
+				strings.Join(want, " "), strings.Join(got, " "))
+		}
+	}
+	return nil
+}
+
+// sequentialPrintf 返回按顺序取参数的 printf 占位符，不含 %1$s 等位置参数、%(name)s 命名参数与 %%
+func sequentialPrintf(tokens []markup.Token) []string {
+	var result []string
+	for _, tok := range tokens {
+		raw := tok.Raw
+		if tok.Type != markup.OpaqueToken || !strings.HasPrefix(raw, "%") || len(raw) < 2 {
+			continue
+		}
+		if raw == "%%" || raw[1] == '(' || raw[1] == '{' || positionalPattern.MatchString(raw) {
+			continue
+		}
+		result = append(result, raw)
+	}
+	return result
+}
+
+// placeholderKey 忽略占位符中的空白，ICU 分支之间的缩进不影响校验
+func placeholderKey(raw string) string {
+	return strings.Join(strings.Fields(raw), "")
+}
+
+func messageTokens(text string, xml bool) []markup.Token {
+	mode := "html"
+	if xml {
+		mode = "xml"
+	}
+
+	tokens := markup.Tokenize(text, mode)
+	if xml {
+		tokens = collapseNative(tokens)
+	}
+
+	var result []markup.Token
+	for _, tok := range tokens {
+		if tok.Type == markup.TextToken {
+			result = append(result, splitPlaceholders(tok.Raw, false)...)
+			continue
+		}
+		result = append(result, tok)
+	}
+	return result
+}
+
+// collapseNative 将 XLIFF 原始代码元素与 translate="no" 的元素（含内容）合并为一个不翻译的片段
+func collapseNative(tokens []markup.Token) []markup.Token {
+	var result []markup.Token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Type != markup.StartTagToken || (!xliffNativeElements[tok.Name] && !strings.Contains(tok.Raw, `translate="no"`)) {
+			result = append(result, tok)
+			continue
+		}
+
+		depth, end := 0, -1
+		for j := i; j < len(tokens) && end < 0; j++ {
+			switch {
+			case tokens[j].Type == markup.StartTagToken && tokens[j].Name == tok.Name:
+				depth++
+			case tokens[j].Type == markup.EndTagToken && tokens[j].Name == tok.Name:
+				if depth--; depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			result = append(result, tok)
+			continue
+		}
+
+		var raw strings.Builder
+		for _, t := range tokens[i : end+1] {
+			raw.WriteString(t.Raw)
+		}
+		result = append(result, markup.Token{Type: markup.OpaqueToken, Raw: raw.String(), Name: tok.Name})
+		i = end
+	}
+	return result
+}
+
+// splitPlaceholders 将文本中的占位符切分为不翻译的片段
+// ICU 复数、选择参数的开头与结尾、各分支的 "选择器 {" 与 "}" 作为成对片段，译文中必须保持嵌套结构；
+// plural 为 true 时处于复数分支内，"#" 也是占位符
+func splitPlaceholders(text string, plural bool) []markup.Token {
+	var tokens []markup.Token
+	last := 0
+	flush := func(end int) {
+		if end > last {
+			tokens = append(tokens, markup.Token{Type: markup.TextToken, Raw: text[last:end]})
+		}
+	}
+	opaque := func(start, end int) {
+		flush(start)
+		tokens = append(tokens, markup.Token{Type: markup.OpaqueToken, Raw: text[start:end]})
+		last = end
+	}
+
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case c == '{' || ((c == '$' || c == '%') && i+1 < len(text) && text[i+1] == '{'):
+			open := i
+			if c != '{' {
+				open++
+			}
+			end := matchBrace(text, open)
+			if end < 0 {
+				i++
+				continue
+			}
+			if c == '{' {
+				if branches := icuSelect(text[open:end]); branches != nil {
+					flush(i)
+					tokens = append(tokens, branches...)
+					last = end
+					i = end
+					continue
+				}
+			}
+			opaque(i, end)
+			i = end
+		case c == '%':
+			if loc := printfPattern.FindStringIndex(text[i:]); loc != nil && loc[0] == 0 {
+				opaque(i, i+loc[1])
+				i += loc[1]
+				continue
+			}
+			i++
+		case c == '#' && plural:
+			opaque(i, i+1)
+			i++
+		default:
+			i++
+		}
+	}
+	flush(len(text))
+	return tokens
+}
+
+// icuSelect 解析 ICU 复数、选择参数（含外层花括号），不是此类参数或语法有误时返回 nil
+func icuSelect(arg string) []markup.Token {
+	m := icuSelectPattern.FindStringSubmatch(arg[1:])
+	if m == nil {
+		return nil
+	}
+	head, plural := m[0], m[1] != "select"
+
+	tokens := []markup.Token{{Type: markup.StartTagToken, Raw: arg[:1+len(head)], Name: "icu"}}
+	rest := arg[1+len(head) : len(arg)-1]
+	for strings.TrimSpace(rest) != "" {
+		selector := icuSelectorPattern.FindString(rest)
+		if selector == "" {
+			return nil
+		}
+		end := matchBrace(rest, len(selector)-1)
+		if end < 0 {
+			return nil
+		}
+		tokens = append(tokens, markup.Token{Type: markup.StartTagToken, Raw: selector, Name: "icu-branch"})
+		tokens = append(tokens, splitPlaceholders(rest[len(selector):end-1], plural)...)
+		tokens = append(tokens, markup.Token{Type: markup.EndTagToken, Raw: "}", Name: "icu-branch"})
+		rest = rest[end:]
+	}
+	return append(tokens, markup.Token{Type: markup.EndTagToken, Raw: rest + "}", Name: "icu"})
+}
+
+// matchBrace 返回 text[open] 处的 "{" 对应的 "}" 之后的位置，没有配对时返回 -1
+func matchBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,56 @@
+package l10n
+
+import "testing"
+
+func TestCheckPlaceholders(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		translation string
+		xml         bool
+		wantErr     bool
+	}{
+		{name: "no placeholders", source: "Sign in", translation: "登录"},
+		{name: "icu argument kept", source: "Hello, {name}!", translation: "你好，{name}！"},
+		{name: "icu argument missing", source: "Hello, {name}!", translation: "你好！", wantErr: true},
+		{name: "icu argument renamed", source: "Hello, {name}!", translation: "你好，{名字}！", wantErr: true},
+		{name: "mustache argument kept", source: "{{count}} files", translation: "{{count}} 个文件"},
+		{name: "template literal kept", source: "Total: ${amount}", translation: "合计：${amount}"},
+		{name: "printf placeholders in order", source: "%s of %d", translation: "%s，共 %d"},
+		{name: "printf placeholders reordered", source: "%s of %d", translation: "%d 中的 %s", wantErr: true},
+		{name: "positional printf reordered", source: "%1$s of %2$d", translation: "%2$d 中的 %1$s"},
+		{name: "named printf reordered", source: "%(user)s has %(count)d", translation: "%(count)d 属于 %(user)s"},
+		{name: "printf placeholder duplicated", source: "%s", translation: "%s %s", wantErr: true},
+		{name: "literal percent ignored for order", source: "100%% of %s", translation: "%s 的 100%%"},
+		{name: "html tags kept", source: "Click <b>here</b>", translation: "点击<b>这里</b>"},
+		{name: "html tag dropped", source: "Click <b>here</b>", translation: "点击这里", wantErr: true},
+		{name: "html tag added", source: "Click here", translation: "点击<b>这里</b>", wantErr: true},
+		{
+			name:        "icu plural branches translated",
+			source:      "{count, plural, one {# file} other {# files}}",
+			translation: "{count, plural, one {# 个文件} other {# 个文件}}",
+		},
+		{
+			name:        "icu plural whitespace ignored",
+			source:      "{count, plural, one {# file} other {# files}}",
+			translation: "{count,plural,\n  one {# 个文件}\n  other {# 个文件}}",
+		},
+		{
+			name:        "icu plural selector renamed",
+			source:      "{count, plural, one {# file} other {# files}}",
+			translation: "{total, plural, one {# 个文件} other {# 个文件}}",
+			wantErr:     true,
+		},
+		{name: "xliff inline element kept", source: `Save <ph id="1"/>`, translation: `保存<ph id="1"/>`, xml: true},
+		{name: "xliff inline element dropped", source: `Save <ph id="1"/>`, translation: "保存", xml: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPlaceholders(tt.source, tt.translation, tt.xml)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPlaceholders(%q, %q) error = %v, wantErr %v", tt.source, tt.translation, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// l10n/po.go
+package l10n
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// poKeywordPattern PO 条目中的关键字行，例如 msgid "..."、msgstr[1] "..."
+var poKeywordPattern = regexp.MustCompile(`^(msgctxt|msgid_plural|msgid|msgstr(?:\[(\d+)\])?)\s+(".*")\s*$`)
+
+// poLanguagePattern 头部条目中的 Language 字段
+var poLanguagePattern = regexp.MustCompile(`(?m)^Language:\s*([^\s\\]+)`)
+
+// poFile gettext PO 文件
+// 文件按空行拆分为块，每块最多一个条目；未修改的块原样输出
+type poFile struct {
+	blocks  []*poBlock
+	entries []*Entry
+	lang    string
+	newline string
+}
+
+// poBlock PO 文件中的一块：条目连同其注释，sep 为其后的空行
+type poBlock struct {
+	lines []string
+	sep   []string
+	entry *Entry
+
+	comments  []string // 条目的注释行，不含 "#," 标志行
+	flagsAt   int      // 标志行在注释行中的位置，没有标志行时为 -1
+	flags     []string
+	keywords  []string // msgctxt、msgid、msgid_plural 的原始行（含续行），写回时保持不变
+	msgstrIdx []int    // 各 msgstr 的复数序号
+}
+
+// ParsePO 解析 gettext PO 文件
+// 头部条目（msgid ""）与已废弃的条目（#~）不翻译；带 fuzzy 标志的条目视为待审校
+func ParsePO(text string) (File, error) {
+	f := &poFile{newline: "\n"}
+	if strings.Contains(text, "\r\n") {
+		f.newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); {
+		start := i
+		for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			i++
+		}
+		end := i
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			i++
+		}
+
+		block := &poBlock{lines: lines[start:end], sep: lines[end:i], flagsAt: -1}
+		if err := f.parseBlock(block); err != nil {
+			return nil, fmt.Errorf("line %d: %w", start+1, err)
+		}
+		f.blocks = append(f.blocks, block)
+	}
+	return f, nil
+}
+
+func (f *poFile) parseBlock(block *poBlock) error {
+	fields := make(map[string]string)
+	msgstrs := make(map[int]string)
+	current := ""
+	for _, line := range block.lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#~"):
+			// 已废弃的条目原样保留
+			return nil
+		case strings.HasPrefix(trimmed, "#,"):
+			block.flagsAt = len(block.comments)
+			for _, flag := range strings.Split(trimmed[2:], ",") {
+				if flag = strings.TrimSpace(flag); flag != "" {
+					block.flags = append(block.flags, flag)
+				}
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			block.comments = append(block.comments, line)
+		case strings.HasPrefix(trimmed, `"`):
+			if current == "" {
+				return fmt.Errorf("unexpected string continuation %s", trimmed)
+			}
+			s, err := unquotePO(trimmed)
+			if err != nil {
+				return err
+			}
+			if block.msgstrIdx == nil {
+				block.keywords = append(block.keywords, line)
+				fields[current] += s
+			} else {
+				msgstrs[block.msgstrIdx[len(block.msgstrIdx)-1]] += s
+			}
+		default:
+			m := poKeywordPattern.FindStringSubmatch(trimmed)
+			if m == nil {
+				return fmt.Errorf("invalid line %q", trimmed)
+			}
+			s, err := unquotePO(m[3])
+			if err != nil {
+				return err
+			}
+			current = m[1]
+
+			if !strings.HasPrefix(current, "msgstr") {
+				if block.msgstrIdx != nil {
+					return fmt.Errorf("%s after msgstr", current)
+				}
+				block.keywords = append(block.keywords, line)
+				fields[current] = s
+				continue
+			}
+			idx := 0
+			if m[2] != "" {
+				idx, _ = strconv.Atoi(m[2])
+			}
+			block.msgstrIdx = append(block.msgstrIdx, idx)
+			msgstrs[idx] = s
+		}
+	}
+
+	f.buildEntry(block, fields, msgstrs)
+	return nil
+}
+
+// buildEntry 由解析出的字段构造条目；头部条目只提取目标语言
+func (f *poFile) buildEntry(block *poBlock, fields map[string]string, msgstrs map[int]string) {
+	id, ok := fields["msgid"]
+	if !ok || len(block.msgstrIdx) == 0 {
+		return
+	}
+	if id == "" {
+		if m := poLanguagePattern.FindStringSubmatch(msgstrs[0]); m != nil {
+			f.lang = m[1]
+		}
+		return
+	}
+
+	entry := &Entry{
+		Key:          id,
+		Source:       id,
+		SourcePlural: fields["msgid_plural"],
+	}
+	if ctxt, ok := fields["msgctxt"]; ok {
+		entry.Key = ctxt + "|" + id
+	}
+
+	translated := true
+	for _, idx := range block.msgstrIdx {
+		entry.Translations = append(entry.Translations, msgstrs[idx])
+		translated = translated && msgstrs[idx] != ""
+	}
+	switch {
+	case block.hasFlag("fuzzy"):
+		entry.State = Fuzzy
+	case translated:
+		entry.State = Translated
+	}
+
+	block.entry = entry
+	f.entries = append(f.entries, entry)
+}
+
+func (b *poBlock) hasFlag(flag string) bool {
+	for _, f := range b.flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *poFile) Format() string           { return FormatPO }
+func (f *poFile) Entries() []*Entry        { return f.entries }
+func (f *poFile) SourceLanguage() string   { return "" }
+func (f *poFile) TargetLanguage() string   { return f.lang }
+func (f *poFile) SetTargetLanguage(string) {}
+
+func (f *poFile) Render() (string, error) {
+	var lines []string
+	for _, block := range f.blocks {
+		if block.entry != nil && block.entry.updated {
+			lines = append(lines, block.render()...)
+		} else {
+			lines = append(lines, block.lines...)
+		}
+		lines = append(lines, block.sep...)
+	}
+	return strings.Join(lines, f.newline), nil
+}
+
+// render 写回修改后的条目：更新 fuzzy 标志与 msgstr，其余行保持不变
+// 取消 fuzzy 标志时同时去掉记录旧原文的 "#|" 注释，与 msgmerge 等工具的行为一致
+func (b *poBlock) render() []string {
+	e := b.entry
+
+	var flags []string
+	if e.fuzzy {
+		flags = append(flags, "fuzzy")
+	}
+	for _, flag := range b.flags {
+		if flag != "fuzzy" {
+			flags = append(flags, flag)
+		}
+	}
+
+	flagsAt := b.flagsAt
+	if flagsAt < 0 {
+		flagsAt = len(b.comments)
+	}
+
+	var lines []string
+	for i := 0; i <= len(b.comments); i++ {
+		if i == flagsAt && len(flags) > 0 {
+			lines = append(lines, "#, "+strings.Join(flags, ", "))
+		}
+		if i == len(b.comments) {
+			break
+		}
+		if !e.fuzzy && strings.HasPrefix(strings.TrimSpace(b.comments[i]), "#|") {
+			continue
+		}
+		lines = append(lines, b.comments[i])
+	}
+
+	lines = append(lines, b.keywords...)
+	for i, idx := range b.msgstrIdx {
+		keyword := "msgstr"
+		if e.SourcePlural != "" {
+			keyword = fmt.Sprintf("msgstr[%d]", idx)
+		}
+		text := ""
+		if i < len(e.translations) {
+			text = e.translations[i]
+		}
+		lines = append(lines, formatPOString(keyword, text)...)
+	}
+	return lines
+}
+
+// formatPOString 按 gettext 的习惯格式化字符串：包含换行的多行文本从空字符串开始，每行一个换行符结尾的片段
+func formatPOString(keyword, text string) []string {
+	if !strings.Contains(strings.TrimSuffix(text, "\n"), "\n") {
+		return []string{keyword + " " + quotePO(text)}
+	}
+
+	lines := []string{keyword + ` ""`}
+	for text != "" {
+		n := strings.IndexByte(text, '\n') + 1
+		if n == 0 {
+			n = len(text)
+		}
+		lines = append(lines, quotePO(text[:n]))
+		text = text[n:]
+	}
+	return lines
+}
+
+// quotePO 转义并加上引号
+func quotePO(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquotePO 解析带引号的 PO 字符串
+func unquotePO(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	s = s[1 : len(s)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i++; i == len(s) {
+			return "", fmt.Errorf("invalid escape at end of string")
+		}
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		default:
+			// \"、\\ 以及其它字符按原样保留
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,333 @@
+// l10n/xliff.go
+package l10n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// xliffVersion2Pattern XLIFF 2.x 的根元素
+var xliffVersion2Pattern = regexp.MustCompile(`<xliff\b[^>]*\bversion\s*=\s*["']2\.`)
+
+// xliffElement 元素在文件中的位置：开始标签 [start, openEnd)，结束标签 [closeStart, end)
+// 自闭合元素的 openEnd、closeStart 与 end 相同
+type xliffElement struct {
+	start, openEnd, closeStart, end int
+}
+
+func (e xliffElement) selfClosing() bool {
+	return e.openEnd == e.end
+}
+
+// xliffUnit 一个可翻译的单元：XLIFF 1.2 的 <trans-unit>，或 XLIFF 2.0 <unit> 中的 <segment>
+type xliffUnit struct {
+	source xliffElement
+	target *xliffElement
+	state  xliffElement // 带 state 属性的元素：1.2 为 <target>，2.0 为 <segment>
+	indent string       // <source> 之前的空白，插入 <target> 时沿用
+	entry  *Entry
+}
+
+// xliffFile XLIFF 1.2/2.0 文件，只修改 <target> 与 state 属性，其余内容原样保留
+type xliffFile struct {
+	text    string
+	v2      bool
+	units   []*xliffUnit
+	entries []*Entry
+	srcLang string
+	trgLang string
+	langTag xliffElement // 声明语言的元素：1.2 为第一个 <file>，2.0 为 <xliff>
+}
+
+// ParseXLIFF 解析 XLIFF 1.2 或 2.0 文件
+// translate="no" 的单元不翻译；1.2 中 state 为 needs-review-* 的译文、2.0 中 state 为 initial 的译文视为待审校
+func ParseXLIFF(text string) (File, error) {
+	f := &xliffFile{text: text, v2: xliffVersion2Pattern.MatchString(text)}
+
+	langElement, srcAttr, trgAttr := "file", "source-language", "target-language"
+	if f.v2 {
+		langElement, srcAttr, trgAttr = "xliff", "srcLang", "trgLang"
+	}
+	el, ok := findElement(text, langElement, 0, len(text))
+	if !ok {
+		return nil, fmt.Errorf("invalid XLIFF: missing <%s> element", langElement)
+	}
+	f.langTag = el
+	f.srcLang = getAttr(text[el.start:el.openEnd], srcAttr)
+	f.trgLang = getAttr(text[el.start:el.openEnd], trgAttr)
+
+	var err error
+	if f.v2 {
+		err = f.parseV2()
+	} else {
+		err = f.parseV1()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *xliffFile) parseV1() error {
+	for pos := 0; ; {
+		unit, ok := findElement(f.text, "trans-unit", pos, len(f.text))
+		if !ok {
+			return nil
+		}
+		pos = unit.end
+
+		open := f.text[unit.start:unit.openEnd]
+		if getAttr(open, "translate") == "no" {
+			continue
+		}
+
+		u, err := f.parseUnit(unit, getAttr(open, "id"))
+		if err != nil {
+			return err
+		}
+		if u.target != nil {
+			u.state = *u.target
+		}
+
+		switch state := getAttr(f.text[u.state.start:u.state.openEnd], "state"); {
+		case u.target == nil || u.entry.Translations[0] == "":
+		case strings.HasPrefix(state, "needs-review"):
+			u.entry.State = Fuzzy
+		case state == "" || state == "translated" || state == "signed-off" || state == "final":
+			u.entry.State = Translated
+		}
+		f.add(u)
+	}
+}
+
+func (f *xliffFile) parseV2() error {
+	for pos := 0; ; {
+		unit, ok := findElement(f.text, "unit", pos, len(f.text))
+		if !ok {
+			return nil
+		}
+		pos = unit.end
+
+		open := f.text[unit.start:unit.openEnd]
+		if getAttr(open, "translate") == "no" {
+			continue
+		}
+		id := getAttr(open, "id")
+
+		for i, segPos := 0, unit.openEnd; ; i++ {
+			seg, ok := findElement(f.text, "segment", segPos, unit.closeStart)
+			if !ok {
+				break
+			}
+			segPos = seg.end
+
+			key := id
+			if segID := getAttr(f.text[seg.start:seg.openEnd], "id"); segID != "" {
+				key = id + "/" + segID
+			} else if i > 0 {
+				key = fmt.Sprintf("%s/%d", id, i+1)
+			}
+			u, err := f.parseUnit(seg, key)
+			if err != nil {
+				return err
+			}
+			u.state = seg
+
+			switch state := getAttr(f.text[seg.start:seg.openEnd], "state"); {
+			case u.target == nil || u.entry.Translations[0] == "":
+			case state == "" || state == "initial":
+				u.entry.State = Fuzzy
+			default:
+				u.entry.State = Translated
+			}
+			f.add(u)
+		}
+	}
+}
+
+// parseUnit 在容器元素中查找 <source> 与 <target>；1.2 中 <alt-trans> 里的候选译文不计入
+func (f *xliffFile) parseUnit(container xliffElement, key string) (*xliffUnit, error) {
+	source, ok := findElement(f.text, "source", container.openEnd, container.closeStart)
+	if !ok || source.selfClosing() {
+		return nil, fmt.Errorf("invalid XLIFF: unit %q has no <source>", key)
+	}
+
+	u := &xliffUnit{source: source}
+	lineStart := strings.LastIndexByte(f.text[:source.start], '\n') + 1
+	if strings.TrimSpace(f.text[lineStart:source.start]) == "" {
+		u.indent = f.text[lineStart:source.start]
+	}
+
+	end := container.closeStart
+	if alt := strings.Index(f.text[source.end:end], "<alt-trans"); alt >= 0 {
+		end = source.end + alt
+	}
+	u.entry = &Entry{
+		Key:    key,
+		Source: f.text[source.openEnd:source.closeStart],
+		XML:    true,
+	}
+	if target, ok := findElement(f.text, "target", source.end, end); ok {
+		u.target = &target
+		u.entry.Translations = []string{f.text[target.openEnd:target.closeStart]}
+	}
+	return u, nil
+}
+
+func (f *xliffFile) add(u *xliffUnit) {
+	f.units = append(f.units, u)
+	f.entries = append(f.entries, u.entry)
+}
+
+func (f *xliffFile) Format() string         { return FormatXLIFF }
+func (f *xliffFile) Entries() []*Entry      { return f.entries }
+func (f *xliffFile) SourceLanguage() string { return f.srcLang }
+func (f *xliffFile) TargetLanguage() string { return f.trgLang }
+
+// SetTargetLanguage 文件未声明目标语言时写入 target-language（1.2）或 trgLang（2.0）属性
+// XLIFF 2.0 要求包含 <target> 的文件声明 trgLang
+func (f *xliffFile) SetTargetLanguage(lang string) {
+	if f.trgLang == "" {
+		f.trgLang = lang
+	}
+}
+
+// xliffEdit 对原文件的一处替换
+type xliffEdit struct {
+	start, end int
+	text       string
+}
+
+func (f *xliffFile) Render() (string, error) {
+	var edits []xliffEdit
+
+	langAttr := "target-language"
+	if f.v2 {
+		langAttr = "trgLang"
+	}
+	if open := f.text[f.langTag.start:f.langTag.openEnd]; f.trgLang != "" && getAttr(open, langAttr) == "" {
+		edits = append(edits, xliffEdit{f.langTag.start, f.langTag.openEnd, setAttr(open, langAttr, f.trgLang)})
+	}
+
+	for _, u := range f.units {
+		e := u.entry
+		if !e.updated {
+			continue
+		}
+
+		state := "translated"
+		if e.fuzzy {
+			state = "needs-review-translation"
+			if f.v2 {
+				state = "initial"
+			}
+		}
+
+		openTag := "<target>"
+		if u.target != nil {
+			openTag = f.text[u.target.start:u.target.openEnd]
+			if u.target.selfClosing() {
+				openTag = strings.TrimSuffix(strings.TrimSuffix(openTag, "/>"), " ") + ">"
+			}
+		}
+		if !f.v2 {
+			openTag = setAttr(openTag, "state", state)
+		}
+		target := openTag + e.translations[0] + "</target>"
+
+		if u.target != nil {
+			edits = append(edits, xliffEdit{u.target.start, u.target.end, target})
+		} else {
+			sep := ""
+			if u.indent != "" {
+				sep = "\n" + u.indent
+				if strings.HasSuffix(f.text[:u.source.start], "\r\n"+u.indent) {
+					sep = "\r" + sep
+				}
+			}
+			edits = append(edits, xliffEdit{u.source.end, u.source.end, sep + target})
+		}
+		if f.v2 {
+			edits = append(edits, xliffEdit{u.state.start, u.state.openEnd, setAttr(f.text[u.state.start:u.state.openEnd], "state", state)})
+		}
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var b strings.Builder
+	last := 0
+	for _, edit := range edits {
+		b.WriteString(f.text[last:edit.start])
+		b.WriteString(edit.text)
+		last = edit.end
+	}
+	b.WriteString(f.text[last:])
+	return b.String(), nil
+}
+
+// findElement 在 text[from:to] 中查找第一个名为 name 的元素（不处理同名元素嵌套）
+func findElement(text, name string, from, to int) (xliffElement, bool) {
+	for pos := from; pos < to; {
+		i := strings.Index(text[pos:to], "<"+name)
+		if i < 0 {
+			break
+		}
+		start := pos + i
+		pos = start + 1 + len(name)
+		if pos >= to || !strings.ContainsRune(" \t\r\n/>", rune(text[pos])) {
+			continue
+		}
+
+		gt := strings.IndexByte(text[pos:to], '>')
+		if gt < 0 {
+			break
+		}
+		openEnd := pos + gt + 1
+		if text[openEnd-2] == '/' {
+			return xliffElement{start, openEnd, openEnd, openEnd}, true
+		}
+
+		closeTag := "</" + name + ">"
+		c := strings.Index(text[openEnd:to], closeTag)
+		if c < 0 {
+			break
+		}
+		closeStart := openEnd + c
+		return xliffElement{start, openEnd, closeStart, closeStart + len(closeTag)}, true
+	}
+	return xliffElement{}, false
+}
+
+// attrPattern 匹配开始标签中指定名称的属性
+func attrPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+}
+
+// getAttr 读取开始标签中的属性值，不存在时返回空字符串
+func getAttr(tag, name string) string {
+	m := attrPattern(name).FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return m[1] + m[2]
+}
+
+// setAttr 设置开始标签中的属性值，不存在时添加到标签末尾
+func setAttr(tag, name, value string) string {
+	pattern := attrPattern(name)
+	attr := " " + name + `="` + value + `"`
+	if loc := pattern.FindStringIndex(tag); loc != nil {
+		return tag[:loc[0]] + attr + tag[loc[1]:]
+	}
+
+	end := len(tag) - 1
+	if strings.HasSuffix(tag, "/>") {
+		end--
+	}
+	for end > 0 && strings.ContainsRune(" \t\r\n", rune(tag[end-1])) {
+		end--
+	}
+	return tag[:end] + attr + tag[end:]
+}
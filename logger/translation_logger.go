@@ -13,17 +13,24 @@ import (
 
 // TranslationRecord 表示一条翻译记录
 type TranslationRecord struct {
-	Timestamp   time.Time `json:"timestamp"`
-	SourceText  string    `json:"source_text"`
-	TargetText  string    `json:"target_text"`
-	SourceLang  string    `json:"source_lang"`
-	TargetLang  string    `json:"target_lang"`
-	APIURL      string    `json:"api_url"`
-	Provider    string    `json:"provider"`
-	Model       string    `json:"model"`
-	CacheKey    string    `json:"cache_key"`
-	CacheHit    bool      `json:"cache_hit"`
-	ProcessTime float64   `json:"process_time_ms"`
+	Timestamp    time.Time `json:"timestamp"`
+	SourceText   string    `json:"source_text"`
+	TargetText   string    `json:"target_text"`
+	SourceLang   string    `json:"source_lang"`
+	TargetLang   string    `json:"target_lang"`
+	APIURL       string    `json:"api_url"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	CacheKey     string    `json:"cache_key"`
+	CacheHit     bool      `json:"cache_hit"`
+	ProcessTime  float64   `json:"process_time_ms"`
+	Attempts     int       `json:"attempts,omitempty"`      // 尝试过的模型数量（含最终应答的模型）
+	FailedModels []string  `json:"failed_models,omitempty"` // 故障转移前失败的模型
+	Reason       string    `json:"reason,omitempty"`        // 未调用模型直接返回的原因，例如 same_language
+	Prompt       string    `json:"prompt,omitempty"`        // 命中的语言对提示词规则，例如 "*->ja"；默认模板为空
+	MemoryScore  float64   `json:"memory_score,omitempty"`  // 直接采用或作为参考的翻译记忆条目中最高的相似度
+
+	GlossaryViolations []string `json:"glossary_violations,omitempty"` // 译文中未按术语表翻译的术语
 }
 
 // TranslationLogger 翻译日志记录器
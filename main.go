@@ -9,16 +9,26 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	"transbridge/api/admin"
+	"transbridge/api/deepl"
 	"transbridge/api/deeplx/translate_handler"
+	"transbridge/api/google"
+	"transbridge/api/libretranslate"
 	"transbridge/api/openai"
 	"transbridge/cache"
 	"transbridge/config"
+	"transbridge/glossary"
 	"transbridge/internal/middleware"
+	"transbridge/internal/utils"
+	"transbridge/jobs"
 	"transbridge/logger"
 	"transbridge/service"
+	"transbridge/tm"
 	"transbridge/translator"
+	"transbridge/webhook"
 )
 
 func main() {
@@ -65,16 +75,101 @@ func main() {
 	}
 
 	// 初始化模型管理器
-	modelManager, err := translator.NewModelManager(cfg.Providers)
+	modelManager, err := translator.NewModelManager(cfg.Providers, translator.HealthOptions{
+		Enabled:            cfg.CircuitBreaker.Enabled,
+		FailureThreshold:   cfg.CircuitBreaker.FailureThreshold,
+		ErrorRateThreshold: cfg.CircuitBreaker.ErrorRateThreshold,
+		MinRequests:        cfg.CircuitBreaker.MinRequests,
+		WindowSize:         cfg.CircuitBreaker.WindowSize,
+		OpenTimeout:        time.Duration(cfg.CircuitBreaker.OpenTimeout) * time.Second,
+		HalfOpenProbes:     cfg.CircuitBreaker.HalfOpenProbes,
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize model manager: %v", err)
 	}
 
+	// 加载术语表
+	glossaryStore, err := initGlossary(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load glossary: %v", err)
+	}
+
+	// 加载翻译记忆
+	memoryStore, err := initTranslationMemory(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load translation memory: %v", err)
+	}
+
+	// 多实例部署时的翻译锁，与缓存共用 Redis 连接配置
+	var translationLock *cache.RedisLock
+	if cfg.Translation.DistributedLock.Enabled {
+		switch {
+		case cacheImpl == nil:
+			log.Printf("Warning: distributed translation lock requires cache, ignoring")
+		case !cacheTypeEnabled(cfg, "redis"):
+			// 等待的实例通过共享的 Redis 缓存读取译文，只有本地缓存时只会等到超时
+			log.Printf("Warning: distributed translation lock requires redis in cache.types, ignoring")
+		default:
+			translationLock = cache.NewRedisLock(cache.NewRedisClient(cache.RedisCacheOptions{
+				Host:     cfg.Cache.Redis.Host,
+				Port:     cfg.Cache.Redis.Port,
+				Password: cfg.Cache.Redis.Password,
+				DB:       cfg.Cache.Redis.DB,
+			}))
+		}
+	}
+
+	if err := validateCacheKey(cfg.Cache.Key); err != nil {
+		log.Fatalf("Invalid cache key config: %v", err)
+	}
+
 	// 初始化翻译服务
-	translationService := service.NewTranslationService(modelManager, cacheImpl, translLogger)
+	serviceOpts := service.TranslationServiceOptions{
+		FailoverEnabled:            cfg.Failover.Enabled,
+		MaxAttempts:                cfg.Failover.MaxAttempts,
+		DetectMinConfidence:        cfg.Detection.MinConfidence,
+		LLMDetectFallback:          cfg.Detection.LLMFallback,
+		SkipSameLanguage:           cfg.Translation.SkipSameLanguage,
+		SkipSameLanguageConfidence: cfg.Translation.SameLanguageMinConfidence,
+		CacheKeyVersion:            cfg.Cache.Key.Version,
+		CacheKeyDimensions:         cfg.Cache.Key.Dimensions,
+		CacheLegacyLookup:          cfg.Cache.Key.LegacyLookup,
+		Glossary:                   glossaryStore,
+		GlossaryEnforcement:        cfg.Glossary.Enforcement,
+		Memory:                     memoryStore,
+		MemoryReuseThreshold:       cfg.TranslationMemory.ReuseThreshold,
+		MemoryReferenceThreshold:   cfg.TranslationMemory.ReferenceThreshold,
+		MemoryMaxReferences:        cfg.TranslationMemory.MaxReferences,
+		MemoryLearn:                cfg.TranslationMemory.Learn,
+		ChunkingEnabled:            cfg.Translation.Chunking.Enabled,
+		ChunkMaxTokens:             cfg.Translation.Chunking.MaxTokens,
+		ChunkContextTokens:         cfg.Translation.Chunking.ContextTokens,
+		BatchConcurrency:           cfg.Translation.BatchConcurrency,
+		SegmentBatching:            cfg.Translation.Batching.Enabled,
+		SegmentBatchSize:           cfg.Translation.Batching.MaxSegments,
+		SegmentBatchTokens:         cfg.Translation.Batching.MaxTokens,
+		LockTTL:                    time.Duration(cfg.Translation.DistributedLock.TTL) * time.Second,
+		LockWait:                   time.Duration(cfg.Translation.DistributedLock.WaitTimeout) * time.Second,
+	}
+	if translationLock != nil {
+		serviceOpts.Locker = translationLock
+	}
+	translationService := service.NewTranslationService(modelManager, cacheImpl, translLogger, serviceOpts)
+
+	// 加载按语言对选择的提示词模板
+	prompts, err := initPrompts(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load prompt templates: %v", err)
+	}
+
+	// 初始化异步翻译任务
+	jobManager, err := initJobs(cfg, translationService, prompts)
+	if err != nil {
+		log.Fatalf("Failed to initialize jobs: %v", err)
+	}
 
 	// 初始化 HTTP 服务器
-	server := setupServer(cfg, translationService, modelManager)
+	server := setupServer(cfg, translationService, modelManager, prompts, glossaryStore, memoryStore, jobManager)
 
 	// 启动服务器
 	go func() {
@@ -99,12 +194,25 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// 停止任务执行，执行中的任务恢复为排队状态
+	if jobManager != nil {
+		if err := jobManager.Close(); err != nil {
+			log.Printf("Error closing job manager: %v", err)
+		}
+	}
+
 	if translLogger != nil {
 		if err := translLogger.Close(); err != nil {
 			log.Printf("Error closing translation logger: %v", err)
 		}
 	}
 
+	if translationLock != nil {
+		if err := translationLock.Close(); err != nil {
+			log.Printf("Error closing translation lock: %v", err)
+		}
+	}
+
 	// 关闭缓存
 	if cacheImpl != nil {
 		if err := cacheImpl.Close(ctx); err != nil {
@@ -115,14 +223,15 @@ func main() {
 	log.Println("Server exited")
 }
 
-func setupServer(cfg *config.Config, translationService *service.TranslationService, modelManager *translator.ModelManager) *http.Server {
+func setupServer(cfg *config.Config, translationService *service.TranslationService, modelManager *translator.ModelManager, prompts *utils.PromptSet, glossaryStore *glossary.Store, memoryStore *tm.Store, jobManager *jobs.Manager) *http.Server {
 	// 创建路由
 	mux := http.NewServeMux()
 
 	// 创建处理器
 	translationHandler := translate_handler.NewHandler(translationService, translate_handler.HandlerConfig{
-		AuthTokens:     cfg.TransAPI.Tokens,
-		PromptTemplate: cfg.Prompt.Template,
+		AuthTokens: cfg.TransAPI.Tokens,
+		Prompts:    prompts,
+		Jobs:       jobManager,
 	})
 
 	// 注册翻译接口
@@ -144,6 +253,152 @@ func setupServer(cfg *config.Config, translationService *service.TranslationServ
 		),
 	)
 
+	mux.HandleFunc("/translate/markdown",
+		middleware.Chain(
+			translationHandler.HandleMarkdownTranslation,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	mux.HandleFunc("/translate/subtitles",
+		middleware.Chain(
+			translationHandler.HandleSubtitleTranslation,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	mux.HandleFunc("/translate/localization",
+		middleware.Chain(
+			translationHandler.HandleLocalizationTranslation,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	// 异步翻译任务接口（仅在启用时注册）
+	if jobManager != nil {
+		mux.HandleFunc("/jobs",
+			middleware.Chain(
+				translationHandler.HandleJobs,
+				middleware.Recovery,
+				middleware.Logger,
+				middleware.CORS,
+			),
+		)
+
+		mux.HandleFunc("/jobs/",
+			middleware.Chain(
+				translationHandler.HandleJob,
+				middleware.Recovery,
+				middleware.Logger,
+				middleware.CORS,
+			),
+		)
+	}
+
+	// 注册 DeepL v2 兼容接口
+	deeplHandler := deepl.NewHandler(translationService, deepl.HandlerConfig{
+		AuthTokens: cfg.TransAPI.Tokens,
+		Prompts:    prompts,
+	})
+
+	mux.HandleFunc("/v2/translate",
+		middleware.Chain(
+			deeplHandler.HandleTranslate,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	mux.HandleFunc("/v2/languages",
+		middleware.Chain(
+			deeplHandler.HandleLanguages,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	mux.HandleFunc("/v2/usage",
+		middleware.Chain(
+			deeplHandler.HandleUsage,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	// 注册 Google Translate v2 兼容接口
+	googleHandler := google.NewHandler(translationService, google.HandlerConfig{
+		AuthTokens: cfg.TransAPI.Tokens,
+		Prompts:    prompts,
+	})
+
+	mux.HandleFunc("/language/translate/v2",
+		middleware.Chain(
+			googleHandler.HandleTranslate,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	mux.HandleFunc("/language/translate/v2/languages",
+		middleware.Chain(
+			googleHandler.HandleLanguages,
+			middleware.Recovery,
+			middleware.Logger,
+			middleware.CORS,
+		),
+	)
+
+	// 如果启用了 LibreTranslate 兼容接口，在配置的前缀下注册
+	if cfg.LibreTranslate.Enabled {
+		libreHandler := libretranslate.NewHandler(translationService, libretranslate.HandlerConfig{
+			AuthTokens:     cfg.TransAPI.Tokens,
+			Prompts:        prompts,
+			AllowAnonymous: cfg.LibreTranslate.AllowAnonymous,
+		})
+
+		librePath := strings.TrimSuffix(cfg.LibreTranslate.Path, "/")
+		if cfg.LibreTranslate.Path == "" {
+			librePath = "/libre"
+		}
+
+		mux.HandleFunc(librePath+"/translate",
+			middleware.Chain(
+				libreHandler.HandleTranslate,
+				middleware.Recovery,
+				middleware.Logger,
+				middleware.CORS,
+			),
+		)
+
+		mux.HandleFunc(librePath+"/detect",
+			middleware.Chain(
+				libreHandler.HandleDetect,
+				middleware.Recovery,
+				middleware.Logger,
+				middleware.CORS,
+			),
+		)
+
+		mux.HandleFunc(librePath+"/languages",
+			middleware.Chain(
+				libreHandler.HandleLanguages,
+				middleware.Recovery,
+				middleware.Logger,
+				middleware.CORS,
+			),
+		)
+	}
+
 	// 如果启用了 OpenAI 兼容接口，注册相关路由
 	if cfg.OpenAI.CompatibleAPI.Enabled {
 		openaiHandler := openai.NewOpenAIHandler(modelManager, cfg.OpenAI.CompatibleAPI.AuthTokens)
@@ -172,6 +427,70 @@ func setupServer(cfg *config.Config, translationService *service.TranslationServ
 		)
 	}
 
+	// 管理接口
+	if cfg.Admin.Enabled {
+		adminHandler := admin.NewAdminHandler(modelManager, admin.HandlerConfig{
+			AuthTokens:         cfg.Admin.Tokens,
+			TranslationService: translationService,
+			Prompts:            prompts,
+			Glossary:           glossaryStore,
+			Memory:             memoryStore,
+		})
+
+		adminPath := cfg.Admin.Path
+		if adminPath == "" {
+			adminPath = "/admin"
+		}
+
+		mux.HandleFunc(adminPath+"/health/models",
+			middleware.Chain(
+				adminHandler.HandleModelHealth,
+				middleware.Recovery,
+				middleware.Logger,
+			),
+		)
+
+		// 术语表管理接口（仅在启用术语表时注册）
+		if glossaryStore != nil {
+			mux.HandleFunc(adminPath+"/glossary",
+				middleware.Chain(
+					adminHandler.HandleGlossary,
+					middleware.Recovery,
+					middleware.Logger,
+				),
+			)
+		}
+
+		// 翻译记忆管理接口（仅在启用翻译记忆时注册）
+		if memoryStore != nil {
+			mux.HandleFunc(adminPath+"/memory",
+				middleware.Chain(
+					adminHandler.HandleMemory,
+					middleware.Recovery,
+					middleware.Logger,
+				),
+			)
+		}
+
+		// 缓存管理接口（仅在启用缓存时注册）
+		if cfg.Cache.Enabled {
+			mux.HandleFunc(adminPath+"/cache",
+				middleware.Chain(
+					adminHandler.HandleCache,
+					middleware.Recovery,
+					middleware.Logger,
+				),
+			)
+			mux.HandleFunc(adminPath+"/cache/stats",
+				middleware.Chain(
+					adminHandler.HandleCacheStats,
+					middleware.Recovery,
+					middleware.Logger,
+				),
+			)
+		}
+	}
+
 	// 健康检查
 	mux.HandleFunc("/health",
 		middleware.Chain(
@@ -187,10 +506,168 @@ func setupServer(cfg *config.Config, translationService *service.TranslationServ
 	return &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
 		Handler:      mux,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  secondsOrDefault(cfg.Server.ReadTimeout, 15),
+		WriteTimeout: secondsOrDefault(cfg.Server.WriteTimeout, 15),
+		IdleTimeout:  secondsOrDefault(cfg.Server.IdleTimeout, 60),
+	}
+}
+
+// secondsOrDefault 将以秒为单位的配置转换为 time.Duration，未配置时使用默认值
+func secondsOrDefault(seconds, def int) time.Duration {
+	if seconds <= 0 {
+		seconds = def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// initPrompts 根据配置创建提示词模板集合，prompt.pairs 中的规则按语言对覆盖默认模板
+func initPrompts(cfg *config.Config) (*utils.PromptSet, error) {
+	prompts := utils.NewPromptSet(utils.PromptTemplate{
+		System:   cfg.Prompt.System,
+		Template: cfg.Prompt.Template,
+	})
+	for _, pair := range cfg.Prompt.Pairs {
+		if err := prompts.AddRule(pair.Pair, utils.PromptTemplate{
+			System:   pair.System,
+			Template: pair.Template,
+		}); err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded prompt template for %s", pair.Pair)
+	}
+	return prompts, nil
+}
+
+// initGlossary 创建术语表并加载配置中的术语文件，未启用时返回 nil
+func initGlossary(cfg *config.Config) (*glossary.Store, error) {
+	if !cfg.Glossary.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Glossary.Enforcement {
+	case "", glossary.EnforcementOff, glossary.EnforcementFlag, glossary.EnforcementRepair:
+	default:
+		return nil, fmt.Errorf("unknown glossary enforcement %q", cfg.Glossary.Enforcement)
 	}
+
+	store := glossary.NewStore()
+	for _, file := range cfg.Glossary.Files {
+		count, err := store.LoadFile(file.Path, file.SourceLang, file.TargetLang)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded %d glossary terms for %s->%s from %s", count, file.SourceLang, file.TargetLang, file.Path)
+	}
+	return store, nil
+}
+
+// validateCacheKey 校验缓存键版本与指纹维度
+func validateCacheKey(cfg config.CacheKeyConfig) error {
+	switch cfg.Version {
+	case "", service.CacheKeyV1, service.CacheKeyV2:
+	default:
+		return fmt.Errorf("unknown cache key version %q", cfg.Version)
+	}
+
+	for _, dim := range cfg.Dimensions {
+		known := false
+		for _, d := range service.CacheKeyDimensions {
+			if strings.EqualFold(strings.TrimSpace(dim), d) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown cache key dimension %q", dim)
+		}
+	}
+	return nil
+}
+
+// initTranslationMemory 创建翻译记忆并加载配置中的 TMX 文件，未启用时返回 nil
+func initTranslationMemory(cfg *config.Config) (*tm.Store, error) {
+	if !cfg.TranslationMemory.Enabled {
+		return nil, nil
+	}
+
+	store := tm.NewStore(cfg.TranslationMemory.MaxLearned)
+	for _, file := range cfg.TranslationMemory.Files {
+		count, err := store.LoadFile(file.Path, file.SourceLang, file.TargetLang)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded %d translation memory entries from %s", count, file.Path)
+	}
+	return store, nil
+}
+
+// initJobs 创建并启动异步翻译任务管理器，未启用时返回 nil
+func initJobs(cfg *config.Config, translationService *service.TranslationService, prompts *utils.PromptSet) (*jobs.Manager, error) {
+	if !cfg.Jobs.Enabled {
+		return nil, nil
+	}
+
+	retention := 24 * time.Hour // 默认1天
+	if duration, ok := cfg.Jobs.Retention.Duration(); ok && duration > 0 {
+		retention = duration
+	}
+
+	var store jobs.Store
+	switch cfg.Jobs.Store {
+	case "", "memory":
+		store = jobs.NewMemoryStore(retention)
+	case "redis":
+		// 与缓存共用 Redis 连接配置
+		store = jobs.NewRedisStore(cache.NewRedisClient(cache.RedisCacheOptions{
+			Host:     cfg.Cache.Redis.Host,
+			Port:     cfg.Cache.Redis.Port,
+			Password: cfg.Cache.Redis.Password,
+			DB:       cfg.Cache.Redis.DB,
+		}), retention)
+	default:
+		return nil, fmt.Errorf("unsupported job store: %s", cfg.Jobs.Store)
+	}
+
+	// 回调签名密钥按任务归属索引，未单独配置的令牌使用令牌本身
+	secrets := make(map[string]string, len(cfg.TransAPI.Tokens))
+	for _, token := range cfg.TransAPI.Tokens {
+		secret := cfg.Jobs.Webhook.Secrets[token]
+		if secret == "" {
+			secret = token
+		}
+		secrets[jobs.Owner(token)] = secret
+	}
+
+	manager := jobs.NewManager(translationService, prompts, store, jobs.Options{
+		Workers:     cfg.Jobs.Workers,
+		QueueSize:   cfg.Jobs.QueueSize,
+		Concurrency: cfg.Jobs.Concurrency,
+		Retention:   retention,
+		LeaseTTL:    time.Duration(cfg.Jobs.LeaseTTL) * time.Second,
+		Webhook: webhook.NewClient(webhook.Options{
+			Timeout:     time.Duration(cfg.Jobs.Webhook.Timeout) * time.Second,
+			MaxAttempts: cfg.Jobs.Webhook.MaxAttempts,
+			Backoff:     time.Duration(cfg.Jobs.Webhook.Backoff) * time.Second,
+			Allowlist:   cfg.Jobs.Webhook.Allowlist,
+		}),
+		Secrets: secrets,
+	})
+	if err := manager.Start(context.Background()); err != nil {
+		store.Close()
+		return nil, err
+	}
+	log.Printf("Translation jobs enabled (store: %s)", cfg.Jobs.Store)
+	return manager, nil
+}
+
+// cacheTypeEnabled 判断 cache.types 中是否包含指定的缓存类型
+func cacheTypeEnabled(cfg *config.Config, cacheType string) bool {
+	for _, t := range cfg.Cache.Types {
+		if t == cacheType {
+			return true
+		}
+	}
+	return false
 }
 
 // main.go 中的缓存初始化函数
@@ -217,13 +694,25 @@ func initCache(cfg *config.Config) (cache.Cache, error) {
 				maxSize = 10000 // 默认10000条
 			}
 
+			policy := strings.ToLower(cfg.Cache.Memory.Policy)
+			switch policy {
+			case "":
+				policy = cache.PolicyLRU
+			case cache.PolicyLRU, cache.PolicyTinyLFU:
+			default:
+				return nil, fmt.Errorf("unsupported memory cache policy: %s", cfg.Cache.Memory.Policy)
+			}
+
 			memoryCacheOptions := cache.MemoryCacheOptions{
 				MaxSize:    maxSize,
+				MaxBytes:   int64(cfg.Cache.Memory.MaxMemory) << 20,
+				Policy:     policy,
 				DefaultTTL: ttl,
 				Permanent:  isPermanent,
 			}
 
 			caches = append(caches, cache.NewMemoryCache(memoryCacheOptions))
+			log.Printf("Memory cache enabled (policy: %s, max entries: %d, max memory: %dMB)", policy, maxSize, cfg.Cache.Memory.MaxMemory)
 
 		case "redis":
 			// 解析Redis缓存TTL
@@ -249,6 +738,45 @@ func initCache(cfg *config.Config) (cache.Cache, error) {
 
 			caches = append(caches, cache.NewRedisCache(redisCacheOptions))
 
+		case "disk":
+			// 磁盘缓存默认永久保存
+			ttl := time.Duration(0)
+			isPermanent := true
+
+			if duration, ok := cfg.Cache.Disk.TTL.Duration(); ok && duration > 0 {
+				ttl = duration
+				isPermanent = false
+			}
+
+			compactInterval := time.Hour // 默认1小时
+			if duration, ok := cfg.Cache.Disk.CompactInterval.Duration(); ok {
+				if duration < 0 {
+					compactInterval = 0
+				} else {
+					compactInterval = duration
+				}
+			}
+
+			path := cfg.Cache.Disk.Path
+			if path == "" {
+				path = "data/cache.db"
+			}
+
+			diskCache, err := cache.NewDiskCache(cache.DiskCacheOptions{
+				Path:            path,
+				DefaultTTL:      ttl,
+				Permanent:       isPermanent,
+				CompactInterval: compactInterval,
+			})
+			if err != nil {
+				for _, c := range caches {
+					c.Close(context.Background())
+				}
+				return nil, err
+			}
+			caches = append(caches, diskCache)
+			log.Printf("Disk cache enabled (path: %s)", path)
+
 		default:
 			return nil, fmt.Errorf("unsupported cache type: %s", cacheType)
 		}
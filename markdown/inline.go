@@ -0,0 +1,210 @@
+// markdown/inline.go
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"transbridge/markup"
+)
+
+// 行内片段对应的占位符名称；同名的开始/结束片段按嵌套关系配对
+const (
+	linkTag  = "link"
+	imageTag = "image"
+)
+
+var (
+	// autolinkPattern 尖括号包裹的链接或邮箱，例如 <https://example.com>
+	autolinkPattern = regexp.MustCompile(`^<(?:[a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s]*|[^<>\s@]+@[^<>\s@]+)>`)
+	// inlineHTMLPattern 行内 HTML 标签或注释
+	inlineHTMLPattern = regexp.MustCompile(`^(?:<!--.*?-->|</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[^<>]*)?/?>)`)
+	// bareURLPattern 正文中的裸链接
+	bareURLPattern = regexp.MustCompile(`^(?:https?|ftp)://[^\s<>]+`)
+)
+
+// ParseInline 解析一段 Markdown 行内文本，返回可保护行内标记的文档：
+// 链接与图片的文字照常翻译、目标地址整体保留；行内代码、自动链接、裸链接与行内 HTML 替换为自闭合占位符
+func ParseInline(text string) *markup.Document {
+	return markup.NewDocument(inlineTokens(text), false)
+}
+
+// inlineTokens 将行内文本切分为文本与标记片段
+func inlineTokens(s string) []markup.Token {
+	var tokens []markup.Token
+	textStart := 0
+	flushText := func(end int) {
+		if end > textStart {
+			tokens = append(tokens, markup.Token{Type: markup.TextToken, Raw: s[textStart:end]})
+		}
+	}
+	emit := func(start, end int, toks ...markup.Token) int {
+		flushText(start)
+		tokens = append(tokens, toks...)
+		textStart = end
+		return end
+	}
+
+	i := 0
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			// 转义字符按普通文本处理
+			i += 2
+		case c == '`':
+			if end, ok := codeSpanEnd(s, i); ok {
+				i = emit(i, end, opaque(s[i:end]))
+				continue
+			}
+			i += backtickRun(s, i)
+		case c == '!' && strings.HasPrefix(s[i+1:], "["):
+			if labelEnd, end, ok := linkEnd(s, i+1); ok {
+				i = emit(i, end, linkTokens(s, i, i+2, labelEnd, end, imageTag)...)
+				continue
+			}
+			i++
+		case c == '[':
+			if labelEnd, end, ok := linkEnd(s, i); ok {
+				i = emit(i, end, linkTokens(s, i, i+1, labelEnd, end, linkTag)...)
+				continue
+			}
+			i++
+		case c == '<':
+			if m := autolinkPattern.FindString(s[i:]); m != "" {
+				i = emit(i, i+len(m), opaque(m))
+				continue
+			}
+			if m := inlineHTMLPattern.FindString(s[i:]); m != "" {
+				i = emit(i, i+len(m), opaque(m))
+				continue
+			}
+			i++
+		case c == 'h' || c == 'f':
+			if (i == 0 || !isWordByte(s[i-1])) && bareURLPattern.MatchString(s[i:]) {
+				url := trimURLPunctuation(bareURLPattern.FindString(s[i:]))
+				i = emit(i, i+len(url), opaque(url))
+				continue
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	flushText(len(s))
+	return tokens
+}
+
+// linkTokens 生成链接或图片的片段：文字部分继续按行内文本切分，"](目标)" 部分整体保留
+// 文字为空时整个链接作为自闭合占位符
+func linkTokens(s string, start, labelStart, labelEnd, end int, name string) []markup.Token {
+	label := s[labelStart:labelEnd]
+	if strings.TrimSpace(label) == "" {
+		return []markup.Token{opaque(s[start:end])}
+	}
+
+	toks := []markup.Token{{Type: markup.StartTagToken, Raw: s[start:labelStart], Name: name}}
+	toks = append(toks, inlineTokens(label)...)
+	return append(toks, markup.Token{Type: markup.EndTagToken, Raw: s[labelEnd:end], Name: name})
+}
+
+// linkEnd 从 s[start]（"["）处解析链接，返回文字结束位置（"]" 所在位置）与整个链接的结束位置
+// 支持 [文字](目标 "标题")、[文字][引用] 与 [文字][] 三种形式
+func linkEnd(s string, start int) (int, int, bool) {
+	depth := 0
+	labelEnd := -1
+	for i := start; i < len(s) && labelEnd < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			if end, ok := codeSpanEnd(s, i); ok {
+				i = end - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				labelEnd = i
+			}
+		}
+	}
+	if labelEnd < 0 || labelEnd+1 >= len(s) {
+		return 0, 0, false
+	}
+
+	switch s[labelEnd+1] {
+	case '(':
+		// 目标中允许成对的括号
+		depth := 0
+		for i := labelEnd + 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return labelEnd, i + 1, true
+				}
+			case '\n':
+				return 0, 0, false
+			}
+		}
+	case '[':
+		if idx := strings.IndexByte(s[labelEnd+2:], ']'); idx >= 0 && !strings.Contains(s[labelEnd+2:labelEnd+2+idx], "[") {
+			return labelEnd, labelEnd + 2 + idx + 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// codeSpanEnd 从 s[start] 处的反引号解析行内代码，返回结束位置；找不到等长的结束反引号时返回 false
+func codeSpanEnd(s string, start int) (int, bool) {
+	n := backtickRun(s, start)
+	for i := start + n; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := backtickRun(s, i)
+		if run == n {
+			return i + run, true
+		}
+		i += run
+	}
+	return 0, false
+}
+
+func backtickRun(s string, start int) int {
+	n := 0
+	for start+n < len(s) && s[start+n] == '`' {
+		n++
+	}
+	return n
+}
+
+// trimURLPunctuation 去掉裸链接末尾的标点，例如句末的 "." 与不成对的 ")"
+func trimURLPunctuation(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?*_~'\"", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+func opaque(raw string) markup.Token {
+	return markup.Token{Type: markup.OpaqueToken, Raw: raw}
+}
@@ -0,0 +1,678 @@
+// markdown/markdown.go
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultFrontMatterKeys 默认翻译的 front matter 字段
+var DefaultFrontMatterKeys = []string{"title", "description", "summary"}
+
+// Options 解析选项
+type Options struct {
+	FrontMatterKeys []string // 需要翻译取值的 YAML front matter 字段，为空时使用 DefaultFrontMatterKeys
+}
+
+func (o Options) frontMatterKeys() map[string]bool {
+	keys := o.FrontMatterKeys
+	if len(keys) == 0 {
+		keys = DefaultFrontMatterKeys
+	}
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(strings.TrimSpace(key))] = true
+	}
+	return set
+}
+
+// Segment 需要翻译的正文片段
+type Segment struct {
+	Text string // 片段原文，段落跨行时以 "\n" 连接
+	HTML bool   // HTML 块，翻译时按 HTML 保护标签；否则按 Markdown 行内文本处理（见 ParseInline）
+}
+
+// segmentKind 决定译文写回时的格式
+type segmentKind int
+
+const (
+	blockSegment   segmentKind = iota // 段落、列表项等，译文换行时按原缩进续行
+	headingSegment                    // ATX 标题，译文必须在同一行内
+	cellSegment                       // 表格单元格，译文必须在同一行内
+	yamlSegment                       // front matter 字段值
+)
+
+// part 文档按顺序拆分成的片段：原样输出的文本或需要翻译的正文
+type part struct {
+	literal string
+	segment int // 正文片段下标，-1 表示原样输出 literal
+	kind    segmentKind
+	indent  string // blockSegment 译文续行的前缀
+	quote   byte   // yamlSegment 原值使用的引号，0 表示无引号
+}
+
+// Document 解析后的 Markdown 文档
+// 代码块、front matter 的键、链接引用定义、表格分隔行等原样保留，只有正文片段需要翻译，
+// 翻译后按原结构重新拼接（见 Render）
+type Document struct {
+	parts    []part
+	segments []Segment
+	crlf     bool
+}
+
+// Parse 解析 Markdown 文档
+// 识别 front matter、标题、段落、列表、引用、表格、HTML 块、围栏代码块与缩进代码块；列表与引用中的内容递归解析
+func Parse(text string, opts Options) *Document {
+	d := &Document{crlf: strings.Contains(text, "\r\n")}
+	if d.crlf {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	lines := strings.Split(text, "\n")
+
+	start := 0
+	if end, yaml, ok := frontMatterEnd(lines); ok {
+		if yaml {
+			d.frontMatter(lines[:end+1], opts.frontMatterKeys())
+		} else {
+			d.literalLines(lines[:end+1])
+		}
+		start = end + 1
+		if start < len(lines) {
+			d.addLiteral("\n")
+		}
+	}
+	d.blocks(lines[start:])
+	return d
+}
+
+// Segments 返回需要翻译的正文片段
+func (d *Document) Segments() []Segment {
+	return d.segments
+}
+
+// Render 用译文替换正文片段并按原结构输出文档，translations 与 Segments 一一对应，缺少的片段保留原文
+func (d *Document) Render(translations []string) string {
+	var b strings.Builder
+	for _, p := range d.parts {
+		if p.segment < 0 {
+			b.WriteString(p.literal)
+			continue
+		}
+
+		text := d.segments[p.segment].Text
+		if p.segment < len(translations) {
+			text = strings.TrimSpace(translations[p.segment])
+		}
+		switch p.kind {
+		case headingSegment:
+			b.WriteString(singleLine(text))
+		case cellSegment:
+			b.WriteString(escapePipes(singleLine(text)))
+		case yamlSegment:
+			b.WriteString(yamlValue(singleLine(text), p.quote))
+		default:
+			b.WriteString(strings.ReplaceAll(text, "\n", "\n"+p.indent))
+		}
+	}
+
+	if d.crlf {
+		return strings.ReplaceAll(b.String(), "\n", "\r\n")
+	}
+	return b.String()
+}
+
+func (d *Document) addLiteral(s string) {
+	if s == "" {
+		return
+	}
+	if n := len(d.parts); n > 0 && d.parts[n-1].segment < 0 {
+		d.parts[n-1].literal += s
+		return
+	}
+	d.parts = append(d.parts, part{literal: s, segment: -1})
+}
+
+func (d *Document) addSegment(seg Segment, p part) {
+	p.segment = len(d.segments)
+	d.segments = append(d.segments, seg)
+	d.parts = append(d.parts, p)
+}
+
+func (d *Document) literalLines(lines []string) {
+	d.addLiteral(strings.Join(lines, "\n"))
+}
+
+var (
+	fencePattern         = regexp.MustCompile("^[ \t]*(`{3,}|~{3,})")
+	atxHeadingPattern    = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+|$)`)
+	thematicBreakPattern = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	quotePattern         = regexp.MustCompile(`^ {0,3}> ?`)
+	listItemPattern      = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	taskPattern          = regexp.MustCompile(`^\[[ xX]\][ \t]+`)
+	setextPattern        = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	tableDelimPattern    = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	linkRefDefPattern    = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*\S`)
+	htmlBlockPattern     = regexp.MustCompile(`^ {0,3}(?:<!--|<\?|<![A-Za-z]|<!\[CDATA\[|</?([A-Za-z][A-Za-z0-9-]*)(?:[ \t/>]|$))`)
+	htmlTagLinePattern   = regexp.MustCompile(`^ {0,3}(?:<[A-Za-z][A-Za-z0-9-]*(?:\s+[^<>]*)?/?>|</[A-Za-z][A-Za-z0-9-]*\s*>)[ \t]*$`)
+)
+
+// htmlBlockTags 可以开始 HTML 块的块级标签
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "center": true,
+	"details": true, "dialog": true, "dd": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "html": true, "iframe": true, "li": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true, "script": true, "section": true,
+	"style": true, "summary": true, "table": true, "tbody": true, "td": true, "textarea": true, "tfoot": true,
+	"th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// blocks 逐块解析 lines，块之间以 "\n" 连接
+func (d *Document) blocks(lines []string) {
+	for i := 0; i < len(lines); {
+		if i > 0 {
+			d.addLiteral("\n")
+		}
+
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			d.addLiteral(line)
+			i++
+		case indentWidth(line) >= 4:
+			i = d.indentedCode(lines, i)
+		case fencePattern.MatchString(line):
+			i = d.fencedCode(lines, i)
+		case atxHeadingPattern.MatchString(line):
+			d.heading(line)
+			i++
+		case thematicBreakPattern.MatchString(line), linkRefDefPattern.MatchString(line):
+			d.addLiteral(line)
+			i++
+		case quotePattern.MatchString(line):
+			i = d.blockquote(lines, i)
+		case listItemPattern.MatchString(line):
+			i = d.listItem(lines, i)
+		case isHTMLBlockStart(line):
+			i = d.htmlBlock(lines, i)
+		case isTableStart(lines, i):
+			i = d.table(lines, i)
+		default:
+			i = d.paragraph(lines, i)
+		}
+	}
+}
+
+// indentedCode 缩进代码块，末尾的空行不计入代码块
+func (d *Document) indentedCode(lines []string, i int) int {
+	end := i + 1
+	for j := i + 1; j < len(lines); j++ {
+		if isBlank(lines[j]) {
+			continue
+		}
+		if indentWidth(lines[j]) < 4 {
+			break
+		}
+		end = j + 1
+	}
+	d.literalLines(lines[i:end])
+	return end
+}
+
+// fencedCode 围栏代码块，未闭合时延续到文档末尾
+func (d *Document) fencedCode(lines []string, i int) int {
+	fence := fencePattern.FindStringSubmatch(lines[i])[1]
+	end := len(lines)
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			end = j + 1
+			break
+		}
+	}
+	d.literalLines(lines[i:end])
+	return end
+}
+
+// heading ATX 标题，保留 "#" 标记与结尾的闭合 "#"
+func (d *Document) heading(line string) {
+	marker := atxHeadingPattern.FindString(line)
+	content := line[len(marker):]
+
+	text := strings.TrimRight(content, " \t")
+	if closing := strings.TrimRight(text, "#"); closing != text && (closing == "" || strings.HasSuffix(closing, " ") || strings.HasSuffix(closing, "\t")) {
+		text = strings.TrimRight(closing, " \t")
+	}
+
+	d.addLiteral(marker)
+	if text != "" {
+		d.addSegment(Segment{Text: text}, part{kind: headingSegment})
+	}
+	d.addLiteral(content[len(text):])
+}
+
+// paragraph 段落，遇到空行或其它块开始时结束；紧随其后的 "==="/"---" 为 Setext 标题下划线
+func (d *Document) paragraph(lines []string, i int) int {
+	end := i + 1
+	for end < len(lines) && !isBlank(lines[end]) && !setextPattern.MatchString(lines[end]) &&
+		!interrupts(lines[end]) && !isTableStart(lines, end) {
+		end++
+	}
+
+	contents := make([]string, 0, end-i)
+	for _, line := range lines[i:end] {
+		contents = append(contents, strings.TrimLeft(line, " \t"))
+	}
+	indent := ""
+	if end-i > 1 {
+		indent = leadingSpace(lines[i+1])
+	}
+	d.addTrimmedSegment(leadingSpace(lines[i]), strings.Join(contents, "\n"), part{indent: indent}, false)
+
+	if end < len(lines) && setextPattern.MatchString(lines[end]) {
+		d.addLiteral("\n" + lines[end])
+		end++
+	}
+	return end
+}
+
+// addTrimmedSegment 添加正文片段，首尾空白作为原样输出的文本
+func (d *Document) addTrimmedSegment(lead, text string, p part, html bool) {
+	d.addLiteral(lead)
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		d.addLiteral(text)
+		return
+	}
+	start := strings.Index(text, trimmed)
+	d.addLiteral(text[:start])
+	d.addSegment(Segment{Text: trimmed, HTML: html}, p)
+	d.addLiteral(text[start+len(trimmed):])
+}
+
+// blockquote 引用块，内容去掉 ">" 前缀后递归解析；允许段落的惰性续行
+func (d *Document) blockquote(lines []string, i int) int {
+	var prefixes, contents []string
+	j := i
+	for ; j < len(lines); j++ {
+		line := lines[j]
+		if prefix := quotePattern.FindString(line); prefix != "" {
+			prefixes = append(prefixes, prefix)
+			contents = append(contents, line[len(prefix):])
+			continue
+		}
+		if j > i && !isBlank(line) && !isBlank(contents[len(contents)-1]) && !interrupts(line) {
+			prefixes = append(prefixes, "")
+			contents = append(contents, line)
+			continue
+		}
+		break
+	}
+
+	d.addNested(contents, prefixes, strings.TrimLeft(prefixes[0], " "))
+	return j
+}
+
+// listItem 列表项，内容去掉列表标记（与对应宽度的缩进）后递归解析，嵌套列表、代码块随之处理
+func (d *Document) listItem(lines []string, i int) int {
+	line := lines[i]
+	m := listItemPattern.FindStringSubmatch(line)
+	width := len(m[0])
+	if len(m[3]) > 4 {
+		// 标记后超过 4 个空格时内容为缩进代码块，续行宽度按一个空格计算
+		width = len(m[1]) + len(m[2]) + 1
+	}
+
+	first := line[:width]
+	if task := taskPattern.FindString(line[width:]); task != "" {
+		first += task
+	}
+	prefixes := []string{first}
+	contents := []string{line[len(first):]}
+
+	j := i + 1
+	for j < len(lines) {
+		line := lines[j]
+		switch {
+		case isBlank(line):
+			// 空行之后仍有缩进足够的内容时属于同一列表项
+			k := j
+			for k < len(lines) && isBlank(lines[k]) {
+				k++
+			}
+			if k == len(lines) || indentWidth(lines[k]) < width {
+				d.addNested(contents, prefixes, strings.Repeat(" ", width))
+				return j
+			}
+			for ; j < k; j++ {
+				prefixes = append(prefixes, lines[j])
+				contents = append(contents, "")
+			}
+		case indentWidth(line) >= width:
+			cut := indentBytes(line, width)
+			prefixes = append(prefixes, line[:cut])
+			contents = append(contents, line[cut:])
+			j++
+		case !isBlank(contents[len(contents)-1]) && !interrupts(line) && !setextPattern.MatchString(line):
+			prefixes = append(prefixes, "")
+			contents = append(contents, line)
+			j++
+		default:
+			d.addNested(contents, prefixes, strings.Repeat(" ", width))
+			return j
+		}
+	}
+	d.addNested(contents, prefixes, strings.Repeat(" ", width))
+	return j
+}
+
+// addNested 递归解析列表项或引用块的内容，并在每行前加回原始前缀；cont 为译文续行使用的前缀
+func (d *Document) addNested(contents, prefixes []string, cont string) {
+	sub := &Document{}
+	sub.blocks(contents)
+
+	line := 0
+	d.addLiteral(prefixes[0])
+	for _, p := range sub.parts {
+		if p.segment < 0 {
+			for k, piece := range strings.Split(p.literal, "\n") {
+				if k > 0 {
+					line++
+					d.addLiteral("\n")
+					if line < len(prefixes) {
+						d.addLiteral(prefixes[line])
+					}
+				}
+				d.addLiteral(piece)
+			}
+			continue
+		}
+
+		seg := sub.segments[p.segment]
+		p.indent = cont + p.indent
+		d.addSegment(seg, p)
+		line += strings.Count(seg.Text, "\n")
+	}
+}
+
+// htmlBlock HTML 块，整体作为一个片段按 HTML 翻译
+// 注释与 <script>/<style>/<pre>/<textarea> 延续到结束标记所在行，其它 HTML 块在空行处结束
+func (d *Document) htmlBlock(lines []string, i int) int {
+	terminator := ""
+	trimmed := strings.ToLower(strings.TrimSpace(lines[i]))
+	switch {
+	case strings.HasPrefix(trimmed, "<!--"):
+		terminator = "-->"
+	default:
+		for _, tag := range []string{"script", "style", "pre", "textarea"} {
+			if strings.HasPrefix(trimmed, "<"+tag) {
+				terminator = "</" + tag + ">"
+			}
+		}
+	}
+
+	end := len(lines)
+	for j := i; j < len(lines); j++ {
+		if terminator != "" && strings.Contains(strings.ToLower(lines[j]), terminator) {
+			end = j + 1
+			break
+		}
+		if terminator == "" && j > i && isBlank(lines[j]) {
+			end = j
+			break
+		}
+	}
+
+	d.addTrimmedSegment("", strings.Join(lines[i:end], "\n"), part{}, true)
+	return end
+}
+
+// table GFM 表格，逐个单元格翻译，分隔行原样保留
+func (d *Document) table(lines []string, i int) int {
+	end := i + 2
+	for end < len(lines) && !isBlank(lines[end]) && strings.Contains(lines[end], "|") {
+		end++
+	}
+
+	for j := i; j < end; j++ {
+		if j > i {
+			d.addLiteral("\n")
+		}
+		if j == i+1 {
+			d.addLiteral(lines[j])
+			continue
+		}
+		d.tableRow(lines[j])
+	}
+	return end
+}
+
+// tableRow 按未转义、不在行内代码中的 "|" 拆分单元格
+func (d *Document) tableRow(line string) {
+	start := 0
+	for k := 0; k <= len(line); k++ {
+		if k < len(line) {
+			switch line[k] {
+			case '\\':
+				k++
+				continue
+			case '`':
+				if end, ok := codeSpanEnd(line, k); ok {
+					k = end - 1
+				}
+				continue
+			case '|':
+			default:
+				continue
+			}
+		}
+
+		d.addTrimmedSegment("", line[start:k], part{kind: cellSegment}, false)
+		if k < len(line) {
+			d.addLiteral("|")
+		}
+		start = k + 1
+	}
+}
+
+// frontMatterField front matter 中顶层的 "key: value" 行
+var frontMatterField = regexp.MustCompile(`^([A-Za-z0-9_-]+)([ \t]*:[ \t]+)(.*?)([ \t]*)$`)
+
+// frontMatter 只翻译指定字段的字符串取值，其它行原样保留
+func (d *Document) frontMatter(lines []string, keys map[string]bool) {
+	for j, line := range lines {
+		if j > 0 {
+			d.addLiteral("\n")
+		}
+
+		m := frontMatterField.FindStringSubmatch(line)
+		if m == nil || !keys[strings.ToLower(m[1])] {
+			d.addLiteral(line)
+			continue
+		}
+		value, quote, rest, ok := yamlScalar(m[3])
+		if !ok || strings.TrimSpace(value) == "" {
+			d.addLiteral(line)
+			continue
+		}
+
+		d.addLiteral(m[1] + m[2])
+		d.addSegment(Segment{Text: value}, part{kind: yamlSegment, quote: quote})
+		d.addLiteral(rest + m[4])
+	}
+}
+
+// frontMatterEnd 查找文档开头 front matter 的结束行；"---" 为 YAML，"+++" 为 TOML
+func frontMatterEnd(lines []string) (int, bool, bool) {
+	if len(lines) == 0 {
+		return 0, false, false
+	}
+
+	open := strings.TrimRight(lines[0], " \t")
+	if open != "---" && open != "+++" {
+		return 0, false, false
+	}
+	for j := 1; j < len(lines); j++ {
+		line := strings.TrimRight(lines[j], " \t")
+		if line == open || (open == "---" && line == "...") {
+			return j, open == "---", true
+		}
+	}
+	return 0, false, false
+}
+
+// yamlScalar 解析单行 YAML 字符串，返回去掉引号后的值、引号与值之后的注释
+// 块标量、流式集合、锚点等不支持的写法返回 false
+func yamlScalar(v string) (string, byte, string, bool) {
+	if v == "" {
+		return "", 0, "", false
+	}
+
+	switch v[0] {
+	case '"':
+		if len(v) < 2 || v[len(v)-1] != '"' {
+			return "", 0, "", false
+		}
+		inner := v[1 : len(v)-1]
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(inner), '"', "", true
+	case '\'':
+		if len(v) < 2 || v[len(v)-1] != '\'' {
+			return "", 0, "", false
+		}
+		return strings.ReplaceAll(v[1:len(v)-1], "''", "'"), '\'', "", true
+	case '[', '{', '|', '>', '&', '*', '!', '%', '@', '`':
+		return "", 0, "", false
+	}
+
+	if idx := strings.Index(v, " #"); idx >= 0 {
+		value := strings.TrimRight(v[:idx], " \t")
+		return value, 0, v[len(value):], true
+	}
+	return v, 0, "", true
+}
+
+// yamlValue 按原引号输出 front matter 取值；原值无引号但译文含特殊字符时改用双引号
+func yamlValue(text string, quote byte) string {
+	switch quote {
+	case '\'':
+		return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+	case '"':
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+	}
+
+	if text == "" || strings.ContainsAny(text[:1], "-?:,[]{}#&*!|>'\"%@` \t") ||
+		strings.Contains(text, ": ") || strings.Contains(text, " #") || strings.HasSuffix(text, ":") {
+		return yamlValue(text, '"')
+	}
+	return text
+}
+
+// isTableStart lines[i] 是否为表格表头（下一行为分隔行）
+func isTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) && strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") && tableDelimPattern.MatchString(lines[i+1])
+}
+
+// isHTMLBlockStart 块级标签、注释等开始 HTML 块；其它标签只有独占一行时才开始 HTML 块
+func isHTMLBlockStart(line string) bool {
+	m := htmlBlockPattern.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	if m[1] == "" || htmlBlockTags[strings.ToLower(m[1])] {
+		return true
+	}
+	return htmlTagLinePattern.MatchString(line)
+}
+
+// interrupts line 是否会打断段落（开始新的块）
+func interrupts(line string) bool {
+	if fencePattern.MatchString(line) || atxHeadingPattern.MatchString(line) ||
+		thematicBreakPattern.MatchString(line) || quotePattern.MatchString(line) {
+		return true
+	}
+	if m := htmlBlockPattern.FindStringSubmatch(line); m != nil && (m[1] == "" || htmlBlockTags[strings.ToLower(m[1])]) {
+		return true
+	}
+	// 与 CommonMark 一致：空列表项与不以 1 开头的有序列表不打断段落
+	if m := listItemPattern.FindStringSubmatch(line); m != nil {
+		if isBlank(line[len(m[0]):]) {
+			return false
+		}
+		marker := m[2]
+		return marker == "-" || marker == "*" || marker == "+" || marker[:len(marker)-1] == "1"
+	}
+	return false
+}
+
+// singleLine 将译文中的换行替换为空格
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(text, "\n", " ")), " ")
+}
+
+// escapePipes 转义单元格译文中未转义、不在行内代码中的 "|"
+func escapePipes(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			b.WriteString(text[i : i+2])
+			i++
+			continue
+		}
+		if text[i] == '`' {
+			if end, ok := codeSpanEnd(text, i); ok {
+				b.WriteString(text[i:end])
+				i = end - 1
+				continue
+			}
+		}
+		if text[i] == '|' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// indentWidth 行首缩进宽度，制表符按 4 列对齐
+func indentWidth(line string) int {
+	width := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// indentBytes 返回覆盖 width 列缩进所需的字节数
+func indentBytes(line string, width int) int {
+	col := 0
+	for i := 0; i < len(line); i++ {
+		if col >= width {
+			return i
+		}
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			col += 4 - col%4
+		default:
+			return i
+		}
+	}
+	return len(line)
+}
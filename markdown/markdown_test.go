@@ -0,0 +1,111 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"transbridge/markup"
+)
+
+// testDocuments 覆盖各类块结构的 Markdown 文档
+var testDocuments = []struct {
+	name string
+	text string
+}{
+	{
+		name: "front matter and headings",
+		text: "---\ntitle: \"Getting started\"\ndescription: Install the CLI\nslug: getting-started\n---\n\n# Getting started\n\nInstall the `transbridge` CLI first.\n\n## Next steps ##\n",
+	},
+	{
+		name: "lists and quotes",
+		text: "- First item\n- Second item\n  continues here\n  1. Nested step\n\n> Quoted text\n> over two lines\n\n* [ ] Task with [a link](https://example.com/docs \"Docs\")\n",
+	},
+	{
+		name: "code blocks",
+		text: "Run this:\n\n```bash\ngo build ./...\necho \"not translated\"\n```\n\n    indented code block\n    second line\n\nAfter the code.",
+	},
+	{
+		name: "table",
+		text: "| Name | Description |\n|------|:-----------:|\n| `id` | The unique id |\n| url | See https://example.com/a_(b) |\n",
+	},
+	{
+		name: "html block and references",
+		text: "<div class=\"note\">\n<p>Read the <b>docs</b>.</p>\n</div>\n\nSee [the guide][guide] or <https://example.com>.\n\n[guide]: https://example.com/guide \"Guide\"\n",
+	},
+	{
+		name: "inline markup",
+		text: "Use **bold**, _emphasis_, ``code with ` tick``, ![logo](img/logo.png) and <kbd>Ctrl</kbd>+C.\nVisit https://example.com/path?q=1, then email <team@example.com>.",
+	},
+	{
+		name: "crlf line endings",
+		text: "# Title\r\n\r\nFirst paragraph\r\ncontinued\r\n\r\n```\r\nmake install\r\n```\r\n",
+	},
+}
+
+func TestRenderIdentity(t *testing.T) {
+	for _, tt := range testDocuments {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse(tt.text, Options{})
+			segments := doc.Segments()
+			if len(segments) == 0 {
+				t.Fatal("no segments to translate")
+			}
+			identity := make([]string, len(segments))
+			for i, seg := range segments {
+				identity[i] = seg.Text
+			}
+			if got := doc.Render(identity); got != tt.text {
+				t.Errorf("Render(identity) differs from the input:\n got %q\nwant %q", got, tt.text)
+			}
+			if got := doc.Render(nil); got != tt.text {
+				t.Errorf("Render(nil) differs from the input:\n got %q\nwant %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestInlineIdentity(t *testing.T) {
+	for _, tt := range testDocuments {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, seg := range Parse(tt.text, Options{}).Segments() {
+				if seg.HTML {
+					continue
+				}
+				inline := ParseInline(seg.Text)
+				if got := inline.Rebuild(inline.TextNodes()); got != seg.Text {
+					t.Errorf("Rebuild(text nodes) = %q, want %q", got, seg.Text)
+				}
+				if got, err := inline.Restore(inline.Protected()); err != nil || got != seg.Text {
+					t.Errorf("Restore(Protected()) = %q, %v, want %q", got, err, seg.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestCodeAndURLsNotTranslated(t *testing.T) {
+	// 这些片段出现在文档中，但不能出现在交给模型的任何文本中
+	protected := []string{
+		"go build", "not translated", "indented code block", "second line", "make install", "getting-started",
+		"`transbridge`", "transbridge", "`id`", "with ` tick",
+		"https://", "example.com", "img/logo.png", "team@example.com", "[guide]", "<kbd>",
+	}
+
+	for _, tt := range testDocuments {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, seg := range Parse(tt.text, Options{}).Segments() {
+				texts := ParseInline(seg.Text).TextNodes()
+				if seg.HTML {
+					texts = markup.Parse(seg.Text, "html").TextNodes()
+				}
+				for _, text := range texts {
+					for _, p := range protected {
+						if strings.Contains(text, p) {
+							t.Errorf("%q handed out for translation, contains %q", text, p)
+						}
+					}
+				}
+			}
+		})
+	}
+}
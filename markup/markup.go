@@ -0,0 +1,285 @@
+// markup/markup.go
+package markup
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// placeholder 占位符对应的原始标签；成对标签 close 不为空
+type placeholder struct {
+	open  string
+	close string
+}
+
+func (p placeholder) paired() bool {
+	return p.close != ""
+}
+
+// Document 解析后的 HTML/XML 片段
+// 标签被替换为 <x1>…</x1>、<x2/> 形式的占位符，翻译后按占位符还原原始标签
+type Document struct {
+	tokens       []Token
+	placeholders []placeholder // 下标为占位符编号 - 1
+	protected    string
+	textNodes    []int // 需要翻译的文本片段在 tokens 中的下标
+	entities     bool  // 文本是否使用 HTML/XML 字符实体
+}
+
+// Parse 解析 HTML（mode 为 "html"）或 XML 片段
+// 未配对的开始/结束标签、注释、<script>/<style> 以及 translate="no" 或 class="notranslate" 的元素都替换为自闭合占位符
+func Parse(s, mode string) *Document {
+	return NewDocument(mergeNoTranslate(Tokenize(s, mode)), true)
+}
+
+// NewDocument 由已切分好的片段创建文档，供其它标记格式（如 Markdown 的链接、行内代码）复用占位符还原逻辑
+// 同名的开始/结束片段按嵌套关系配对；entities 为 false 时文本不做实体转义与解码
+func NewDocument(tokens []Token, entities bool) *Document {
+	partners := pairTags(tokens)
+
+	d := &Document{tokens: tokens, entities: entities}
+	ids := make(map[int]int, len(tokens))
+
+	var b strings.Builder
+	for i, tok := range tokens {
+		switch tok.Type {
+		case TextToken:
+			b.WriteString(tok.Raw)
+			if strings.TrimSpace(tok.Raw) != "" {
+				d.textNodes = append(d.textNodes, i)
+			}
+		case StartTagToken:
+			if partner, ok := partners[i]; ok {
+				d.placeholders = append(d.placeholders, placeholder{open: tok.Raw, close: tokens[partner].Raw})
+				id := len(d.placeholders)
+				ids[partner] = id
+				fmt.Fprintf(&b, "<x%d>", id)
+				continue
+			}
+			d.writeSelfClosing(&b, tok.Raw)
+		case EndTagToken:
+			if id, ok := ids[i]; ok {
+				fmt.Fprintf(&b, "</x%d>", id)
+				continue
+			}
+			d.writeSelfClosing(&b, tok.Raw)
+		default:
+			d.writeSelfClosing(&b, tok.Raw)
+		}
+	}
+	d.protected = b.String()
+	return d
+}
+
+func (d *Document) writeSelfClosing(b *strings.Builder, raw string) {
+	d.placeholders = append(d.placeholders, placeholder{open: raw})
+	fmt.Fprintf(b, "<x%d/>", len(d.placeholders))
+}
+
+// HasMarkup 片段中是否包含标签
+func (d *Document) HasMarkup() bool {
+	return len(d.placeholders) > 0
+}
+
+// Protected 返回标签替换为占位符后的文本
+func (d *Document) Protected() string {
+	return d.protected
+}
+
+// PlainText 返回去掉标签后的文本，用于语言检测
+func (d *Document) PlainText() string {
+	parts := make([]string, len(d.textNodes))
+	for i, idx := range d.textNodes {
+		parts[i] = d.unescape(strings.TrimSpace(d.tokens[idx].Raw))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Restore 将译文中的占位符还原为原始标签，并校验标签结构：
+// 每个占位符必须恰好出现一次、成对占位符正确嵌套，且不能出现其它标签
+func (d *Document) Restore(translated string) (string, error) {
+	used := make([]bool, len(d.placeholders))
+	var stack []int
+
+	var b strings.Builder
+	for _, tok := range Tokenize(translated, "xml") {
+		if tok.Type == TextToken {
+			if d.entities {
+				b.WriteString(escapeAmpersands(tok.Raw))
+			} else {
+				b.WriteString(tok.Raw)
+			}
+			continue
+		}
+
+		id, ok := d.placeholderID(tok)
+		if !ok {
+			return "", fmt.Errorf("unexpected markup %q in translation", tok.Raw)
+		}
+		p := d.placeholders[id-1]
+
+		switch tok.Type {
+		case StartTagToken:
+			if !p.paired() || used[id-1] {
+				return "", fmt.Errorf("unexpected placeholder %q in translation", tok.Raw)
+			}
+			used[id-1] = true
+			stack = append(stack, id)
+			b.WriteString(p.open)
+		case EndTagToken:
+			if len(stack) == 0 || stack[len(stack)-1] != id {
+				return "", fmt.Errorf("misplaced closing placeholder %q in translation", tok.Raw)
+			}
+			stack = stack[:len(stack)-1]
+			b.WriteString(p.close)
+		case SelfClosingToken:
+			if p.paired() || used[id-1] {
+				return "", fmt.Errorf("unexpected placeholder %q in translation", tok.Raw)
+			}
+			used[id-1] = true
+			b.WriteString(p.open)
+		}
+	}
+
+	if len(stack) > 0 {
+		return "", fmt.Errorf("unclosed placeholder <x%d> in translation", stack[len(stack)-1])
+	}
+	for i, ok := range used {
+		if !ok {
+			return "", fmt.Errorf("placeholder x%d missing from translation", i+1)
+		}
+	}
+	return b.String(), nil
+}
+
+// placeholderID 解析占位符编号，例如 <x12> -> 12
+func (d *Document) placeholderID(tok Token) (int, bool) {
+	if tok.Type == TextToken || tok.Type == OpaqueToken || !strings.HasPrefix(tok.Name, "x") {
+		return 0, false
+	}
+	id, err := strconv.Atoi(tok.Name[1:])
+	if err != nil || id < 1 || id > len(d.placeholders) {
+		return 0, false
+	}
+	return id, true
+}
+
+// TextNodes 返回需要翻译的文本片段（已去除首尾空白并解码实体），用于逐段翻译的回退方案
+func (d *Document) TextNodes() []string {
+	nodes := make([]string, len(d.textNodes))
+	for i, idx := range d.textNodes {
+		nodes[i] = d.unescape(strings.TrimSpace(d.tokens[idx].Raw))
+	}
+	return nodes
+}
+
+// Rebuild 用逐段翻译的结果替换文本片段并保留原始标签，translations 与 TextNodes 一一对应
+func (d *Document) Rebuild(translations []string) string {
+	replaced := make(map[int]string, len(d.textNodes))
+	for i, idx := range d.textNodes {
+		if i < len(translations) {
+			raw := d.tokens[idx].Raw
+			leading := raw[:len(raw)-len(strings.TrimLeft(raw, " \t\r\n"))]
+			trailing := raw[len(strings.TrimRight(raw, " \t\r\n")):]
+			text := translations[i]
+			if d.entities {
+				text = escapeText(text)
+			}
+			replaced[idx] = leading + text + trailing
+		}
+	}
+
+	var b strings.Builder
+	for i, tok := range d.tokens {
+		if text, ok := replaced[i]; ok {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString(tok.Raw)
+	}
+	return b.String()
+}
+
+// unescape 解码文本中的字符实体
+func (d *Document) unescape(text string) string {
+	if !d.entities {
+		return text
+	}
+	return html.UnescapeString(text)
+}
+
+// pairTags 为开始标签找到对应的结束标签，返回开始标签下标到结束标签下标的映射
+// 结束标签与栈中最近的同名开始标签配对，中间未闭合的开始标签视为不成对
+func pairTags(tokens []Token) map[int]int {
+	partners := make(map[int]int)
+	var stack []int
+	for i, tok := range tokens {
+		switch tok.Type {
+		case StartTagToken:
+			stack = append(stack, i)
+		case EndTagToken:
+			for j := len(stack) - 1; j >= 0; j-- {
+				if tokens[stack[j]].Name == tok.Name {
+					partners[stack[j]] = i
+					stack = stack[:j]
+					break
+				}
+			}
+		}
+	}
+	return partners
+}
+
+// noTranslatePattern 标记为不翻译的元素
+var noTranslatePattern = regexp.MustCompile(`(?i)\stranslate\s*=\s*["']?no["'\s/>]|\sclass\s*=\s*["'][^"']*\bnotranslate\b`)
+
+// mergeNoTranslate 将不翻译的元素（含其内容）合并为一个不翻译的片段
+func mergeNoTranslate(tokens []Token) []Token {
+	partners := pairTags(tokens)
+
+	merged := make([]Token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		end, ok := partners[i]
+		if tok.Type != StartTagToken || !ok || !noTranslatePattern.MatchString(tok.Raw) {
+			merged = append(merged, tok)
+			continue
+		}
+
+		var raw strings.Builder
+		for _, t := range tokens[i : end+1] {
+			raw.WriteString(t.Raw)
+		}
+		merged = append(merged, Token{Type: OpaqueToken, Raw: raw.String(), Name: tok.Name})
+		i = end
+	}
+	return merged
+}
+
+// entityPattern 合法的字符实体，例如 &amp;、&#39;、&#x27;
+var entityPattern = regexp.MustCompile(`^&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);`)
+
+// escapeAmpersands 将不构成实体的 "&" 转义为 "&amp;"
+func escapeAmpersands(text string) string {
+	if !strings.Contains(text, "&") {
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '&' && !entityPattern.MatchString(text[i:]) {
+			b.WriteString("&amp;")
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// escapeText 转义纯文本中的 &、<、>
+func escapeText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
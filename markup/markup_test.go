@@ -0,0 +1,99 @@
+package markup
+
+import "testing"
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        string
+		mode          string
+		wantProtected string
+		translated    string
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:          "paired tag",
+			source:        "Click <b>here</b> now",
+			wantProtected: "Click <x1>here</x1> now",
+			translated:    "现在点击<x1>这里</x1>",
+			want:          "现在点击<b>这里</b>",
+		},
+		{
+			name:          "attributes kept",
+			source:        `Read the <a href="/docs?a=1&amp;b=2" class="link">docs</a>.`,
+			wantProtected: "Read the <x1>docs</x1>.",
+			translated:    "阅读<x1>文档</x1>。",
+			want:          `阅读<a href="/docs?a=1&amp;b=2" class="link">文档</a>。`,
+		},
+		{
+			name:          "void element becomes self-closing placeholder",
+			source:        "Line one<br>line two",
+			wantProtected: "Line one<x1/>line two",
+			translated:    "第一行<x1/>第二行",
+			want:          "第一行<br>第二行",
+		},
+		{
+			name:          "placeholders reordered",
+			source:        "<b>bold</b> and <i>italic</i>",
+			wantProtected: "<x1>bold</x1> and <x2>italic</x2>",
+			translated:    "<x2>斜体</x2>和<x1>粗体</x1>",
+			want:          "<i>斜体</i>和<b>粗体</b>",
+		},
+		{
+			name:          "nested placeholders",
+			source:        "<p>Say <em>hi</em></p>",
+			wantProtected: "<x1>Say <x2>hi</x2></x1>",
+			translated:    "<x1>说<x2>你好</x2></x1>",
+			want:          "<p>说<em>你好</em></p>",
+		},
+		{
+			name:          "notranslate element protected",
+			source:        `<span class="notranslate">Acme</span> rocks`,
+			wantProtected: "<x1/> rocks",
+			translated:    "<x1/> 很棒",
+			want:          `<span class="notranslate">Acme</span> 很棒`,
+		},
+		{
+			name:       "bare ampersand escaped",
+			source:     "Tom &amp; <b>Jerry</b>",
+			translated: "汤姆 & <x1>杰瑞</x1>",
+			want:       "汤姆 &amp; <b>杰瑞</b>",
+		},
+		{
+			name:       "xml entity kept",
+			source:     "<g id=\"1\">A &lt; B</g>",
+			mode:       "xml",
+			translated: "<x1>A &lt; B</x1>",
+			want:       "<g id=\"1\">A &lt; B</g>",
+		},
+		{name: "missing placeholder", source: "Click <b>here</b>", translated: "点击这里", wantErr: true},
+		{name: "duplicated placeholder", source: "Click <b>here</b>", translated: "<x1>点击</x1><x1>这里</x1>", wantErr: true},
+		{name: "unclosed placeholder", source: "Click <b>here</b>", translated: "点击<x1>这里", wantErr: true},
+		{name: "misnested placeholders", source: "<b>a</b><i>b</i>", translated: "<x1><x2>甲</x1>乙</x2>", wantErr: true},
+		{name: "unknown placeholder", source: "Click <b>here</b>", translated: "<x1>这里</x1><x9/>", wantErr: true},
+		{name: "paired placeholder used as self-closing", source: "Click <b>here</b>", translated: "点击<x1/>", wantErr: true},
+		{name: "new tag in translation", source: "Click <b>here</b>", translated: "<x1>点击</x1><span>这里</span>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := tt.mode
+			if mode == "" {
+				mode = "html"
+			}
+			doc := Parse(tt.source, mode)
+			if tt.wantProtected != "" && doc.Protected() != tt.wantProtected {
+				t.Errorf("Protected() = %q, want %q", doc.Protected(), tt.wantProtected)
+			}
+
+			got, err := doc.Restore(tt.translated)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Restore(%q) error = %v, wantErr %v", tt.translated, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Restore(%q) = %q, want %q", tt.translated, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// markup/tokenizer.go
+package markup
+
+import "strings"
+
+// TokenType 标记片段类型
+type TokenType int
+
+const (
+	TextToken        TokenType = iota // 文本
+	StartTagToken                     // 开始标签，例如 <a href="...">
+	EndTagToken                       // 结束标签，例如 </a>
+	SelfClosingToken                  // 自闭合标签，例如 <br/>、HTML 中的 <img>
+	OpaqueToken                       // 不参与翻译的片段：注释、CDATA、DOCTYPE、处理指令、<script>/<style> 等
+)
+
+// Token 标记片段，Raw 为原始文本，拼接所有 Raw 可还原输入
+type Token struct {
+	Type TokenType
+	Raw  string
+	Name string // 标签名（小写），仅标签类型有效
+}
+
+// htmlVoidElements HTML 中没有结束标签的元素
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawTextElements 内容不是普通文本、整体不翻译的 HTML 元素
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true,
+}
+
+// Tokenize 将文本切分为标签与文本片段；mode 为 "html" 时识别空元素与 <script>/<style>
+// 无法识别为标签的 "<"（例如 "a < b"）按普通文本处理
+func Tokenize(s, mode string) []Token {
+	html := mode == "html"
+
+	var tokens []Token
+	textStart := 0
+	flushText := func(end int) {
+		if end > textStart {
+			tokens = append(tokens, Token{Type: TextToken, Raw: s[textStart:end]})
+		}
+	}
+
+	i := 0
+	for i < len(s) {
+		if s[i] != '<' {
+			i++
+			continue
+		}
+
+		tagStart := i
+		end, tok, ok := readTag(s, i, html)
+		if !ok {
+			i++
+			continue
+		}
+
+		flushText(tagStart)
+		tokens = append(tokens, tok)
+		i = end
+
+		// <script>、<style> 的内容连同结束标签整体作为不翻译的片段
+		if html && tok.Type == StartTagToken && htmlRawTextElements[tok.Name] {
+			closeIdx := indexFold(s[i:], "</"+tok.Name)
+			if closeIdx < 0 {
+				closeIdx = len(s) - i
+			}
+			closeEnd := i + closeIdx
+			if gt := strings.IndexByte(s[closeEnd:], '>'); gt >= 0 {
+				closeEnd += gt + 1
+			} else {
+				closeEnd = len(s)
+			}
+			tokens[len(tokens)-1] = Token{Type: OpaqueToken, Raw: s[tagStart:closeEnd], Name: tok.Name}
+			i = closeEnd
+		}
+		textStart = i
+	}
+	flushText(len(s))
+	return tokens
+}
+
+// readTag 尝试从 s[start] 处读取一个标签，返回结束位置
+func readTag(s string, start int, html bool) (int, Token, bool) {
+	rest := s[start:]
+	switch {
+	case strings.HasPrefix(rest, "<!--"):
+		return readUntil(s, start, "-->", OpaqueToken)
+	case strings.HasPrefix(rest, "<![CDATA["):
+		return readUntil(s, start, "]]>", OpaqueToken)
+	case strings.HasPrefix(rest, "<?"):
+		return readUntil(s, start, "?>", OpaqueToken)
+	case strings.HasPrefix(rest, "<!"):
+		return readUntil(s, start, ">", OpaqueToken)
+	}
+
+	i := start + 1
+	tokType := StartTagToken
+	if i < len(s) && s[i] == '/' {
+		tokType = EndTagToken
+		i++
+	}
+
+	nameStart := i
+	if i >= len(s) || !isNameStart(s[i]) {
+		return 0, Token{}, false
+	}
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	name := strings.ToLower(s[nameStart:i])
+
+	// 跳过属性，引号内的 ">" 不结束标签
+	var quote byte
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '<':
+			// 标签未闭合就出现新的 "<"，视为普通文本
+			return 0, Token{}, false
+		case c == '>':
+			raw := s[start : i+1]
+			if tokType == StartTagToken && (strings.HasSuffix(raw, "/>") || (html && htmlVoidElements[name])) {
+				tokType = SelfClosingToken
+			}
+			return i + 1, Token{Type: tokType, Raw: raw, Name: name}, true
+		}
+	}
+	return 0, Token{}, false
+}
+
+func readUntil(s string, start int, terminator string, tokType TokenType) (int, Token, bool) {
+	idx := strings.Index(s[start:], terminator)
+	if idx < 0 {
+		return 0, Token{}, false
+	}
+	end := start + idx + len(terminator)
+	return end, Token{Type: tokType, Raw: s[start:end]}, true
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '-' || c == ':' || c == '.'
+}
+
+// indexFold 忽略 ASCII 大小写查找子串（substr 为小写 ASCII），返回字节位置
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if asciiEqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func asciiEqualFold(a, lower string) bool {
+	for i := 0; i < len(lower); i++ {
+		c := a[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c != lower[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"transbridge/glossary"
+	"transbridge/internal/utils"
+)
+
+// batchInstructions 多段文本合并翻译时附加的指令
+func batchInstructions(n int) string {
+	return fmt.Sprintf("The text is a JSON array of %d separate segments. Translate each segment independently and reply with only a JSON array of exactly %d strings "+
+		"containing the translations in the same order, without explanations or code fences.", n, n)
+}
+
+// batchTranslateSegments 批量翻译：逐条检测语言、查找缓存，未命中缓存的纯文本按语言对与提示词分组，每组合并为一次模型调用
+// 模型返回的条数与请求不一致或无法解析时，该组改为逐条翻译；带标记或上下文的请求仍逐条翻译
+func (s *TranslationService) batchTranslateSegments(ctx context.Context, prompts *utils.PromptSet, requests []TranslateRequest) []BatchResult {
+	results := make([]BatchResult, len(requests))
+	pending := make([]*pendingText, len(requests))
+	sem := make(chan struct{}, s.opts.BatchConcurrency)
+
+	// 1. 逐条检测语言、查找缓存；缓存命中的文本直接返回
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func(idx int, req TranslateRequest) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[idx] = BatchResult{Error: ctx.Err()}
+				return
+			}
+
+			var result *TranslateResult
+			var err error
+			if req.Text != "" && req.TargetLang != "" && req.TagHandling == "" && req.passage == nil {
+				pending[idx], result, err = s.prepareText(ctx, prompts, req)
+			} else {
+				result, err = s.TranslateWithResult(ctx, prompts, req)
+			}
+			if err != nil {
+				results[idx] = BatchResult{Error: err}
+			} else if result != nil {
+				results[idx] = BatchResult{Text: result.Text, Result: result}
+			}
+		}(i, req)
+	}
+	wg.Wait()
+
+	// 2. 未命中缓存的文本分组合并翻译
+	for _, group := range s.batchGroups(pending) {
+		wg.Add(1)
+		go func(group []int) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				for _, idx := range group {
+					results[idx] = BatchResult{Error: ctx.Err()}
+				}
+				return
+			}
+			s.translateBatch(ctx, pending, group, results)
+		}(group)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// batchGroups 把待翻译的文本按语言对、模型、语气与提示词模板分组，并按 SegmentBatchSize 与 token 上限拆分，保持原有顺序
+func (s *TranslationService) batchGroups(pending []*pendingText) [][]int {
+	type bucket struct {
+		groups [][]int
+		tokens int
+	}
+	buckets := make(map[string]*bucket)
+	var order []string
+
+	for idx, p := range pending {
+		if p == nil {
+			continue
+		}
+		req := p.req
+		key := strings.Join([]string{req.SourceLang, req.TargetLang, req.Provider, req.Model, req.Formality, p.selected.Name, p.selected.Fingerprint()}, "\x00")
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{}
+			buckets[key] = b
+			order = append(order, key)
+		}
+
+		maxTokens, estimate := s.segmentBatchBudget(req)
+		tokens := estimate(req.Text)
+		last := len(b.groups) - 1
+		if last < 0 || len(b.groups[last]) >= s.opts.SegmentBatchSize || b.tokens+tokens > maxTokens {
+			b.groups = append(b.groups, nil)
+			last++
+			b.tokens = 0
+		}
+		b.groups[last] = append(b.groups[last], idx)
+		b.tokens += tokens
+	}
+
+	var groups [][]int
+	for _, key := range order {
+		groups = append(groups, buckets[key].groups...)
+	}
+	return groups
+}
+
+// segmentBatchBudget 返回单次合并调用原文的最大 token 数与 token 估算方法
+func (s *TranslationService) segmentBatchBudget(req TranslateRequest) (int, func(string) int) {
+	limit, estimate := s.modelTokenBudget(req)
+	if s.opts.SegmentBatchTokens > 0 {
+		limit = s.opts.SegmentBatchTokens
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	return limit, estimate
+}
+
+// translateBatch 将一组文本合并为 JSON 数组翻译一次，再按顺序拆回各条；解析失败时逐条翻译
+func (s *TranslationService) translateBatch(ctx context.Context, pending []*pendingText, group []int, results []BatchResult) {
+	if len(group) > 1 {
+		first := pending[group[0]]
+		texts := make([]string, len(group))
+		var terms []glossary.Term
+		seen := make(map[glossary.Term]bool)
+		for i, idx := range group {
+			texts[i] = pending[idx].req.Text
+			for _, term := range pending[idx].terms {
+				if !seen[term] {
+					seen[term] = true
+					terms = append(terms, term)
+				}
+			}
+		}
+		payload, _ := json.Marshal(texts)
+
+		batchReq := first.req
+		batchReq.Text = string(payload)
+		prompt := batchReq.applyInstructions(first.selected)
+		prompt.Template = batchInstructions(len(group)) + "\n" + prompt.Template
+		prompt = applyMemory(applyGlossary(prompt, terms), mergeReferences(pending, group))
+
+		reply, usedTranslator, failedModels, err := s.translateWithFailover(ctx, prompt, batchReq)
+		if err != nil {
+			for _, idx := range group {
+				results[idx] = BatchResult{Error: err}
+			}
+			return
+		}
+
+		translations, ok := parseBatchReply(reply, len(group))
+		if ok {
+			var retry []int
+			for i, idx := range group {
+				if strings.TrimSpace(translations[i]) == "" && strings.TrimSpace(texts[i]) != "" {
+					retry = append(retry, idx) // 模型漏译的条目单独翻译
+					continue
+				}
+				result := s.completeText(ctx, pending[idx], translations[i], usedTranslator, failedModels)
+				results[idx] = BatchResult{Text: result.Text, Result: result}
+			}
+			group = retry
+		} else {
+			log.Printf("Batch reply from %s/%s does not contain %d segments, translating them one by one",
+				usedTranslator.GetProvider(), usedTranslator.GetModel(), len(group))
+		}
+	}
+
+	for _, idx := range group {
+		result, err := s.translatePending(ctx, pending[idx])
+		if err != nil {
+			results[idx] = BatchResult{Error: err}
+			continue
+		}
+		results[idx] = BatchResult{Text: result.Text, Result: result}
+	}
+}
+
+// parseBatchReply 从模型回复中解析 JSON 字符串数组，条数与 n 不一致时返回 false
+// 模型有时会在数组外包裹代码块或说明文字，取第一个 "[" 到最后一个 "]" 之间的内容解析
+func parseBatchReply(reply string, n int) ([]string, bool) {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, false
+	}
+
+	var translations []string
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &translations); err != nil {
+		return nil, false
+	}
+	return translations, len(translations) == n
+}
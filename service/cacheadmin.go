@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"transbridge/cache"
+	"transbridge/internal/utils"
+)
+
+// ManualCacheProvider 通过管理接口写入、未指定模型的缓存条目记录的服务提供商
+const ManualCacheProvider = "manual"
+
+// 缓存管理错误
+var (
+	ErrCacheDisabled         = errors.New("cache is not enabled")
+	ErrEmptyPurgeFilter      = errors.New("at least one of source_lang, target_lang, provider or model is required")
+	ErrPurgeByLanguageV1     = errors.New("deleting by language pair requires cache key version v2")
+	ErrPurgeByLanguageLegacy = errors.New("deleting by language pair cannot remove v1 entries still served by cache.key.legacy_lookup, disable it or delete by model")
+	ErrCacheNoText           = errors.New("text contains only markup and is never cached")
+	ErrCacheChunked          = errors.New("text exceeds the chunk budget and is cached per chunk, manage its chunks individually")
+	ErrCachePlaceholders     = errors.New("translation of tagged text must keep the <x1>…</x1> placeholders of the source")
+)
+
+// cacheKeyPrefixes 存放译文缓存的键前缀，按模型删除时扫描
+var cacheKeyPrefixes = []string{utils.CacheKeyPrefix, "transbrige:"}
+
+// cacheKeyPattern 译文缓存键：v2 键、v1 键以及拼写错误的旧前缀，共用前缀的任务、锁等键不匹配
+var cacheKeyPattern = regexp.MustCompile(`^(transbridge:v2:[^:]*:[^:]*:|transbridge:|transbrige:)[0-9a-f]{32}$`)
+
+// CachedTranslation 管理接口查到的缓存条目
+type CachedTranslation struct {
+	Key                string           `json:"key"`
+	Legacy             bool             `json:"legacy,omitempty"`               // 命中的是旧版缓存键
+	DetectedSourceLang string           `json:"detected_source_lang,omitempty"` // 未指定源语言时检测得出的语言
+	Entry              cache.CacheEntry `json:"entry"`
+}
+
+// CachePurgeFilter 批量删除缓存的条件，各字段为空时不限制，同时指定时需全部满足
+type CachePurgeFilter struct {
+	SourceLang string
+	TargetLang string
+	Provider   string
+	Model      string
+}
+
+// adminPending 按翻译时的方式计算请求的缓存键：未指定源语言（或为 auto）时同样检测语言，
+// 带标签的文本按替换为占位符后的文本计算，再按提示词、术语与指纹维度计算缓存键
+// 只有标签的文本不会写入缓存，超出分块预算的文本按块缓存，这两种情况返回错误
+func (s *TranslationService) adminPending(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (*pendingText, error) {
+	req.passage, req.detected = nil, nil
+
+	detectText := req.Text
+	if req.TagHandling != "" {
+		doc := parseMarkup(req.Text, req.TagHandling)
+		if len(doc.TextNodes()) == 0 {
+			return nil, ErrCacheNoText
+		}
+		detectText, req.Text = doc.PlainText(), doc.Protected()
+	}
+
+	// 带标签的文本在保护标签前已检测语言，旧版缓存键按检测后的语言计算
+	rawSource, rawTarget := req.SourceLang, req.TargetLang
+	detected, err := s.resolveSourceLang(ctx, &req, detectText)
+	if err != nil {
+		return nil, err
+	}
+	if req.TagHandling != "" {
+		rawSource = req.SourceLang
+	}
+
+	if maxTokens, estimate := s.chunkBudget(req); maxTokens > 0 && estimate(req.Text) > maxTokens {
+		return nil, ErrCacheChunked
+	}
+
+	selected := prompts.Select(req.SourceLang, req.TargetLang)
+	terms := s.opts.Glossary.Match(req.SourceLang, req.TargetLang, req.Text)
+	return &pendingText{
+		req:       req,
+		selected:  selected,
+		terms:     terms,
+		cacheKey:  s.cacheKey(req, selected, terms),
+		rawSource: rawSource,
+		rawTarget: rawTarget,
+		detected:  detected,
+	}, nil
+}
+
+// CacheLookup 查找请求对应的缓存条目，查找顺序与翻译时一致，但旧键命中时不迁移；未命中时返回 nil
+func (s *TranslationService) CacheLookup(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (*CachedTranslation, error) {
+	if s.cache == nil {
+		return nil, ErrCacheDisabled
+	}
+	p, err := s.adminPending(ctx, prompts, req)
+	if err != nil {
+		return nil, err
+	}
+	var detected string
+	if p.detected != nil {
+		detected = p.detected.Language
+	}
+	if entry, ok := s.readCacheEntry(ctx, p.cacheKey); ok {
+		return &CachedTranslation{Key: p.cacheKey, DetectedSourceLang: detected, Entry: *entry}, nil
+	}
+
+	for _, legacyKey := range s.legacyCacheKeys(p) {
+		entry, ok := s.readCacheEntry(ctx, legacyKey)
+		if !ok || s.pinnedModelMismatch(p.req, entry) {
+			continue
+		}
+		return &CachedTranslation{Key: legacyKey, Legacy: true, DetectedSourceLang: detected, Entry: *entry}, nil
+	}
+	return nil, nil
+}
+
+// CacheStore 写入或修正请求对应的缓存条目，之后相同的请求直接返回该译文
+// entry 未指定服务提供商时记为 ManualCacheProvider；ttl 为 0 时使用缓存的默认过期时间，小于 0 时永久保存
+// 带标签的文本缓存的是占位符形式的译文，entry 中的译文同样需要使用原文的占位符
+func (s *TranslationService) CacheStore(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, entry cache.CacheEntry, ttl time.Duration) (string, error) {
+	if s.cache == nil {
+		return "", ErrCacheDisabled
+	}
+	if req.TagHandling != "" {
+		if _, err := parseMarkup(req.Text, req.TagHandling).Restore(entry.Translation); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrCachePlaceholders, err)
+		}
+	}
+	if entry.Provider == "" {
+		entry.Provider = ManualCacheProvider
+	}
+	p, err := s.adminPending(ctx, prompts, req)
+	if err != nil {
+		return "", err
+	}
+	key := p.cacheKey
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	if err := s.cache.Set(ctx, key, string(data), ttl); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// CacheDelete 删除请求对应的缓存条目，包括所有旧版缓存键（见 v1CacheKeys），返回删除的条目数
+func (s *TranslationService) CacheDelete(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (int, error) {
+	if s.cache == nil {
+		return 0, ErrCacheDisabled
+	}
+	p, err := s.adminPending(ctx, prompts, req)
+	if err != nil {
+		return 0, err
+	}
+	keys := append([]string{p.cacheKey}, p.v1CacheKeys()...)
+
+	count := 0
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if _, err := s.cache.Get(ctx, k); err != nil {
+			continue
+		}
+		if err := s.cache.Delete(ctx, k); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// CachePurge 删除满足条件的缓存条目，返回删除的条目数
+// 按语言对删除依赖 v2 缓存键中的明文语言，只能删除 v2 条目；按模型删除会检查所有译文缓存，包括 v1 条目
+// 开启 CacheLegacyLookup 时同一文本的 v1 条目仍会被读取，删除 v2 条目后译文照旧返回，因此不允许按语言对删除
+func (s *TranslationService) CachePurge(ctx context.Context, filter CachePurgeFilter) (int, error) {
+	if s.cache == nil {
+		return 0, ErrCacheDisabled
+	}
+	if filter == (CachePurgeFilter{}) {
+		return 0, ErrEmptyPurgeFilter
+	}
+
+	src := utils.NormalizeLanguageCode(filter.SourceLang)
+	if src == "" && filter.SourceLang != "" {
+		src = "auto" // 自动检测失败时写入的条目
+	}
+	tgt := utils.NormalizeLanguageCode(filter.TargetLang)
+	byLanguage := filter.SourceLang != "" || filter.TargetLang != ""
+	if byLanguage && s.opts.CacheKeyVersion == CacheKeyV1 {
+		return 0, ErrPurgeByLanguageV1
+	}
+	if byLanguage && s.opts.CacheLegacyLookup {
+		return 0, ErrPurgeByLanguageLegacy
+	}
+
+	prefixes := cacheKeyPrefixes
+	switch {
+	case src != "" && tgt != "":
+		prefixes = []string{utils.CacheKeyLangPrefix(src, tgt)}
+	case byLanguage:
+		prefixes = []string{utils.CacheKeyPrefix + "v2:"}
+	}
+
+	count := 0
+	var deleteErr error
+	for _, prefix := range prefixes {
+		err := s.cache.Scan(ctx, prefix, func(key, value string) bool {
+			if !cacheKeyPattern.MatchString(key) {
+				return true
+			}
+			if byLanguage && !keyMatchesLanguages(key, src, tgt) {
+				return true
+			}
+			if filter.Provider != "" || filter.Model != "" {
+				var entry cache.CacheEntry
+				if err := json.Unmarshal([]byte(value), &entry); err != nil {
+					return true
+				}
+				if (filter.Provider != "" && entry.Provider != filter.Provider) || (filter.Model != "" && entry.Model != filter.Model) {
+					return true
+				}
+			}
+			if deleteErr = s.cache.Delete(ctx, key); deleteErr != nil {
+				return false
+			}
+			count++
+			return true
+		})
+		if err != nil {
+			return count, err
+		}
+		if deleteErr != nil {
+			return count, deleteErr
+		}
+	}
+	return count, nil
+}
+
+// keyMatchesLanguages 判断 v2 缓存键中的语言对是否与条件一致，条件为空时不限制
+func keyMatchesLanguages(key, src, tgt string) bool {
+	parts := strings.Split(strings.TrimPrefix(key, utils.CacheKeyPrefix+"v2:"), ":")
+	if len(parts) != 3 {
+		return false
+	}
+	return (src == "" || parts[0] == src) && (tgt == "" || parts[1] == tgt)
+}
+
+// CacheClear 清空所有译文缓存
+func (s *TranslationService) CacheClear(ctx context.Context) error {
+	if s.cache == nil {
+		return ErrCacheDisabled
+	}
+	return s.cache.Clear(ctx)
+}
+
+// CacheStats 返回各缓存层的命中、淘汰等统计信息
+func (s *TranslationService) CacheStats() ([]cache.Stats, error) {
+	if s.cache == nil {
+		return nil, ErrCacheDisabled
+	}
+	return cache.CollectStats(s.cache), nil
+}
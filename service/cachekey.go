@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"transbridge/cache"
+	"transbridge/glossary"
+	"transbridge/internal/utils"
+	"transbridge/translator"
+)
+
+// 缓存键版本
+const (
+	CacheKeyV1 = "v1" // transbridge:<md5>，只包含语言对、原文以及语气、标签处理方式等选项
+	CacheKeyV2 = "v2" // transbridge:v2:<源语言>:<目标语言>:<md5>，包含可配置的指纹维度
+)
+
+// 参与 v2 缓存键计算的指纹维度
+const (
+	DimensionPrompt      = "prompt"      // 提示词模板（含系统提示词）
+	DimensionModel       = "model"       // 请求指定的 provider/model，未指定时不区分
+	DimensionTemperature = "temperature" // 模型的采样参数，未指定模型时取所有模型的参数
+	DimensionFormality   = "formality"   // 语气
+	DimensionGlossary    = "glossary"    // 文本中出现的术语
+)
+
+// CacheKeyDimensions 所有指纹维度，按计算顺序排列；未配置维度时全部启用
+var CacheKeyDimensions = []string{DimensionPrompt, DimensionModel, DimensionTemperature, DimensionFormality, DimensionGlossary}
+
+// canonicalDimensions 按 CacheKeyDimensions 的顺序整理配置的维度，配置顺序不影响缓存键
+func canonicalDimensions(dimensions []string) []string {
+	if dimensions == nil {
+		return CacheKeyDimensions
+	}
+	enabled := make(map[string]bool, len(dimensions))
+	for _, dim := range dimensions {
+		enabled[strings.ToLower(strings.TrimSpace(dim))] = true
+	}
+	result := make([]string, 0, len(dimensions))
+	for _, dim := range CacheKeyDimensions {
+		if enabled[dim] {
+			result = append(result, dim)
+		}
+	}
+	return result
+}
+
+// cacheKey 按配置的版本生成缓存键
+func (s *TranslationService) cacheKey(req TranslateRequest, prompt utils.PromptTemplate, terms []glossary.Term) string {
+	if s.opts.CacheKeyVersion == CacheKeyV1 {
+		return utils.GenerateCacheKey(req.Text, req.SourceLang, req.TargetLang, req.cacheOptions(prompt, terms)...)
+	}
+	return utils.VersionedCacheKey(req.Text, req.SourceLang, req.TargetLang, s.cacheDimensions(req, prompt, terms)...)
+}
+
+// legacyCacheKeys 返回缓存键未命中时依次查找的旧键，见 v1CacheKeys；v2 只在开启 CacheLegacyLookup 时查找
+func (s *TranslationService) legacyCacheKeys(p *pendingText) []string {
+	if s.opts.CacheKeyVersion != CacheKeyV1 && !s.opts.CacheLegacyLookup {
+		return nil
+	}
+	var keys []string
+	for _, key := range p.v1CacheKeys() {
+		if key != p.cacheKey {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// v1CacheKeys 返回请求可能对应的 v1 格式缓存键，每个键同时包含拼写错误的旧前缀 transbrige: 的版本：
+// 按检测后的语言与选项计算的 v1 键；没有任何选项时，还有按请求中原样的语言计算的旧版键——
+// 旧版本不检测源语言，自动检测的请求以 "auto" 或空字符串计算，语言代码也保留客户端的大小写
+func (p *pendingText) v1CacheKeys() []string {
+	opts := p.req.cacheOptions(p.selected, p.terms)
+	keys := []string{utils.GenerateCacheKey(p.req.Text, p.req.SourceLang, p.req.TargetLang, opts...)}
+	if len(opts) == 0 {
+		if raw := utils.GenerateCacheKey(p.req.Text, p.rawSource, p.rawTarget); raw != keys[0] {
+			keys = append(keys, raw)
+		}
+	}
+
+	result := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		result = append(result, key, strings.Replace(key, utils.CacheKeyPrefix, "transbrige:", 1))
+	}
+	return result
+}
+
+// cacheDimensions 返回参与 v2 缓存键计算的各维度指纹
+func (s *TranslationService) cacheDimensions(req TranslateRequest, prompt utils.PromptTemplate, terms []glossary.Term) []string {
+	var dims []string
+	for _, dim := range s.opts.CacheKeyDimensions {
+		switch dim {
+		case DimensionPrompt:
+			dims = append(dims, "prompt="+prompt.Fingerprint())
+		case DimensionModel:
+			if req.Provider != "" && req.Model != "" {
+				dims = append(dims, "model="+req.Provider+"/"+req.Model)
+			}
+		case DimensionTemperature:
+			if params := s.samplingFingerprint(req); params != "" {
+				dims = append(dims, "sampling="+params)
+			}
+		case DimensionFormality:
+			if req.Formality != "" && req.Formality != "default" {
+				dims = append(dims, "formality="+req.Formality)
+			}
+		case DimensionGlossary:
+			if len(terms) > 0 {
+				dims = append(dims, "glossary="+glossary.Fingerprint(terms))
+			}
+		}
+	}
+	// 标签处理方式决定译文格式，始终参与计算
+	if req.TagHandling != "" {
+		dims = append(dims, "tags="+req.TagHandling)
+	}
+	return dims
+}
+
+// samplingFingerprint 返回请求可能使用的模型的采样参数指纹；未指定模型时包含所有模型，模型均未提供采样参数时返回空字符串
+func (s *TranslationService) samplingFingerprint(req TranslateRequest) string {
+	var params []string
+	for _, id := range s.modelManager.ListModels() {
+		if req.Provider != "" && req.Model != "" && (id.Provider != req.Provider || id.Model != req.Model) {
+			continue
+		}
+		t, ok := s.modelManager.Lookup(id)
+		if !ok {
+			continue
+		}
+		if sampling, ok := t.(translator.Sampling); ok {
+			params = append(params, id.String()+":"+sampling.SamplingParams())
+		}
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	sort.Strings(params)
+
+	hasher := md5.New()
+	hasher.Write([]byte(strings.Join(params, "\n")))
+	return hex.EncodeToString(hasher.Sum(nil))[:12]
+}
+
+// lookupCache 按 p 的缓存键查找缓存条目，未命中时依次查找旧键；启用 model 维度且请求指定了模型时，只采用由该模型翻译的旧条目
+// 旧键不含提示词指纹，启用 prompt 维度时旧条目只读取、不写入新键，避免按旧提示词翻译的结果在关闭 legacy_lookup 后仍被当作新提示词的结果使用
+func (s *TranslationService) lookupCache(ctx context.Context, p *pendingText) (*cache.CacheEntry, string, bool) {
+	if entry, ok := s.readCacheEntry(ctx, p.cacheKey); ok {
+		log.Printf("Cache hit for: %s, originally translated by %s/%s",
+			p.cacheKey, entry.APIURL, entry.Model)
+		return entry, p.cacheKey, true
+	}
+
+	for _, legacyKey := range p.legacyKeys {
+		entry, ok := s.readCacheEntry(ctx, legacyKey)
+		if !ok {
+			continue
+		}
+		if s.pinnedModelMismatch(p.req, entry) {
+			continue
+		}
+		log.Printf("Cache hit (legacy key) for: %s, originally translated by %s/%s",
+			legacyKey, entry.APIURL, entry.Model)
+
+		if !s.dimensionEnabled(DimensionPrompt) {
+			if data, err := json.Marshal(entry); err == nil {
+				if err := s.cache.Set(ctx, p.cacheKey, string(data), 0); err != nil {
+					log.Printf("Failed to migrate cache entry %s to %s: %v", legacyKey, p.cacheKey, err)
+				}
+			}
+		}
+		return entry, legacyKey, true
+	}
+	return nil, "", false
+}
+
+// pinnedModelMismatch 判断旧条目是否不是由请求指定的模型翻译的；未启用 model 维度时不区分模型
+func (s *TranslationService) pinnedModelMismatch(req TranslateRequest, entry *cache.CacheEntry) bool {
+	if req.Provider == "" || req.Model == "" || !s.dimensionEnabled(DimensionModel) {
+		return false
+	}
+	return entry.Provider != req.Provider || entry.Model != req.Model
+}
+
+// dimensionEnabled 判断 v2 缓存键是否包含指定维度，v1 缓存键不包含任何维度
+func (s *TranslationService) dimensionEnabled(dimension string) bool {
+	if s.opts.CacheKeyVersion == CacheKeyV1 {
+		return false
+	}
+	for _, dim := range s.opts.CacheKeyDimensions {
+		if dim == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// readCacheEntry 读取并解析缓存条目
+func (s *TranslationService) readCacheEntry(ctx context.Context, key string) (*cache.CacheEntry, bool) {
+	cachedData, err := s.cache.Get(ctx, key)
+	if err != nil || cachedData == "" {
+		return nil, false
+	}
+	var entry cache.CacheEntry
+	if err := json.Unmarshal([]byte(cachedData), &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"transbridge/cache"
+)
+
+func TestLookupCacheLegacyMigration(t *testing.T) {
+	const (
+		newKey    = "transbridge:v2:en:zh:new"
+		legacyKey = "transbridge:legacy"
+	)
+	legacyEntry := cache.CacheEntry{Translation: "旧译文", Provider: "openai", Model: "gpt-4o"}
+
+	tests := []struct {
+		name       string
+		version    string
+		dimensions []string
+		req        TranslateRequest
+		wantHit    bool
+		wantKey    string
+		wantCopied bool
+	}{
+		{
+			name:       "prompt dimension keeps legacy entry out of v2 key",
+			dimensions: CacheKeyDimensions,
+			wantHit:    true,
+			wantKey:    legacyKey,
+		},
+		{
+			name:       "without prompt dimension legacy entry migrates",
+			dimensions: []string{DimensionModel, DimensionFormality},
+			wantHit:    true,
+			wantKey:    legacyKey,
+			wantCopied: true,
+		},
+		{
+			name:       "v1 keys migrate",
+			version:    CacheKeyV1,
+			dimensions: CacheKeyDimensions,
+			wantHit:    true,
+			wantKey:    legacyKey,
+			wantCopied: true,
+		},
+		{
+			name:       "pinned model mismatch skips legacy entry",
+			dimensions: []string{DimensionModel},
+			req:        TranslateRequest{Provider: "claude", Model: "sonnet"},
+		},
+		{
+			name:       "pinned model match uses legacy entry",
+			dimensions: []string{DimensionModel},
+			req:        TranslateRequest{Provider: "openai", Model: "gpt-4o"},
+			wantHit:    true,
+			wantKey:    legacyKey,
+			wantCopied: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memory := cache.NewMemoryCache(cache.MemoryCacheOptions{Permanent: true})
+			defer memory.Close(ctx)
+			data, _ := json.Marshal(legacyEntry)
+			memory.Set(ctx, legacyKey, string(data), 0)
+
+			s := &TranslationService{cache: memory, opts: TranslationServiceOptions{
+				CacheKeyVersion:    tt.version,
+				CacheKeyDimensions: tt.dimensions,
+			}}
+			p := &pendingText{req: tt.req, cacheKey: newKey, legacyKeys: []string{legacyKey}}
+
+			entry, key, ok := s.lookupCache(ctx, p)
+			if ok != tt.wantHit || key != tt.wantKey {
+				t.Fatalf("lookupCache() = %q, %v, want %q, %v", key, ok, tt.wantKey, tt.wantHit)
+			}
+			if ok && entry.Translation != legacyEntry.Translation {
+				t.Errorf("translation = %q, want %q", entry.Translation, legacyEntry.Translation)
+			}
+			_, err := memory.Get(ctx, newKey)
+			if copied := err == nil; copied != tt.wantCopied {
+				t.Errorf("copied to new key = %v, want %v", copied, tt.wantCopied)
+			}
+
+			// 写入新键后直接命中新键
+			if tt.wantCopied {
+				if _, key, _ := s.lookupCache(ctx, p); key != newKey {
+					t.Errorf("second lookup key = %q, want %q", key, newKey)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"transbridge/internal/utils"
+	"transbridge/translator"
+)
+
+// passageContext 翻译长文档中的一部分时携带的前后文，只用于提示词，不参与缓存键计算
+type passageContext struct {
+	previous            string // 前文原文
+	previousTranslation string // 前文译文，可能为空
+	next                string // 后文原文
+}
+
+// instructions 生成附加在提示词中的上下文说明，没有前后文时返回空字符串
+func (c *passageContext) instructions() string {
+	if c == nil || (c.previous == "" && c.next == "") {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("The text is part of a longer document. For context only, the surrounding text is:\n")
+	if c.previous != "" {
+		b.WriteString("Preceding source: " + c.previous + "\n")
+		if c.previousTranslation != "" {
+			b.WriteString("Preceding translation: " + c.previousTranslation + "\n")
+		}
+	}
+	if c.next != "" {
+		b.WriteString("Following source: " + c.next + "\n")
+	}
+	b.WriteString("Do not translate or repeat the surrounding text; use it only to keep terminology, style and sentence flow consistent.")
+	return b.String()
+}
+
+// textChunk 长文本拆分出的一块，sep 为其后的原始空白，拼接译文时原样保留
+type textChunk struct {
+	text   string
+	sep    string
+	anchor bool // 块必须在此结束，见 splitChunks
+}
+
+// chunkAnchorEvery 平均每多少个段落设置一个固定的分块边界
+const chunkAnchorEvery = 4
+
+// chunkBudget 返回单块原文允许的最大 token 数与对应模型的 token 估算方法；未启用分块或无法确定上限时返回 0
+// 请求未指定模型时可能由任一模型应答（含故障转移），按 max_tokens 最小的模型计算，保证分块方式稳定、各块缓存可复用；
+// 译文通常比原文长，未配置 ChunkMaxTokens 时每块原文只占模型输出上限的一半
+func (s *TranslationService) chunkBudget(req TranslateRequest) (int, func(string) int) {
+	if !s.opts.ChunkingEnabled {
+		return 0, nil
+	}
+
+	limit, estimate := s.modelTokenBudget(req)
+	if s.opts.ChunkMaxTokens > 0 {
+		limit = s.opts.ChunkMaxTokens
+	}
+	return limit, estimate
+}
+
+// modelTokenBudget 返回可能应答请求的模型中最小的输出上限的一半及该模型的 token 估算方法，无法确定上限时返回 0
+func (s *TranslationService) modelTokenBudget(req TranslateRequest) (int, func(string) int) {
+	limit := 0
+	estimate := utils.EstimateTokens
+	for _, id := range s.modelManager.ListModels() {
+		if req.Provider != "" && req.Model != "" && (id.Provider != req.Provider || id.Model != req.Model) {
+			continue
+		}
+		t, ok := s.modelManager.Lookup(id)
+		if !ok {
+			continue
+		}
+		budget, ok := t.(translator.TokenBudget)
+		if !ok {
+			continue
+		}
+		if n := budget.MaxOutputTokens() / 2; n > 0 && (limit == 0 || n < limit) {
+			limit = n
+			estimate = budget.EstimateTokens
+		}
+	}
+	return limit, estimate
+}
+
+// translateChunks 依次翻译各块并拼接译文
+// 每块携带上一块末尾的原文与译文作为上下文，保持术语与语气连贯；各块独立缓存，文档局部修改后未改动的块直接命中缓存
+func (s *TranslationService) translateChunks(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, chunks []textChunk, estimate func(string) int, detected *DetectResult) (*TranslateResult, error) {
+	result := &TranslateResult{CacheHit: true, Chunks: len(chunks)}
+
+	var b strings.Builder
+	b.WriteString(req.Text[:len(req.Text)-len(strings.TrimLeftFunc(req.Text, unicode.IsSpace))])
+
+	prev := &passageContext{}
+	for i, chunk := range chunks {
+		chunkReq := req
+		chunkReq.Text = chunk.text
+		chunkReq.passage = prev
+
+		res, err := s.translateText(ctx, prompts, chunkReq)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d/%d translation failed: %w", i+1, len(chunks), err)
+		}
+		b.WriteString(res.Text)
+		b.WriteString(chunk.sep)
+
+		// 以第一个实际调用模型的块作为应答模型
+		if result.Provider == "" && res.Provider != "" {
+			result.Provider, result.Model, result.APIURL = res.Provider, res.Model, res.APIURL
+		}
+		result.CacheHit = result.CacheHit && res.CacheHit
+		result.Attempts += res.Attempts
+		result.GlossaryViolations = append(result.GlossaryViolations, res.GlossaryViolations...)
+		result.cacheKeys = append(result.cacheKeys, res.cacheKeys...)
+
+		if s.opts.ChunkContextTokens > 0 {
+			prev = &passageContext{
+				previous:            tailTokens(chunk.text, s.opts.ChunkContextTokens, estimate),
+				previousTranslation: tailTokens(res.Text, s.opts.ChunkContextTokens, estimate),
+			}
+		}
+	}
+
+	b.WriteString(req.Text[len(strings.TrimRightFunc(req.Text, unicode.IsSpace)):])
+	result.Text = b.String()
+	result.setSource(req.SourceLang, detected)
+	return result, nil
+}
+
+// splitChunks 按段落、句子边界把文本拆分为不超过 maxTokens 的块；单句超长时按词（或字）强制拆分
+// 相邻的段落尽量合并到同一块，但内容哈希满足条件的段落之后总是结束当前块：
+// 文档局部修改只影响所在位置到下一个固定边界之间的块，其余块的原文不变，可以直接命中缓存
+func splitChunks(text string, maxTokens int, estimate func(string) int) []textChunk {
+	var units []textChunk
+	for _, para := range splitKeepingSeparators(strings.TrimSpace(text), isParagraphBreak) {
+		para.anchor = isChunkAnchor(para.text)
+		if estimate(para.text) <= maxTokens {
+			units = append(units, para)
+			continue
+		}
+
+		var pieces []textChunk
+		sentences := splitKeepingSeparators(para.text, isSentenceBreak)
+		sentences[len(sentences)-1].sep = para.sep
+		for _, sentence := range sentences {
+			if estimate(sentence.text) <= maxTokens {
+				pieces = append(pieces, sentence)
+				continue
+			}
+			pieces = append(pieces, hardSplit(sentence, maxTokens, estimate)...)
+		}
+		pieces[len(pieces)-1].anchor = para.anchor
+		units = append(units, pieces...)
+	}
+
+	var chunks []textChunk
+	for _, unit := range units {
+		if n := len(chunks); n > 0 && !chunks[n-1].anchor {
+			last := &chunks[n-1]
+			if merged := last.text + last.sep + unit.text; estimate(merged) <= maxTokens {
+				last.text, last.sep, last.anchor = merged, unit.sep, unit.anchor
+				continue
+			}
+		}
+		chunks = append(chunks, unit)
+	}
+	return chunks
+}
+
+// isChunkAnchor 按段落内容决定是否在其后设置固定的分块边界
+func isChunkAnchor(paragraph string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(paragraph))
+	return h.Sum32()%chunkAnchorEvery == 0
+}
+
+// splitKeepingSeparators 在 isBreak 认定的边界处拆分文本，边界处的空白记入前一片段的 sep
+// isBreak 返回 text[i] 处是否为边界及边界空白的长度（可以为 0）
+func splitKeepingSeparators(text string, isBreak func(text string, i int) (int, bool)) []textChunk {
+	var parts []textChunk
+	start := 0
+	for i := 0; i < len(text); {
+		n, ok := isBreak(text, i)
+		if !ok || (n == 0 && i == start) {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			i += size
+			continue
+		}
+		if i > start {
+			parts = append(parts, textChunk{text: text[start:i], sep: text[i : i+n]})
+		} else if len(parts) > 0 {
+			parts[len(parts)-1].sep += text[i : i+n]
+		}
+		i += n
+		start = i
+	}
+	if start < len(text) || len(parts) == 0 {
+		parts = append(parts, textChunk{text: text[start:]})
+	}
+	return parts
+}
+
+// isParagraphBreak 空行（包含两个及以上换行的空白）为段落边界
+func isParagraphBreak(text string, i int) (int, bool) {
+	if text[i] != '\n' {
+		return 0, false
+	}
+	end := i + len(text[i:]) - len(strings.TrimLeft(text[i:], " \t\r\n"))
+	if strings.Count(text[i:end], "\n") < 2 {
+		return 0, false
+	}
+	return end - i, true
+}
+
+// isSentenceBreak 句末标点之后的空白、换行为句子边界；中日文句末标点之后直接断句
+func isSentenceBreak(text string, i int) (int, bool) {
+	if i == 0 {
+		return 0, false
+	}
+	prev, _ := utf8.DecodeLastRuneInString(text[:i])
+	ws := len(text[i:]) - len(strings.TrimLeft(text[i:], " \t\r\n"))
+
+	switch {
+	case ws > 0 && (strings.ContainsRune(".!?;:", prev) || strings.Contains(text[i:i+ws], "\n")):
+		return ws, true
+	case strings.ContainsRune("。！？；", prev):
+		return ws, true
+	}
+	return 0, false
+}
+
+// hardSplit 在空白处（没有空白时按字符）强制拆分超长的句子
+func hardSplit(sentence textChunk, maxTokens int, estimate func(string) int) []textChunk {
+	var parts []textChunk
+	rest := sentence.text
+	for estimate(rest) > maxTokens {
+		cut := fitPrefix(rest, maxTokens, estimate)
+		if space := strings.LastIndexAny(rest[:cut], " \t\n"); space > 0 {
+			cut = space
+		}
+		if cut == 0 {
+			_, cut = utf8.DecodeRuneInString(rest)
+		}
+		text := rest[:cut]
+		rest = rest[cut:]
+		trimmed := strings.TrimLeft(rest, " \t\n")
+		parts = append(parts, textChunk{text: text, sep: rest[:len(rest)-len(trimmed)]})
+		rest = trimmed
+	}
+	return append(parts, textChunk{text: rest, sep: sentence.sep})
+}
+
+// tailTokens 返回文本末尾不超过 maxTokens 的部分，尽量从句子开头截取
+func tailTokens(text string, maxTokens int, estimate func(string) int) string {
+	text = strings.TrimSpace(text)
+	if estimate(text) <= maxTokens {
+		return text
+	}
+
+	sentences := splitKeepingSeparators(text, isSentenceBreak)
+	tail := ""
+	for i := len(sentences) - 1; i >= 0; i-- {
+		candidate := sentences[i].text + sentences[i].sep + tail
+		if estimate(candidate) > maxTokens {
+			break
+		}
+		tail = candidate
+	}
+	if tail != "" {
+		return strings.TrimSpace(tail)
+	}
+
+	// 最后一句也超出上限时按字符截取
+	start := sort.Search(len(text), func(i int) bool {
+		return estimate(text[runeStart(text, i):]) <= maxTokens
+	})
+	return text[runeStart(text, start):]
+}
+
+// fitPrefix 返回不超过 maxTokens 的最长前缀长度（按字符边界对齐）
+func fitPrefix(text string, maxTokens int, estimate func(string) int) int {
+	n := sort.Search(len(text)+1, func(i int) bool {
+		return estimate(text[:runeStart(text, i)]) > maxTokens
+	}) - 1
+	return runeStart(text, n)
+}
+
+// runeStart 将字节位置 i 向前对齐到字符起始位置
+func runeStart(text string, i int) int {
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"transbridge/internal/utils"
+)
+
+// joinChunks 按分隔符拼接各块，还原拆分前的文本
+func joinChunks(chunks []textChunk) string {
+	var b strings.Builder
+	for _, c := range chunks {
+		b.WriteString(c.text)
+		b.WriteString(c.sep)
+	}
+	return b.String()
+}
+
+// testParagraphs 生成 n 个互不相同的英文段落
+func testParagraphs(n int) []string {
+	paragraphs := make([]string, n)
+	for i := range paragraphs {
+		paragraphs[i] = fmt.Sprintf("Paragraph %d explains one step of the setup. It has a second sentence, too.", i)
+	}
+	return paragraphs
+}
+
+func TestSplitChunks(t *testing.T) {
+	longSentence := strings.Repeat("word ", 200)
+	tests := []struct {
+		name       string
+		text       string
+		maxTokens  int
+		wantChunks int // 0 表示不检查块数
+	}{
+		{name: "short text stays whole", text: "Hello, world.", maxTokens: 100, wantChunks: 1},
+		{name: "paragraphs over budget", text: strings.Join(testParagraphs(30), "\n\n"), maxTokens: 60},
+		{name: "paragraphs with mixed blank lines", text: strings.Join(testParagraphs(10), "\n \n\n"), maxTokens: 40},
+		{name: "long paragraph split by sentence", text: strings.Repeat("This sentence is short. ", 40), maxTokens: 30},
+		{name: "single sentence split by word", text: longSentence, maxTokens: 25},
+		{name: "cjk text split by character", text: strings.Repeat("这是一段没有标点的很长的中文文本", 20), maxTokens: 50},
+		{name: "cjk sentences", text: strings.Repeat("这是第一句。这是第二句！", 30), maxTokens: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitChunks(tt.text, tt.maxTokens, utils.EstimateTokens)
+			if got, want := joinChunks(chunks), strings.TrimSpace(tt.text); got != want {
+				t.Errorf("joined chunks differ from the text:\n got %q\nwant %q", got, want)
+			}
+			for i, c := range chunks {
+				if c.text == "" {
+					t.Errorf("chunk %d is empty", i)
+				}
+				if n := utils.EstimateTokens(c.text); n > tt.maxTokens {
+					t.Errorf("chunk %d has %d tokens, budget %d", i, n, tt.maxTokens)
+				}
+			}
+			if tt.wantChunks > 0 && len(chunks) != tt.wantChunks {
+				t.Errorf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+		})
+	}
+}
+
+func TestSplitChunksLocalEdit(t *testing.T) {
+	const maxTokens = 80
+	paragraphs := testParagraphs(60)
+
+	for _, edited := range []int{0, 17, 42} {
+		t.Run(fmt.Sprintf("edit paragraph %d", edited), func(t *testing.T) {
+			changed := append([]string(nil), paragraphs...)
+			changed[edited] = "This paragraph was rewritten."
+
+			before := splitChunks(strings.Join(paragraphs, "\n\n"), maxTokens, utils.EstimateTokens)
+			after := splitChunks(strings.Join(changed, "\n\n"), maxTokens, utils.EstimateTokens)
+
+			// 修改的段落之后第一个固定边界以后的块不变；修改的段落本身是否为边界在修改前后可能不同
+			anchor := -1
+			for i := edited + 1; i < len(changed); i++ {
+				if isChunkAnchor(changed[i]) {
+					anchor = i
+					break
+				}
+			}
+			if anchor < 0 {
+				t.Skip("no chunk anchor after the edited paragraph")
+			}
+			stable := strings.Join(changed[anchor+1:], "\n\n")
+
+			common := 0
+			for common < len(before) && common < len(after) &&
+				before[len(before)-1-common] == after[len(after)-1-common] {
+				common++
+			}
+			if suffix := joinChunks(after[len(after)-common:]); len(suffix) < len(stable) {
+				t.Errorf("only the last %d bytes are chunked identically, want at least %d after paragraph %d", len(suffix), len(stable), anchor)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"transbridge/logger"
+)
+
+// ReasonCoalesced 与同一文本的并发请求共享译文、未单独调用模型时记录的原因
+const ReasonCoalesced = "coalesced"
+
+// lockPollInterval 等待其它实例翻译时查询缓存的间隔
+const lockPollInterval = 200 * time.Millisecond
+
+// Locker 跨实例的翻译锁，同一缓存键同时只有一个实例调用模型
+type Locker interface {
+	// Acquire 尝试获取锁，锁在 ttl 后自动过期；获取成功时返回释放函数
+	Acquire(ctx context.Context, key string, ttl time.Duration) (release func(), ok bool, err error)
+}
+
+// flight 正在进行的一次模型翻译
+type flight struct {
+	done     chan struct{}
+	result   *TranslateResult
+	err      error
+	canceled bool // 发起翻译的请求已取消，等待者需要重新发起
+}
+
+// flightGroup 合并同一缓存键的并发翻译：第一个请求调用模型，其余请求等待并共享结果
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do 执行或等待 key 对应的翻译，leader 表示本次调用是否实际执行了 fn
+// 等待者的上下文取消时立即返回；执行者的上下文取消导致失败时，等待者重新竞争执行，而不是收到对方的取消错误
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*TranslateResult, error)) (result *TranslateResult, leader bool, err error) {
+	for {
+		g.mu.Lock()
+		if g.flights == nil {
+			g.flights = make(map[string]*flight)
+		}
+		if f, ok := g.flights[key]; ok {
+			g.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			}
+			if f.canceled {
+				continue
+			}
+			return f.result, false, f.err
+		}
+
+		f := &flight{done: make(chan struct{}), err: errors.New("translation aborted")}
+		g.flights[key] = f
+		g.mu.Unlock()
+
+		func() {
+			defer func() {
+				g.mu.Lock()
+				delete(g.flights, key)
+				g.mu.Unlock()
+				close(f.done)
+			}()
+			f.result, f.err = fn()
+			f.canceled = f.err != nil && ctx.Err() != nil
+		}()
+		return f.result, true, f.err
+	}
+}
+
+// translatePending 调用模型翻译未命中缓存的文本
+// 同一缓存键的并发请求只有一个调用模型，其余等待并共享结果；配置了 Locker 时还与其它实例协调，见 translateExclusive
+func (s *TranslationService) translatePending(ctx context.Context, p *pendingText) (*TranslateResult, error) {
+	result, leader, err := s.flights.do(ctx, p.cacheKey, func() (*TranslateResult, error) {
+		return s.translateExclusive(ctx, p)
+	})
+	if err != nil || leader {
+		return result, err
+	}
+
+	shared := *result
+	shared.Attempts = 0
+	shared.Reason = ReasonCoalesced
+	shared.Detected, shared.DetectConfidence = false, 0
+	shared.setSource(p.req.SourceLang, p.detected)
+
+	s.logTranslation(logger.TranslationRecord{
+		SourceText:  p.req.Text,
+		TargetText:  shared.Text,
+		SourceLang:  p.req.SourceLang,
+		TargetLang:  p.req.TargetLang,
+		APIURL:      shared.APIURL,
+		Provider:    shared.Provider,
+		Model:       shared.Model,
+		CacheKey:    p.cacheKey,
+		CacheHit:    shared.CacheHit,
+		ProcessTime: float64(time.Since(p.start).Milliseconds()),
+		Reason:      ReasonCoalesced,
+		Prompt:      p.selected.Name,
+	})
+	return &shared, nil
+}
+
+// translateExclusive 持有分布式锁调用模型；锁被其它实例持有时等待其写入缓存
+// 等待超时、对方未写入缓存即释放锁或 Redis 不可用时自行翻译
+func (s *TranslationService) translateExclusive(ctx context.Context, p *pendingText) (*TranslateResult, error) {
+	if s.opts.Locker != nil && s.cache != nil {
+		release, result, err := s.acquireOrWait(ctx, p)
+		if err != nil || result != nil {
+			return result, err
+		}
+		if release != nil {
+			defer release()
+		}
+	}
+
+	translation, usedTranslator, failedModels, err := s.translateWithFailover(ctx, p.prompt, p.req)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeText(ctx, p, translation, usedTranslator, failedModels), nil
+}
+
+// acquireOrWait 获取 p 的缓存键对应的分布式锁；锁被占用时轮询缓存，直到其它实例写入译文、锁被释放或等待超时
+// 返回释放函数（未获取锁时为 nil），或其它实例写入缓存的结果
+func (s *TranslationService) acquireOrWait(ctx context.Context, p *pendingText) (func(), *TranslateResult, error) {
+	deadline := time.Now().Add(s.opts.LockWait)
+	for {
+		release, ok, err := s.opts.Locker.Acquire(ctx, p.cacheKey, s.opts.LockTTL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			log.Printf("Failed to acquire translation lock, translating without it: %v", err)
+			return nil, nil, nil
+		}
+		if ok {
+			// 获取锁之前其它实例可能刚好完成翻译
+			if result := s.cachedResult(ctx, p); result != nil {
+				release()
+				return nil, result, nil
+			}
+			return release, nil, nil
+		}
+
+		select {
+		case <-time.After(lockPollInterval):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		if result := s.cachedResult(ctx, p); result != nil {
+			return nil, result, nil
+		}
+		if time.Now().After(deadline) {
+			log.Printf("Timed out waiting for another instance to translate %s, translating locally", p.cacheKey)
+			return nil, nil, nil
+		}
+	}
+}
+
+// cachedResult 查找 p 的缓存，命中时记录日志并返回结果
+func (s *TranslationService) cachedResult(ctx context.Context, p *pendingText) *TranslateResult {
+	entry, hitKey, ok := s.lookupCache(ctx, p)
+	if !ok {
+		return nil
+	}
+
+	violations := s.checkGlossary(entry.Translation, p.terms)
+	s.logTranslation(logger.TranslationRecord{
+		SourceText:  p.req.Text,
+		TargetText:  entry.Translation,
+		SourceLang:  p.req.SourceLang,
+		TargetLang:  p.req.TargetLang,
+		APIURL:      entry.APIURL,
+		Provider:    entry.Provider,
+		Model:       entry.Model,
+		CacheKey:    hitKey,
+		CacheHit:    true,
+		ProcessTime: float64(time.Since(p.start).Milliseconds()),
+		Prompt:      p.selected.Name,
+
+		GlossaryViolations: violations,
+	})
+	result := &TranslateResult{
+		Text:     entry.Translation,
+		Provider: entry.Provider,
+		Model:    entry.Model,
+		APIURL:   entry.APIURL,
+		CacheHit: true,
+
+		GlossaryViolations: violations,
+
+		cacheKeys: []string{hitKey},
+	}
+	if hitKey != p.cacheKey {
+		// 旧键命中时条目已迁移到当前缓存键
+		result.cacheKeys = append(result.cacheKeys, p.cacheKey)
+	}
+	result.setSource(p.req.SourceLang, p.detected)
+	return result
+}
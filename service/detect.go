@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"transbridge/internal/utils"
+)
+
+// detectPromptTemplate 让模型识别文本语言的提示词
+const detectPromptTemplate = "Identify the language of the following text. " +
+	"Reply with only its ISO 639-1 code (for example: en, zh, ja) and nothing else.\n\nText:\n{{input}}"
+
+// llmDetectConfidence 模型检测结果的置信度（模型不提供概率，取经验值）
+const llmDetectConfidence = 0.9
+
+// DetectResult 语言检测结果
+type DetectResult struct {
+	Language   string  // BCP 47 语言代码，例如 "en"、"zh-Hans"
+	Confidence float64 // 置信度（0~1）
+}
+
+// DetectLanguage 识别文本语言
+// 优先使用本地检测器；置信度低于 DetectMinConfidence 且开启了 LLMDetectFallback 时改用模型识别，
+// 模型识别失败时仍返回本地检测结果
+func (s *TranslationService) DetectLanguage(ctx context.Context, text string) (*DetectResult, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+
+	local := s.detector.Detect(text)
+	if local.Language != "" && local.Confidence >= s.opts.DetectMinConfidence {
+		return &DetectResult{Language: local.Language, Confidence: local.Confidence}, nil
+	}
+
+	if s.opts.LLMDetectFallback {
+		result, err := s.detectWithLLM(ctx, text)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("LLM language detection failed, using local result %q: %v", local.Language, err)
+	}
+
+	if local.Language == "" {
+		return nil, fmt.Errorf("language detection failed: unable to identify language")
+	}
+	return &DetectResult{Language: local.Language, Confidence: local.Confidence}, nil
+}
+
+// detectWithLLM 使用翻译模型识别文本语言，结果会写入缓存
+func (s *TranslationService) detectWithLLM(ctx context.Context, text string) (*DetectResult, error) {
+	var cacheKey string
+	if s.cache != nil {
+		cacheKey = utils.DetectCacheKey(text)
+		if code, err := s.cache.Get(ctx, cacheKey); err == nil && code != "" {
+			return &DetectResult{Language: code, Confidence: llmDetectConfidence}, nil
+		}
+	}
+
+	reply, usedTranslator, _, err := s.translateWithFailover(ctx, utils.PromptTemplate{Template: detectPromptTemplate}, TranslateRequest{Text: text})
+	if err != nil {
+		return nil, fmt.Errorf("language detection failed: %w", err)
+	}
+
+	code := parseLanguageReply(reply)
+	if code == "" {
+		return nil, fmt.Errorf("language detection failed: unexpected reply %q from %s/%s",
+			reply, usedTranslator.GetProvider(), usedTranslator.GetModel())
+	}
+
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, cacheKey, code, 0); err != nil {
+			log.Printf("Failed to cache detected language: %v", err)
+		}
+	}
+
+	return &DetectResult{Language: code, Confidence: llmDetectConfidence}, nil
+}
+
+// parseLanguageReply 从模型回复中提取语言代码，例如 "EN." -> "en"
+func parseLanguageReply(reply string) string {
+	// 优先把整条回复当作语言代码，再逐词查找，避免误把 "is" 等普通单词识别为语言代码
+	fields := append([]string{reply}, strings.Fields(reply)...)
+	for _, field := range fields {
+		code := strings.ToLower(strings.Trim(field, " \t\r\n.,;:!?\"'`()[]"))
+		if utils.IsValidLanguageCode(code) {
+			return code
+		}
+		if normalized := utils.NormalizeLanguageCode(code); normalized != "" && utils.IsValidLanguageCode(utils.BaseLanguage(normalized)) {
+			return normalized
+		}
+	}
+	return ""
+}
+
+// isAutoLanguage 判断源语言是否需要自动检测
+func isAutoLanguage(lang string) bool {
+	return utils.NormalizeLanguageCode(lang) == ""
+}
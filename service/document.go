@@ -0,0 +1,23 @@
+package service
+
+// DocumentResult 文档翻译结果
+type DocumentResult struct {
+	Text   string
+	Format string // 文档格式，例如字幕的 srt、vtt
+
+	SourceLang       string  // 实际使用的源语言；请求未指定时为检测结果，检测失败时为空
+	Detected         bool    // 源语言是否由自动检测得出
+	DetectConfidence float64 // 自动检测的置信度（0~1）
+
+	Segments  int // 文档中需要翻译的正文片段数
+	CacheHits int // 直接命中缓存的片段数
+}
+
+// setSource 记录实际使用的源语言及检测信息
+func (r *DocumentResult) setSource(sourceLang string, detected *DetectResult) {
+	r.SourceLang = sourceLang
+	if detected != nil {
+		r.Detected = true
+		r.DetectConfidence = detected.Confidence
+	}
+}
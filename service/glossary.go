@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"log"
+	"transbridge/glossary"
+	"transbridge/internal/utils"
+	"transbridge/translator"
+)
+
+// applyGlossary 将文本中出现的术语作为说明放在提示词模板之前
+func applyGlossary(prompt utils.PromptTemplate, terms []glossary.Term) utils.PromptTemplate {
+	if len(terms) == 0 {
+		return prompt
+	}
+	prompt.Template = glossary.Instructions(terms) + "\n" + prompt.Template
+	return prompt
+}
+
+// enforceGlossary 校验译文是否遵守术语表，返回最终译文、应答的翻译器、额外尝试的模型数以及仍未遵守的术语
+// repair 模式下会指明违规术语重新翻译一次，违规更少时采用新译文
+func (s *TranslationService) enforceGlossary(ctx context.Context, prompt utils.PromptTemplate, req TranslateRequest, terms []glossary.Term,
+	translation string, usedTranslator translator.Translator) (string, translator.Translator, int, []string) {
+	if len(terms) == 0 || s.opts.GlossaryEnforcement == glossary.EnforcementOff {
+		return translation, usedTranslator, 0, nil
+	}
+
+	extraAttempts := 0
+	violations := glossary.Check(translation, terms)
+	if len(violations) > 0 && s.opts.GlossaryEnforcement == glossary.EnforcementRepair {
+		log.Printf("Glossary violations in translation by %s/%s, retrying: %v",
+			usedTranslator.GetProvider(), usedTranslator.GetModel(), violations)
+
+		repairPrompt := prompt
+		repairPrompt.Template = glossary.RepairInstructions(violations) + "\n" + prompt.Template
+		repaired, repairTranslator, failedModels, err := s.translateWithFailover(ctx, repairPrompt, req)
+		extraAttempts += len(failedModels)
+		if err != nil {
+			log.Printf("Glossary repair failed, keeping original translation: %v", err)
+		} else {
+			extraAttempts++
+			if remaining := glossary.Check(repaired, terms); len(remaining) < len(violations) {
+				translation, usedTranslator, violations = repaired, repairTranslator, remaining
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return translation, usedTranslator, extraAttempts, nil
+	}
+	flagged := flagViolations(violations)
+	log.Printf("Glossary violations remain in translation: %v", flagged)
+	return translation, usedTranslator, extraAttempts, flagged
+}
+
+// checkGlossary 校验缓存中的译文是否遵守术语表，返回未遵守的术语；flag 模式下违反术语表的译文同样会写入缓存
+func (s *TranslationService) checkGlossary(translation string, terms []glossary.Term) []string {
+	if len(terms) == 0 || s.opts.GlossaryEnforcement == glossary.EnforcementOff {
+		return nil
+	}
+	if violations := glossary.Check(translation, terms); len(violations) > 0 {
+		return flagViolations(violations)
+	}
+	return nil
+}
+
+// flagViolations 将违规术语转为 "原文 => 译文" 形式，用于日志和翻译结果
+func flagViolations(violations []glossary.Violation) []string {
+	flagged := make([]string, len(violations))
+	for i, v := range violations {
+		flagged[i] = v.String()
+	}
+	return flagged
+}
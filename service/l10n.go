@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"transbridge/internal/utils"
+	"transbridge/l10n"
+)
+
+// LocalizationResult 本地化文件翻译结果，Segments 为翻译的条目数
+type LocalizationResult struct {
+	DocumentResult
+	TargetLang string   // 实际使用的目标语言；请求未指定时取自文件
+	Skipped    int      // 已有译文或原文为空而跳过的条目数
+	Fuzzy      []string // 占位符校验未通过、标记为待审校的条目
+}
+
+// TranslateLocalization 翻译 PO、i18n JSON 或 XLIFF 本地化文件，默认跳过已翻译与待审校的条目
+// ICU/printf 占位符与标签替换为占位符后翻译，逐条校验译文中的占位符；校验失败的条目写入译文但标记为待审校
+// （PO 的 fuzzy 标志、XLIFF 的 needs-review 状态；JSON 没有对应的标记，保留原文），不会写入损坏的译文
+func (s *TranslationService) TranslateLocalization(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, opts l10n.Options) (*LocalizationResult, error) {
+	if req.Text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+
+	file, err := l10n.Parse(req.Text, opts)
+	if err != nil {
+		return nil, err
+	}
+	if req.TargetLang == "" {
+		req.TargetLang = file.TargetLanguage()
+	}
+	if req.TargetLang == "" {
+		return nil, fmt.Errorf("target language is required")
+	}
+	if req.SourceLang == "" {
+		req.SourceLang = file.SourceLanguage()
+	}
+
+	result := &LocalizationResult{TargetLang: req.TargetLang}
+	result.Format = file.Format()
+
+	var entries []*l10n.Entry
+	for _, e := range file.Entries() {
+		if opts.Selects(e) {
+			entries = append(entries, e)
+		} else {
+			result.Skipped++
+		}
+	}
+	result.Segments = len(entries)
+
+	// 每个条目对应的原文：复数条目依次为各个 msgstr 的原文，只有一个 msgstr 的语言（如中文）直接使用复数形式
+	sources := make([][]string, len(entries))
+	var plain []string
+	for i, e := range entries {
+		switch {
+		case e.SourcePlural == "":
+			sources[i] = []string{e.Source}
+		case len(e.Translations) == 1:
+			sources[i] = []string{e.SourcePlural}
+		default:
+			sources[i] = []string{e.Source}
+			for range e.Translations[1:] {
+				sources[i] = append(sources[i], e.SourcePlural)
+			}
+		}
+		plain = append(plain, l10n.ParseMessage(e.Source, e.XML).PlainText())
+	}
+
+	if len(entries) > 0 {
+		// 界面文案大多很短，在所有条目上检测一次源语言
+		detected, err := s.resolveSourceLang(ctx, &req, strings.Join(plain, "\n"))
+		if err != nil {
+			return nil, err
+		}
+		result.setSource(req.SourceLang, detected)
+	} else {
+		result.SourceLang = utils.NormalizeLanguageCode(req.SourceLang)
+	}
+
+	var requests []TranslateRequest
+	for i, e := range entries {
+		for _, text := range sources[i] {
+			entryReq := req
+			entryReq.Text = text
+			entryReq.TagHandling = "l10n"
+			if e.XML {
+				entryReq.TagHandling = "l10n-xml"
+			}
+			requests = append(requests, entryReq)
+		}
+	}
+
+	results := s.BatchTranslate(ctx, prompts, requests)
+	next := 0
+	for i, e := range entries {
+		translations := make([]string, len(sources[i]))
+		fuzzy := false
+		for j, source := range sources[i] {
+			res := results[next]
+			next++
+			if res.Error != nil {
+				return nil, fmt.Errorf("entry %q translation failed: %w", e.Key, res.Error)
+			}
+			translations[j] = res.Text
+			if res.Result.CacheHit {
+				result.CacheHits++
+			}
+
+			// 整体还原占位符失败时 translateDocument 会逐段翻译，占位符完整但语序不可靠，同样需要审校
+			if res.Result.TagFallback {
+				fuzzy = true
+			} else if err := l10n.CheckPlaceholders(source, res.Text, e.XML); err != nil {
+				log.Printf("Placeholder check failed for entry %q: %v", e.Key, err)
+				fuzzy = true
+			}
+		}
+
+		e.SetTranslation(translations, fuzzy)
+		if fuzzy {
+			result.Fuzzy = append(result.Fuzzy, e.Key)
+		}
+	}
+
+	file.SetTargetLanguage(req.TargetLang)
+	if result.Text, err = file.Render(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"transbridge/internal/utils"
+	"transbridge/markdown"
+	"transbridge/markup"
+)
+
+// TranslateMarkdown 翻译 Markdown 文档，只翻译正文，代码块、链接地址、front matter 的键等保持不变
+// 各正文片段通过 BatchTranslate 分别翻译与缓存，文档局部修改后未改动的段落直接命中缓存
+func (s *TranslationService) TranslateMarkdown(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, opts markdown.Options) (*DocumentResult, error) {
+	if req.Text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if req.TargetLang == "" {
+		return nil, fmt.Errorf("target language is required")
+	}
+
+	doc := markdown.Parse(req.Text, opts)
+	segments := doc.Segments()
+	result := &DocumentResult{Segments: len(segments)}
+	if len(segments) == 0 {
+		result.Text = req.Text
+		result.SourceLang = utils.NormalizeLanguageCode(req.SourceLang)
+		return result, nil
+	}
+
+	// 在整篇正文上检测一次源语言，各片段使用相同的源语言，避免短标题、单元格检测不准
+	plain := make([]string, len(segments))
+	for i, seg := range segments {
+		if seg.HTML {
+			plain[i] = markup.Parse(seg.Text, "html").PlainText()
+		} else {
+			plain[i] = markdown.ParseInline(seg.Text).PlainText()
+		}
+	}
+	detected, err := s.resolveSourceLang(ctx, &req, strings.Join(plain, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	result.setSource(req.SourceLang, detected)
+
+	requests := make([]TranslateRequest, len(segments))
+	for i, seg := range segments {
+		segReq := req
+		segReq.Text = seg.Text
+		segReq.TagHandling = "markdown"
+		if seg.HTML {
+			segReq.TagHandling = "html"
+		}
+		requests[i] = segReq
+	}
+
+	translations := make([]string, len(segments))
+	for i, res := range s.BatchTranslate(ctx, prompts, requests) {
+		if res.Error != nil {
+			return nil, fmt.Errorf("segment %d translation failed: %w", i+1, res.Error)
+		}
+		translations[i] = res.Text
+		if res.Result.CacheHit {
+			result.CacheHits++
+		}
+	}
+
+	result.Text = doc.Render(translations)
+	return result, nil
+}
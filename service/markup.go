@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"transbridge/internal/utils"
+	"transbridge/l10n"
+	"transbridge/markdown"
+	"transbridge/markup"
+	"transbridge/subtitle"
+)
+
+// translateMarkup 翻译 HTML/XML 片段、Markdown 行内文本、字幕文本或本地化文件中的界面文案
+func (s *TranslationService) translateMarkup(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (*TranslateResult, error) {
+	return s.translateDocument(ctx, prompts, req, parseMarkup(req.Text, req.TagHandling))
+}
+
+// hasMarkup 判断 tagHandling 是否需要保护标签后再翻译
+func hasMarkup(tagHandling string) bool {
+	switch tagHandling {
+	case "html", "xml", "markdown", "subtitle", "l10n", "l10n-xml":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseMarkup 按 tagHandling 解析文本
+func parseMarkup(text, tagHandling string) *markup.Document {
+	switch tagHandling {
+	case "markdown":
+		return markdown.ParseInline(text)
+	case "subtitle":
+		return subtitle.ParseCueText(text)
+	case "l10n", "l10n-xml":
+		return l10n.ParseMessage(text, tagHandling == "l10n-xml")
+	default:
+		return markup.Parse(text, tagHandling)
+	}
+}
+
+// translateDocument 翻译已解析的标记文档
+// 标签先替换为占位符再整体翻译，还原后校验标签结构；还原失败时改为逐个翻译文本节点，保证标签结构不被破坏
+func (s *TranslationService) translateDocument(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, doc *markup.Document) (*TranslateResult, error) {
+	if len(doc.TextNodes()) == 0 {
+		// 只有标签没有文本，无需翻译
+		return &TranslateResult{Text: req.Text, SourceLang: utils.NormalizeLanguageCode(req.SourceLang)}, nil
+	}
+
+	// 在去掉标签的文本上检测语言，避免标签名干扰检测结果
+	detected, err := s.resolveSourceLang(ctx, &req, doc.PlainText())
+	if err != nil {
+		return nil, err
+	}
+
+	protectedReq := req
+	protectedReq.Text = doc.Protected()
+	result, err := s.translateText(ctx, prompts, protectedReq)
+	if err != nil {
+		return nil, err
+	}
+	result.setSource(req.SourceLang, detected)
+
+	if result.Reason == ReasonSameLanguage {
+		result.Text = req.Text
+		return result, nil
+	}
+
+	restored, err := doc.Restore(result.Text)
+	if err == nil {
+		result.Text = restored
+		return result, nil
+	}
+
+	log.Printf("Failed to restore %s markup from %s (%v), falling back to per-text-node translation",
+		req.TagHandling, result.ModelName(), err)
+	s.dropCache(ctx, result.cacheKeys)
+	return s.translateTextNodes(ctx, prompts, req, doc, detected)
+}
+
+// dropCache 删除无法还原标签的译文缓存，避免之后的请求再次命中后回退
+func (s *TranslationService) dropCache(ctx context.Context, keys []string) {
+	if s.cache == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete cached translation %s: %v", key, err)
+		}
+	}
+}
+
+// translateTextNodes 逐个翻译文本节点并按原始标签重建片段
+func (s *TranslationService) translateTextNodes(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, doc *markup.Document, detected *DetectResult) (*TranslateResult, error) {
+	nodes := doc.TextNodes()
+	requests := make([]TranslateRequest, len(nodes))
+	for i, node := range nodes {
+		nodeReq := req
+		nodeReq.Text = node
+		nodeReq.TagHandling = ""
+		requests[i] = nodeReq
+	}
+
+	result := &TranslateResult{TagFallback: true, CacheHit: true}
+	translations := make([]string, len(nodes))
+	for i, res := range s.BatchTranslate(ctx, prompts, requests) {
+		if res.Error != nil {
+			return nil, fmt.Errorf("text node translation failed: %w", res.Error)
+		}
+		translations[i] = res.Text
+
+		// 以第一个实际调用模型的节点作为应答模型
+		if result.Provider == "" && res.Result.Provider != "" {
+			result.Provider, result.Model, result.APIURL = res.Result.Provider, res.Result.Model, res.Result.APIURL
+		}
+		result.CacheHit = result.CacheHit && res.Result.CacheHit
+		result.Attempts += res.Result.Attempts
+	}
+
+	result.Text = doc.Rebuild(translations)
+	result.setSource(req.SourceLang, detected)
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"sort"
+	"time"
+	"transbridge/glossary"
+	"transbridge/internal/utils"
+	"transbridge/logger"
+	"transbridge/tm"
+)
+
+// ReasonTranslationMemory 采用翻译记忆中相似原文的译文、未调用模型时记录的原因
+const ReasonTranslationMemory = "translation_memory"
+
+// maxBatchReferences 合并翻译时一次调用最多附带的参考译文数
+const maxBatchReferences = 10
+
+// applyMemory 将翻译记忆中的参考译文作为说明放在提示词模板之前
+func applyMemory(prompt utils.PromptTemplate, references []tm.Match) utils.PromptTemplate {
+	if len(references) == 0 {
+		return prompt
+	}
+	prompt.Template = tm.Instructions(references) + "\n" + prompt.Template
+	return prompt
+}
+
+// searchMemory 在翻译记忆中查找相似原文：相似度达到 MemoryReuseThreshold、数字、占位符与否定词与原文一致
+// 且译文符合术语表时直接返回该译文，否则把相似度达到 MemoryReferenceThreshold 的条目作为参考译文附加到 p 的提示词中
+// 只用于纯文本请求，带标记或上下文的文本不查找
+func (s *TranslationService) searchMemory(p *pendingText) *TranslateResult {
+	req := p.req
+	if s.opts.Memory == nil || req.SourceLang == "" || req.TagHandling != "" || req.passage != nil {
+		return nil
+	}
+
+	matches := s.opts.Memory.Search(req.SourceLang, req.TargetLang, req.Text, s.opts.MemoryReferenceThreshold, s.opts.MemoryMaxReferences)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	if best := matches[0]; best.Score >= s.opts.MemoryReuseThreshold && tm.Reusable(req.Text, best.Source) && s.followsGlossary(best.Target, p.terms) {
+		s.logTranslation(logger.TranslationRecord{
+			SourceText:  req.Text,
+			TargetText:  best.Target,
+			SourceLang:  req.SourceLang,
+			TargetLang:  req.TargetLang,
+			CacheKey:    p.cacheKey,
+			ProcessTime: float64(time.Since(p.start).Milliseconds()),
+			Reason:      ReasonTranslationMemory,
+			Prompt:      p.selected.Name,
+			MemoryScore: best.Score,
+		})
+		result := &TranslateResult{Text: best.Target, Reason: ReasonTranslationMemory, MemoryScore: best.Score}
+		result.setSource(req.SourceLang, p.detected)
+		return result
+	}
+
+	p.references = matches
+	p.prompt = applyMemory(p.prompt, matches)
+	return nil
+}
+
+// followsGlossary 判断译文是否遵守文本中出现的术语，术语校验关闭时不检查
+func (s *TranslationService) followsGlossary(translation string, terms []glossary.Term) bool {
+	return s.opts.GlossaryEnforcement == glossary.EnforcementOff || len(glossary.Check(translation, terms)) == 0
+}
+
+// learnMemory 将模型给出的纯文本译文写入翻译记忆
+func (s *TranslationService) learnMemory(p *pendingText, translation string) {
+	req := p.req
+	if !s.opts.MemoryLearn || req.SourceLang == "" || req.TagHandling != "" || req.passage != nil {
+		return
+	}
+	s.opts.Memory.Learn(req.SourceLang, req.TargetLang, req.Text, translation)
+}
+
+// mergeReferences 合并一组文本的参考译文，去掉重复原文后按相似度取前 maxBatchReferences 条
+func mergeReferences(pending []*pendingText, group []int) []tm.Match {
+	var references []tm.Match
+	seen := make(map[string]bool)
+	for _, idx := range group {
+		for _, match := range pending[idx].references {
+			if !seen[match.Source] {
+				seen[match.Source] = true
+				references = append(references, match)
+			}
+		}
+	}
+	sort.SliceStable(references, func(i, j int) bool {
+		return references[i].Score > references[j].Score
+	})
+	if len(references) > maxBatchReferences {
+		references = references[:maxBatchReferences]
+	}
+	return references
+}
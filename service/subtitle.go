@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"transbridge/internal/utils"
+	"transbridge/subtitle"
+)
+
+// TranslateSubtitles 翻译 SRT/WebVTT 字幕，序号、时间轴与样式标签保持不变
+// 每条字幕携带前后各一条字幕作为上下文，通过 BatchTranslate 并发翻译并分别缓存；bilingual 为 true 时输出原文在上、译文在下的双语字幕
+func (s *TranslationService) TranslateSubtitles(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, bilingual bool) (*DocumentResult, error) {
+	if req.Text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if req.TargetLang == "" {
+		return nil, fmt.Errorf("target language is required")
+	}
+
+	file := subtitle.Parse(req.Text)
+	cues := file.Cues()
+	result := &DocumentResult{Format: file.Format, Segments: len(cues)}
+	if len(cues) == 0 {
+		result.Text = req.Text
+		result.SourceLang = utils.NormalizeLanguageCode(req.SourceLang)
+		return result, nil
+	}
+
+	// 去掉样式标签的字幕文本，用于语言检测与上下文
+	plain := make([]string, len(cues))
+	for i, cue := range cues {
+		plain[i] = subtitle.ParseCueText(cue.Text).PlainText()
+	}
+	detected, err := s.resolveSourceLang(ctx, &req, strings.Join(plain, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	result.setSource(req.SourceLang, detected)
+
+	requests := make([]TranslateRequest, len(cues))
+	for i, cue := range cues {
+		cueReq := req
+		cueReq.Text = cue.Text
+		cueReq.TagHandling = "subtitle"
+		cueReq.passage = &passageContext{}
+		if i > 0 {
+			cueReq.passage.previous = plain[i-1]
+		}
+		if i+1 < len(cues) {
+			cueReq.passage.next = plain[i+1]
+		}
+		requests[i] = cueReq
+	}
+
+	translations := make([]string, len(cues))
+	for i, res := range s.BatchTranslate(ctx, prompts, requests) {
+		if res.Error != nil {
+			return nil, fmt.Errorf("cue %d translation failed: %w", i+1, res.Error)
+		}
+		translations[i] = res.Text
+		if res.Result.CacheHit {
+			result.CacheHits++
+		}
+	}
+
+	result.Text = file.Render(translations, bilingual)
+	return result, nil
+}
@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 	"transbridge/cache"
+	"transbridge/detector"
+	"transbridge/glossary"
 	"transbridge/internal/utils"
 	"transbridge/logger"
+	"transbridge/markup"
+	"transbridge/tm"
 	"transbridge/translator"
 )
 
@@ -18,8 +23,53 @@ type TranslationService struct {
 	modelManager *translator.ModelManager
 	cache        cache.Cache
 	logger       *logger.TranslationLogger // 新增日志记录器
+	detector     *detector.LocalDetector
+	opts         TranslationServiceOptions
+	flights      flightGroup // 合并同一缓存键的并发翻译
 }
 
+// TranslationServiceOptions 翻译服务选项
+type TranslationServiceOptions struct {
+	FailoverEnabled bool // 模型出错时是否切换到下一个候选模型
+	MaxAttempts     int  // 单次翻译最多尝试的模型数（含首选模型），默认 3
+
+	BatchConcurrency int // BatchTranslate 的最大并发数，默认 5
+
+	SegmentBatching    bool // BatchTranslate 把未命中缓存的纯文本合并为一次模型调用
+	SegmentBatchSize   int  // 单次合并调用最多包含的文本数，默认 20
+	SegmentBatchTokens int  // 单次合并调用原文的最大 token 数，0 表示取模型 max_tokens 的一半
+
+	Locker   Locker        // 跨实例的翻译锁，为空时只合并本实例内的并发请求
+	LockTTL  time.Duration // 锁的过期时间，默认 60 秒
+	LockWait time.Duration // 等待其它实例翻译结果的最长时间，默认 30 秒
+
+	DetectMinConfidence float64 // 本地语言检测结果的最低置信度，低于该值视为不可靠，默认 0.6
+	LLMDetectFallback   bool    // 本地检测不可靠时是否改用模型识别语言
+
+	SkipSameLanguage           bool    // 源语言与目标语言相同时直接返回原文，不调用模型
+	SkipSameLanguageConfidence float64 // 源语言由自动检测得出时，检测置信度不低于该值才直接返回原文，默认 0.9
+
+	CacheKeyVersion    string   // 缓存键版本：v1/v2，默认 v2
+	CacheKeyDimensions []string // 参与 v2 缓存键计算的指纹维度，为 nil 时启用 CacheKeyDimensions 中的全部维度
+	CacheLegacyLookup  bool     // v2 缓存键未命中时查找 v1 缓存键，未启用 prompt 维度时命中后写入新键
+
+	Glossary            *glossary.Store // 术语表，为空时不注入术语
+	GlossaryEnforcement string          // 译文术语校验方式：off/flag/repair，默认 flag
+
+	Memory                   *tm.Store // 翻译记忆，为空时不查找
+	MemoryReuseThreshold     float64   // 相似度不低于该值时直接采用记忆中的译文，默认 1（仅规范化后完全相同的原文），大于 1 表示从不直接采用
+	MemoryReferenceThreshold float64   // 相似度不低于该值的条目作为参考译文附加到提示词，默认 0.7
+	MemoryMaxReferences      int       // 单次翻译最多附带的参考译文数，默认 3
+	MemoryLearn              bool      // 模型给出的纯文本译文写入翻译记忆
+
+	ChunkingEnabled    bool // 超出模型输出上限的长文本按段落、句子拆分后分块翻译
+	ChunkMaxTokens     int  // 每块原文的最大 token 数，0 表示取模型 max_tokens 的一半
+	ChunkContextTokens int  // 携带上一块原文与译文作为上下文的 token 数，默认 200，负数表示不携带
+}
+
+// ReasonSameLanguage 源语言与目标语言相同、原文直接返回时记录的原因
+const ReasonSameLanguage = "same_language"
+
 // TranslateRequest 翻译请求参数
 type TranslateRequest struct {
 	Text       string
@@ -27,81 +77,293 @@ type TranslateRequest struct {
 	TargetLang string
 	Provider   string // 可选，指定服务提供商
 	Model      string // 可选，指定模型
+
+	Formality   string // 可选，语气：more/prefer_more 正式，less/prefer_less 随意
+	TagHandling string // 可选，文本中包含的标记类型：html、xml、markdown（Markdown 行内文本）、subtitle（字幕文本）或 l10n/l10n-xml（本地化文件中的界面文案）
+
+	passage  *passageContext // 分块翻译中的一块或字幕中的一条，携带前后文且不再继续拆分
+	detected *DetectResult   // 源语言由自动检测得出时的检测结果（文档在整篇正文上检测一次，各片段沿用），为空表示由调用方声明
+}
+
+// instructions 根据请求选项生成附加在提示词之前的指令
+func (req TranslateRequest) instructions() []string {
+	var lines []string
+	switch req.Formality {
+	case "more", "prefer_more":
+		lines = append(lines, "Use a formal, polite register in the translation.")
+	case "less", "prefer_less":
+		lines = append(lines, "Use an informal, casual register in the translation.")
+	}
+	switch req.TagHandling {
+	case "html", "xml":
+		lines = append(lines, fmt.Sprintf("The text is %s content in which tags have been replaced by placeholders such as <x1>...</x1> and <x2/>. "+
+			"Keep every placeholder exactly as written, placed around the corresponding translated words, keep character entities such as &amp; unchanged, and do not add any other tags.",
+			strings.ToUpper(req.TagHandling)))
+	case "markdown":
+		lines = append(lines, "The text is a Markdown fragment in which links, inline code and URLs have been replaced by placeholders such as <x1>...</x1> and <x2/>. "+
+			"Keep every placeholder exactly as written, placed around the corresponding translated words, keep Markdown emphasis markers such as ** and _ around the corresponding words, and do not add any other markup.")
+	case "subtitle":
+		lines = append(lines, "The text is a single subtitle cue in which styling tags have been replaced by placeholders such as <x1>...</x1> and <x2/>. "+
+			"Keep every placeholder exactly as written, placed around the corresponding translated words, keep the translation about as short as the original so it fits on screen, and do not add any other markup.")
+	case "l10n", "l10n-xml":
+		lines = append(lines, "The text is a user interface message from a localization file in which variables, message format syntax and tags have been replaced by placeholders such as <x1>...</x1> and <x2/>. "+
+			"Keep every placeholder exactly as written, placed around the corresponding translated words, translate the text inside paired placeholders, keep the wording concise as fits a user interface, and do not add any other markup or placeholders.")
+	}
+	if line := req.passage.instructions(); line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// applyInstructions 将请求选项对应的指令放在提示词模板之前
+func (req TranslateRequest) applyInstructions(prompt utils.PromptTemplate) utils.PromptTemplate {
+	lines := req.instructions()
+	if len(lines) == 0 {
+		return prompt
+	}
+	prompt.Template = strings.Join(lines, "\n") + "\n" + prompt.Template
+	return prompt
+}
+
+// cacheOptions 返回影响译文、需要参与 v1 缓存键计算的选项
+// 命中语言对规则时模板指纹也参与计算；默认模板不加指纹，与旧版缓存键保持一致。
+// 术语表只计入文本中出现的术语，相关术语增删改后旧译文即失效，不相关的修改不影响缓存
+func (req TranslateRequest) cacheOptions(prompt utils.PromptTemplate, terms []glossary.Term) []string {
+	var opts []string
+	if prompt.Name != "" {
+		opts = append(opts, "prompt="+prompt.Fingerprint())
+	}
+	if len(terms) > 0 {
+		opts = append(opts, "glossary="+glossary.Fingerprint(terms))
+	}
+	if req.Formality != "" && req.Formality != "default" {
+		opts = append(opts, "formality="+req.Formality)
+	}
+	if req.TagHandling != "" {
+		opts = append(opts, "tags="+req.TagHandling)
+	}
+	return opts
+}
+
+// TranslateResult 翻译结果，包含最终给出译文的模型信息
+type TranslateResult struct {
+	Text     string
+	Provider string
+	Model    string
+	APIURL   string
+	CacheHit bool
+	Attempts int // 实际尝试的模型数，缓存命中时为 0
+
+	SourceLang       string  // 实际使用的源语言；请求未指定时为检测结果，检测失败时为空
+	Detected         bool    // 源语言是否由自动检测得出
+	DetectConfidence float64 // 自动检测的置信度（0~1）
+
+	Reason string // 未调用模型直接返回的原因，例如 ReasonSameLanguage
+
+	GlossaryViolations []string // 译文中未按术语表翻译的术语，例如 "Workspace => Workspace"
+	TagFallback        bool     // 标签还原失败，改为逐段翻译文本节点
+	Chunks             int      // 长文本拆分的块数，未拆分时为 0
+	MemoryScore        float64  // 直接采用或作为参考的翻译记忆条目中最高的相似度，未使用翻译记忆时为 0
+
+	cacheKeys []string // 译文写入或命中的缓存键，标签还原失败时据此删除
+}
+
+// ModelName 返回 provider/model 形式的模型名称，未经模型翻译时返回空字符串
+func (r *TranslateResult) ModelName() string {
+	if r.Provider == "" && r.Model == "" {
+		return ""
+	}
+	return translator.ModelIdentifier{Provider: r.Provider, Model: r.Model}.String()
 }
 
 // NewTranslationService 创建翻译服务实例
-func NewTranslationService(modelManager *translator.ModelManager, cache cache.Cache, translLogger *logger.TranslationLogger) *TranslationService {
+func NewTranslationService(modelManager *translator.ModelManager, cache cache.Cache, translLogger *logger.TranslationLogger, opts TranslationServiceOptions) *TranslationService {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.BatchConcurrency <= 0 {
+		opts.BatchConcurrency = 5
+	}
+	if opts.SegmentBatchSize <= 0 {
+		opts.SegmentBatchSize = 20
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = 60 * time.Second
+	}
+	if opts.LockWait <= 0 {
+		opts.LockWait = 30 * time.Second
+	}
+	if opts.CacheKeyVersion == "" {
+		opts.CacheKeyVersion = CacheKeyV2
+	}
+	opts.CacheKeyDimensions = canonicalDimensions(opts.CacheKeyDimensions)
+	if opts.DetectMinConfidence <= 0 {
+		opts.DetectMinConfidence = 0.6
+	}
+	if opts.SkipSameLanguageConfidence <= 0 {
+		opts.SkipSameLanguageConfidence = 0.9
+	}
+	if opts.GlossaryEnforcement == "" {
+		opts.GlossaryEnforcement = glossary.EnforcementFlag
+	}
+	if opts.MemoryReuseThreshold <= 0 {
+		opts.MemoryReuseThreshold = 1
+	}
+	if opts.MemoryReferenceThreshold <= 0 {
+		opts.MemoryReferenceThreshold = 0.7
+	}
+	if opts.MemoryMaxReferences <= 0 {
+		opts.MemoryMaxReferences = 3
+	}
+	if opts.ChunkContextTokens == 0 {
+		opts.ChunkContextTokens = 200
+	}
+
 	return &TranslationService{
 		modelManager: modelManager,
 		cache:        cache,
 		logger:       translLogger,
+		detector:     detector.NewLocalDetector(),
+		opts:         opts,
 	}
 }
 
 // Translate 处理翻译请求，自动处理缓存逻辑
-func (s *TranslationService) Translate(ctx context.Context, provider, model, promptTemplate, text, sourceLang, targetLang string) (string, error) {
-	if text == "" {
-		return "", fmt.Errorf("text is required")
+func (s *TranslationService) Translate(ctx context.Context, provider, model string, prompts *utils.PromptSet, text, sourceLang, targetLang string) (string, error) {
+	result, err := s.TranslateWithResult(ctx, prompts, TranslateRequest{
+		Text:       text,
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		Provider:   provider,
+		Model:      model,
+	})
+	if err != nil {
+		return "", err
 	}
-	if targetLang == "" {
-		return "", fmt.Errorf("target language is required")
+	return result.Text, nil
+}
+
+// TranslateWithResult 处理翻译请求并返回实际应答的模型信息
+// 提示词模板按（检测后的）语言对从 prompts 中选取，首选模型失败时按 FailoverCandidates 的顺序切换模型重试；
+// TagHandling 为 html/xml/markdown/subtitle 时保护标签后再翻译，见 translateMarkup
+func (s *TranslationService) TranslateWithResult(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (*TranslateResult, error) {
+	if req.Text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if req.TargetLang == "" {
+		return nil, fmt.Errorf("target language is required")
+	}
+
+	if hasMarkup(req.TagHandling) {
+		return s.translateMarkup(ctx, prompts, req)
 	}
+	return s.translateText(ctx, prompts, req)
+}
 
-	var cacheKey string
+// translateText 翻译纯文本（或已替换为占位符的标记文本），自动处理语言检测、缓存与故障转移
+func (s *TranslationService) translateText(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (*TranslateResult, error) {
+	p, result, err := s.prepareText(ctx, prompts, req)
+	if err != nil || result != nil {
+		return result, err
+	}
 
+	// 执行翻译（必要时故障转移），同一文本的并发请求只调用一次模型
+	return s.translatePending(ctx, p)
+}
+
+// pendingText 已完成语言检测与缓存查找、等待模型翻译的文本
+type pendingText struct {
+	req        TranslateRequest
+	selected   utils.PromptTemplate // 按语言对选中的模板
+	terms      []glossary.Term      // 文本中出现的术语
+	prompt     utils.PromptTemplate // 附加指令、术语与参考译文后的提示词
+	references []tm.Match           // 作为参考译文的翻译记忆条目
+	cacheKey   string
+	legacyKeys []string // 缓存键未命中时依次查找的旧键
+	rawSource  string   // 请求中原样的源语言（如 "auto"、"EN"），旧版缓存键按此计算
+	rawTarget  string   // 请求中原样的目标语言
+	detected   *DetectResult
+	start      time.Time
+}
+
+// prepareText 检测源语言并查找缓存与翻译记忆；缓存命中、翻译记忆中有足够相似的原文、源语言与目标语言相同或分块翻译完成时直接返回结果，
+// 否则返回需要调用模型的 pendingText
+func (s *TranslationService) prepareText(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest) (*pendingText, *TranslateResult, error) {
 	startTime := time.Now()
+	rawSource, rawTarget := req.SourceLang, req.TargetLang
 
-	// 2. 尝试从缓存获取（并兼容旧前缀 transbrige:）
-	if s.cache != nil {
-		cacheKey = utils.GenerateCacheKey(text, sourceLang, targetLang)
-		if cachedData, err := s.cache.Get(ctx, cacheKey); err == nil && cachedData != "" {
-			var entry cache.CacheEntry
-			if err := json.Unmarshal([]byte(cachedData), &entry); err == nil {
-				log.Printf("Cache hit for: %s, originally translated by %s/%s",
-					cacheKey, entry.APIURL, entry.Model)
-				s.logTranslation(text, entry.Translation, sourceLang, targetLang, entry.APIURL, entry.Provider, entry.Model, cacheKey, true, time.Since(startTime).Milliseconds())
-				return entry.Translation, nil
-			}
-		} else {
-			// 向后兼容旧键前缀
-			fallbackKey := strings.Replace(cacheKey, "transbridge:", "transbrige:", 1)
-			if fallbackKey != cacheKey {
-				if cachedData2, err2 := s.cache.Get(ctx, fallbackKey); err2 == nil && cachedData2 != "" {
-					var entry2 cache.CacheEntry
-					if err := json.Unmarshal([]byte(cachedData2), &entry2); err == nil {
-						log.Printf("Cache hit (legacy key) for: %s, originally translated by %s/%s",
-							fallbackKey, entry2.APIURL, entry2.Model)
-						s.logTranslation(text, entry2.Translation, sourceLang, targetLang, entry2.APIURL, entry2.Provider, entry2.Model, fallbackKey, true, time.Since(startTime).Milliseconds())
-						return entry2.Translation, nil
-					}
-				}
-			}
-		}
+	// 0. 未指定源语言时自动检测，检测结果用于提示词和缓存键
+	detected, err := s.resolveSourceLang(ctx, &req, req.Text)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var usedTranslator translator.Translator
-	var err error
-	// 1. 首先尝试获取指定的翻译器
-	if provider != "" && model != "" {
-		usedTranslator, err = s.modelManager.GetModel(provider, model)
-		if err != nil {
-			log.Printf("Specified model %s/%s not found: %v, falling back to default", provider, model, err)
-			usedTranslator = s.modelManager.GetDefaultModel()
+	// 源语言与目标语言相同时原样返回
+	if s.skipSameLanguage(req) {
+		s.logTranslation(logger.TranslationRecord{
+			SourceText:  req.Text,
+			TargetText:  req.Text,
+			SourceLang:  req.SourceLang,
+			TargetLang:  req.TargetLang,
+			ProcessTime: float64(time.Since(startTime).Milliseconds()),
+			Reason:      ReasonSameLanguage,
+		})
+		result := &TranslateResult{Text: req.Text, Reason: ReasonSameLanguage}
+		result.setSource(req.SourceLang, detected)
+		return nil, result, nil
+	}
+
+	// 超出模型输出上限的长文本分块翻译，避免译文被截断
+	if req.passage == nil {
+		if maxTokens, estimate := s.chunkBudget(req); maxTokens > 0 && estimate(req.Text) > maxTokens {
+			result, err := s.translateChunks(ctx, prompts, req, splitChunks(req.Text, maxTokens, estimate), estimate, detected)
+			return nil, result, err
 		}
-	} else {
-		usedTranslator = s.modelManager.GetRandomModel()
 	}
 
-	// 3. 执行翻译
-	translation, err := usedTranslator.Translate(promptTemplate, text, sourceLang, targetLang)
-	if err != nil {
-		// 记录失败的翻译
-		return "", fmt.Errorf("translation failed with %s/%s: %w",
-			usedTranslator.GetAPIURL(), usedTranslator.GetModel(), err)
+	selected := prompts.Select(req.SourceLang, req.TargetLang)
+	terms := s.opts.Glossary.Match(req.SourceLang, req.TargetLang, req.Text)
+	p := &pendingText{
+		req:       req,
+		selected:  selected,
+		terms:     terms,
+		prompt:    applyGlossary(req.applyInstructions(selected), terms),
+		detected:  detected,
+		start:     startTime,
+		rawSource: rawSource,
+		rawTarget: rawTarget,
 	}
 
-	// 4. 缓存成功的翻译结果（包含模型信息）
+	// 1. 尝试从缓存获取（并兼容旧版缓存键）；未启用缓存时缓存键仍用于合并并发请求
+	p.cacheKey = s.cacheKey(req, selected, terms)
 	if s.cache != nil {
+		p.legacyKeys = s.legacyCacheKeys(p)
+		if result := s.cachedResult(ctx, p); result != nil {
+			return nil, result, nil
+		}
+	}
+
+	// 2. 查找翻译记忆：足够相似时直接采用，否则附带参考译文；参考译文不参与缓存键计算
+	if result := s.searchMemory(p); result != nil {
+		return nil, result, nil
+	}
+
+	return p, nil, nil
+}
+
+// completeText 校验模型给出的译文，写入缓存并记录日志
+func (s *TranslationService) completeText(ctx context.Context, p *pendingText, translation string, usedTranslator translator.Translator, failedModels []string) *TranslateResult {
+	req := p.req
+	attempts := len(failedModels) + 1
+
+	// 3. 校验术语，repair 模式下违规时重新翻译一次
+	translation, usedTranslator, repairAttempts, violations := s.enforceGlossary(ctx, p.prompt, req, p.terms, translation, usedTranslator)
+	attempts += repairAttempts
+
+	// 4. 缓存成功的翻译结果（包含模型信息）；flag 模式下违反术语表的译文同样缓存，命中时重新标记违规术语；
+	// repair 模式下修复后仍违反术语表的译文不缓存，下次请求重新翻译并修复
+	cached := false
+	if s.cache != nil && (len(violations) == 0 || s.opts.GlossaryEnforcement != glossary.EnforcementRepair) {
 		cacheEntry := cache.CacheEntry{
 			Translation: translation,
 			Provider:    usedTranslator.GetProvider(),
@@ -112,18 +374,259 @@ func (s *TranslationService) Translate(ctx context.Context, provider, model, pro
 		// 序列化缓存条目
 		cacheData, err := json.Marshal(cacheEntry)
 		if err == nil {
-			cacheKey = utils.GenerateCacheKey(text, sourceLang, targetLang)
 			// 让底层缓存实现使用其默认 TTL（传 0）或永久（由实现决定）
-			if err := s.cache.Set(ctx, cacheKey, string(cacheData), 0); err != nil {
+			if err := s.cache.Set(ctx, p.cacheKey, string(cacheData), 0); err != nil {
 				log.Printf("Failed to cache translation: %v", err)
+			} else {
+				cached = true
 			}
 		}
 	}
 
+	// 5. 未违反术语表的译文写入翻译记忆
+	if len(violations) == 0 {
+		s.learnMemory(p, translation)
+	}
+	var memoryScore float64
+	if len(p.references) > 0 {
+		memoryScore = p.references[0].Score
+	}
+
 	// 记录翻译
-	s.logTranslation(text, translation, sourceLang, targetLang, usedTranslator.GetAPIURL(), usedTranslator.GetProvider(), usedTranslator.GetModel(), cacheKey, false, time.Since(startTime).Milliseconds())
+	s.logTranslation(logger.TranslationRecord{
+		SourceText:   req.Text,
+		TargetText:   translation,
+		SourceLang:   req.SourceLang,
+		TargetLang:   req.TargetLang,
+		APIURL:       usedTranslator.GetAPIURL(),
+		Provider:     usedTranslator.GetProvider(),
+		Model:        usedTranslator.GetModel(),
+		CacheKey:     p.cacheKey,
+		CacheHit:     false,
+		ProcessTime:  float64(time.Since(p.start).Milliseconds()),
+		Attempts:     attempts,
+		FailedModels: failedModels,
+		Prompt:       p.selected.Name,
+		MemoryScore:  memoryScore,
+
+		GlossaryViolations: violations,
+	})
+
+	result := &TranslateResult{
+		Text:        translation,
+		Provider:    usedTranslator.GetProvider(),
+		Model:       usedTranslator.GetModel(),
+		APIURL:      usedTranslator.GetAPIURL(),
+		Attempts:    attempts,
+		MemoryScore: memoryScore,
+
+		GlossaryViolations: violations,
+	}
+	if cached {
+		result.cacheKeys = []string{p.cacheKey}
+	}
+	result.setSource(req.SourceLang, p.detected)
+	return result
+}
+
+// resolveSourceLang 在请求未指定源语言时检测 text 的语言并写回 req.SourceLang
+// 返回检测结果；检测失败时保留空源语言继续翻译，仅在调用方取消时返回错误
+func (s *TranslationService) resolveSourceLang(ctx context.Context, req *TranslateRequest, text string) (*DetectResult, error) {
+	if !isAutoLanguage(req.SourceLang) {
+		return nil, nil
+	}
+	req.SourceLang = ""
+
+	detected, err := s.DetectLanguage(ctx, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Source language detection failed: %v", err)
+		return nil, nil
+	}
+	req.SourceLang = detected.Language
+	req.detected = detected
+	return detected, nil
+}
+
+// skipSameLanguage 判断源语言与目标语言是否相同、可以直接返回原文
+// 自动检测可能出错，检测得出的源语言只有置信度不低于 SkipSameLanguageConfidence 时才跳过翻译，否则仍调用模型
+func (s *TranslationService) skipSameLanguage(req TranslateRequest) bool {
+	if !s.opts.SkipSameLanguage || !utils.SameLanguage(req.SourceLang, req.TargetLang) {
+		return false
+	}
+	return req.detected == nil || req.detected.Confidence >= s.opts.SkipSameLanguageConfidence
+}
+
+// setSource 记录实际使用的源语言及检测信息
+func (r *TranslateResult) setSource(sourceLang string, detected *DetectResult) {
+	r.SourceLang = sourceLang
+	if detected != nil {
+		r.Detected = true
+		r.DetectConfidence = detected.Confidence
+	}
+}
 
-	return translation, nil
+// translateWithFailover 依次尝试候选模型，直到成功、遇到不可重试的错误或达到尝试上限
+// 返回译文、最终应答的翻译器以及之前失败的模型列表
+func (s *TranslationService) translateWithFailover(ctx context.Context, prompt utils.PromptTemplate, req TranslateRequest) (string, translator.Translator, []string, error) {
+	candidates := s.modelManager.FailoverCandidates(req.Provider, req.Model)
+
+	maxAttempts := 1
+	if s.opts.FailoverEnabled {
+		maxAttempts = s.opts.MaxAttempts
+	}
+
+	var failedModels []string
+	var lastErr error
+	attempts := 0
+	for _, candidate := range candidates {
+		if attempts >= maxAttempts {
+			break
+		}
+
+		// 跳过熔断中的模型（半开状态下的探测名额已被占满时同样跳过）
+		id := translator.IdentifierOf(candidate)
+		if !s.modelManager.AllowRequest(id) {
+			log.Printf("Model %s skipped: circuit breaker open", id)
+			continue
+		}
+		attempts++
+
+		attemptStart := time.Now()
+		translation, err := candidate.Translate(ctx, prompt, req.Text, req.SourceLang, req.TargetLang)
+		s.reportAttempt(ctx, id, attemptStart, err)
+
+		if err == nil {
+			if len(failedModels) > 0 {
+				log.Printf("Failover succeeded with %s after %d failed attempt(s): %v",
+					id, len(failedModels), failedModels)
+			}
+			return translation, candidate, failedModels, nil
+		}
+
+		lastErr = fmt.Errorf("translation failed with %s/%s: %w",
+			candidate.GetAPIURL(), candidate.GetModel(), err)
+		failedModels = append(failedModels, id.String())
+
+		// 调用方已取消或错误不适合换模型重试时，直接返回
+		if ctx.Err() != nil || !translator.IsFailoverError(err) {
+			break
+		}
+		log.Printf("Model %s failed: %v", id, err)
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("translation failed: no healthy model available")
+	}
+	return "", nil, failedModels, lastErr
+}
+
+// reportAttempt 上报一次模型调用的结果；调用方取消导致的失败不计入模型健康统计，半开状态的探测名额直接释放
+func (s *TranslationService) reportAttempt(ctx context.Context, id translator.ModelIdentifier, start time.Time, err error) {
+	if err != nil && ctx.Err() != nil {
+		err = context.Canceled
+	}
+	s.modelManager.ReportResult(id, time.Since(start), err)
+}
+
+// Alternatives 使用首选结果以外的其它模型生成备选译文，最多返回 n 条
+// 备选译文不读写缓存（缓存键不区分模型），与首选译文及彼此重复的结果会被丢弃；
+// 带标签的文本同样替换为占位符后翻译，无法还原标签的结果丢弃；启用术语校验时违反术语表的结果直接丢弃，不重新翻译；
+// 需要分块翻译的长文本不生成备选译文
+func (s *TranslationService) Alternatives(ctx context.Context, prompts *utils.PromptSet, req TranslateRequest, primary *TranslateResult, n int) []string {
+	if n <= 0 || primary == nil {
+		return nil
+	}
+	var doc *markup.Document
+	text := req.Text
+	if hasMarkup(req.TagHandling) {
+		doc = parseMarkup(req.Text, req.TagHandling)
+		if len(doc.TextNodes()) == 0 {
+			return nil
+		}
+		text = doc.Protected()
+	}
+	if maxTokens, estimate := s.chunkBudget(req); maxTokens > 0 && estimate(text) > maxTokens {
+		return nil
+	}
+	terms := s.opts.Glossary.Match(req.SourceLang, req.TargetLang, text)
+	checkTerms := len(terms) > 0 && s.opts.GlossaryEnforcement != glossary.EnforcementOff
+	prompt := applyGlossary(req.applyInstructions(prompts.Select(req.SourceLang, req.TargetLang)), terms)
+
+	seen := map[string]bool{strings.TrimSpace(primary.Text): true}
+	var alternatives []string
+	for _, id := range s.modelManager.ListModels() {
+		if len(alternatives) >= n || ctx.Err() != nil {
+			break
+		}
+		if id.Provider == primary.Provider && id.Model == primary.Model && id.APIURL == primary.APIURL {
+			continue
+		}
+		candidate, ok := s.modelManager.Lookup(id)
+		if !ok || !s.modelManager.AllowRequest(id) {
+			continue
+		}
+
+		attemptStart := time.Now()
+		translation, err := candidate.Translate(ctx, prompt, text, req.SourceLang, req.TargetLang)
+		s.reportAttempt(ctx, id, attemptStart, err)
+		if err != nil {
+			log.Printf("Alternative translation with %s failed: %v", id, err)
+			continue
+		}
+		if checkTerms {
+			if violations := glossary.Check(translation, terms); len(violations) > 0 {
+				log.Printf("Alternative translation with %s dropped, glossary violations: %v", id, violations)
+				continue
+			}
+		}
+		if doc != nil {
+			restored, err := doc.Restore(translation)
+			if err != nil {
+				log.Printf("Alternative translation with %s dropped, failed to restore %s markup: %v", id, req.TagHandling, err)
+				continue
+			}
+			translation = restored
+		}
+
+		key := strings.TrimSpace(translation)
+		if !seen[key] {
+			seen[key] = true
+			alternatives = append(alternatives, translation)
+		}
+	}
+	return alternatives
+}
+
+// BatchAlternatives 为批量翻译中每条成功的结果生成最多 n 条备选译文，见 Alternatives；结果顺序与请求一致，失败的条目为 nil
+// 按 BatchConcurrency 限制同时处理的文本数，每条文本内依次调用其它模型
+func (s *TranslationService) BatchAlternatives(ctx context.Context, prompts *utils.PromptSet, requests []TranslateRequest, primaries []*TranslateResult, n int) [][]string {
+	alternatives := make([][]string, len(requests))
+	sem := make(chan struct{}, s.opts.BatchConcurrency)
+
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		if primaries[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int, req TranslateRequest) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			alternatives[idx] = s.Alternatives(ctx, prompts, req, primaries[idx], n)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return alternatives
 }
 
 // GetAvailableModels 获取所有可用的翻译模型
@@ -136,49 +639,57 @@ func (s *TranslationService) GetProviderModels(provider string) []string {
 	return s.modelManager.GetModelsByProvider(provider)
 }
 
-// BatchTranslate 批量翻译
-func (s *TranslationService) BatchTranslate(ctx context.Context, promptTemplate string, requests []TranslateRequest) []struct {
-	Text  string
-	Error error
-} {
-	results := make([]struct {
-		Text  string
-		Error error
-	}, len(requests))
+// BatchResult 批量翻译中单条文本的结果
+type BatchResult struct {
+	Text   string
+	Result *TranslateResult // 成功时包含模型、缓存命中等信息
+	Error  error
+}
+
+// BatchTranslate 批量翻译，按 BatchConcurrency 限制并发，结果顺序与请求一致
+// 启用 SegmentBatching 时未命中缓存的纯文本合并翻译，见 batchTranslateSegments
+func (s *TranslationService) BatchTranslate(ctx context.Context, prompts *utils.PromptSet, requests []TranslateRequest) []BatchResult {
+	if s.opts.SegmentBatching && len(requests) > 1 {
+		return s.batchTranslateSegments(ctx, prompts, requests)
+	}
+
+	results := make([]BatchResult, len(requests))
+	sem := make(chan struct{}, s.opts.BatchConcurrency)
 
+	var wg sync.WaitGroup
 	for i, req := range requests {
-		translation, err := s.Translate(ctx, req.Provider, req.Model, promptTemplate, req.Text, req.SourceLang, req.TargetLang)
-		results[i] = struct {
-			Text  string
-			Error error
-		}{
-			Text:  translation,
-			Error: err,
-		}
+		wg.Add(1)
+		go func(idx int, req TranslateRequest) {
+			defer wg.Done()
+
+			// 调用方已取消时，剩余条目直接返回取消错误
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[idx] = BatchResult{Error: ctx.Err()}
+				return
+			}
+
+			result, err := s.TranslateWithResult(ctx, prompts, req)
+			if err != nil {
+				results[idx] = BatchResult{Error: err}
+				return
+			}
+			results[idx] = BatchResult{Text: result.Text, Result: result}
+		}(i, req)
 	}
+	wg.Wait()
 
 	return results
 }
 
 // logTranslation 记录翻译日志
-func (s *TranslationService) logTranslation(sourceText, targetText, sourceLang, targetLang, apiURL, provider, model string, cacheKey string, cacheHit bool, processTimeMs int64) {
+func (s *TranslationService) logTranslation(record logger.TranslationRecord) {
 	if s.logger == nil {
 		return
 	}
 
-	record := logger.TranslationRecord{
-		SourceText:  sourceText,
-		TargetText:  targetText,
-		SourceLang:  sourceLang,
-		TargetLang:  targetLang,
-		APIURL:      apiURL,
-		Provider:    provider,
-		Model:       model,
-		CacheKey:    cacheKey,
-		CacheHit:    cacheHit,
-		ProcessTime: float64(processTimeMs),
-	}
-
 	if err := s.logger.LogTranslation(record); err != nil {
 		log.Printf("Failed to log translation: %v", err)
 	}
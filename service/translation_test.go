@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"transbridge/cache"
+	"transbridge/config"
+	"transbridge/glossary"
+	"transbridge/internal/utils"
+	"transbridge/translator"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// modelCalls 记录各模型收到的请求
+type modelCalls struct {
+	mu     sync.Mutex
+	inputs map[string]string // 各模型最近一次收到的用户消息
+	counts map[string]int
+}
+
+func (c *modelCalls) input(model string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inputs[model]
+}
+
+func (c *modelCalls) count(model string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[model]
+}
+
+// fakeModels 启动一个 OpenAI 兼容服务，按模型名返回固定译文，并记录各模型收到的请求
+func fakeModels(t *testing.T, replies map[string]string) (*translator.ModelManager, *modelCalls) {
+	t.Helper()
+	calls := &modelCalls{inputs: make(map[string]string), counts: make(map[string]int)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		calls.mu.Lock()
+		calls.inputs[req.Model] = req.Messages[len(req.Messages)-1].Content
+		calls.counts[req.Model]++
+		calls.mu.Unlock()
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: replies[req.Model]}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	provider := config.ProviderConfig{Provider: "openai", APIURL: server.URL, Timeout: 5}
+	for model := range replies {
+		provider.Models = append(provider.Models, config.ModelConfig{Name: model})
+	}
+	manager, err := translator.NewModelManager([]config.ProviderConfig{provider}, translator.HealthOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager, calls
+}
+
+// testGlossary 返回只包含 Dashboard => 仪表盘 的英译中术语表
+func testGlossary(t *testing.T) *glossary.Store {
+	t.Helper()
+	terms := glossary.NewStore()
+	if _, err := terms.Upsert("en", "zh", []glossary.Term{{Source: "Dashboard", Target: "仪表盘"}}); err != nil {
+		t.Fatal(err)
+	}
+	return terms
+}
+
+func TestAlternatives(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		tagHandling string
+		enforcement string
+		replies     map[string]string
+		wantInput   string // 备选模型收到的原文，位于附加指令之后
+		want        []string
+	}{
+		{
+			name:      "plain text",
+			text:      "Hello world",
+			replies:   map[string]string{"primary": "你好世界", "alt": "世界你好"},
+			wantInput: "Hello world",
+			want:      []string{"世界你好"},
+		},
+		{
+			name:      "duplicate of primary dropped",
+			text:      "Hello world",
+			replies:   map[string]string{"primary": "你好世界", "alt": " 你好世界 "},
+			wantInput: "Hello world",
+		},
+		{
+			name:        "html protected and restored",
+			text:        "Click <b>here</b>",
+			tagHandling: "html",
+			replies:     map[string]string{"primary": "点击<b>这里</b>", "alt": "<x1>这里</x1>点击"},
+			wantInput:   "Click <x1>here</x1>",
+			want:        []string{"<b>这里</b>点击"},
+		},
+		{
+			name:        "html alternative that fails to restore dropped",
+			text:        "Click <b>here</b>",
+			tagHandling: "html",
+			replies:     map[string]string{"primary": "点击<b>这里</b>", "alt": "点击这里"},
+			wantInput:   "Click <x1>here</x1>",
+		},
+		{
+			name:        "glossary violation dropped",
+			text:        "Open the Dashboard",
+			enforcement: glossary.EnforcementFlag,
+			replies:     map[string]string{"primary": "打开仪表盘", "alt": "打开控制台"},
+		},
+		{
+			name:        "glossary ignored when enforcement is off",
+			text:        "Open the Dashboard",
+			enforcement: glossary.EnforcementOff,
+			replies:     map[string]string{"primary": "打开仪表盘", "alt": "打开控制台"},
+			want:        []string{"打开控制台"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, calls := fakeModels(t, tt.replies)
+			enforcement := tt.enforcement
+			if enforcement == "" {
+				enforcement = glossary.EnforcementOff
+			}
+			s := &TranslationService{modelManager: manager, opts: TranslationServiceOptions{
+				Glossary:            testGlossary(t),
+				GlossaryEnforcement: enforcement,
+			}}
+
+			req := TranslateRequest{Text: tt.text, SourceLang: "en", TargetLang: "zh", TagHandling: tt.tagHandling}
+			primary := &TranslateResult{Text: tt.replies["primary"], Provider: "openai", Model: "primary", APIURL: manager.ListModels()[0].APIURL}
+			prompts := utils.NewPromptSet(utils.PromptTemplate{Template: "{{input}}"})
+
+			got := s.Alternatives(context.Background(), prompts, req, primary, 3)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Alternatives() = %q, want %q", got, tt.want)
+			}
+			if calls.count("primary") > 0 {
+				t.Error("the primary model was asked for an alternative")
+			}
+			if !strings.HasSuffix(calls.input("alt"), tt.wantInput) {
+				t.Errorf("alternative model got %q, want it to end with %q", calls.input("alt"), tt.wantInput)
+			}
+			if strings.Contains(calls.input("alt"), "<b>") {
+				t.Errorf("raw markup sent to the alternative model: %q", calls.input("alt"))
+			}
+		})
+	}
+}
+
+func TestGlossaryViolationCaching(t *testing.T) {
+	tests := []struct {
+		name           string
+		enforcement    string
+		wantCalls      int // 两次相同请求共调用模型的次数
+		wantViolations bool
+	}{
+		{name: "flag mode caches flagged translation", enforcement: glossary.EnforcementFlag, wantCalls: 1, wantViolations: true},
+		{name: "failed repair is not cached", enforcement: glossary.EnforcementRepair, wantCalls: 4, wantViolations: true},
+		{name: "enforcement off caches translation", enforcement: glossary.EnforcementOff, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			manager, calls := fakeModels(t, map[string]string{"model": "打开控制台"})
+			memory := cache.NewMemoryCache(cache.MemoryCacheOptions{Permanent: true})
+			defer memory.Close(ctx)
+			s := NewTranslationService(manager, memory, nil, TranslationServiceOptions{
+				Glossary:            testGlossary(t),
+				GlossaryEnforcement: tt.enforcement,
+			})
+			prompts := utils.NewPromptSet(utils.PromptTemplate{Template: "{{input}}"})
+			req := TranslateRequest{Text: "Open the Dashboard", SourceLang: "en", TargetLang: "zh"}
+
+			for i := 0; i < 2; i++ {
+				result, err := s.TranslateWithResult(ctx, prompts, req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := len(result.GlossaryViolations) > 0; got != tt.wantViolations {
+					t.Errorf("request %d: GlossaryViolations = %v, want violations %v", i+1, result.GlossaryViolations, tt.wantViolations)
+				}
+			}
+			if got := calls.count("model"); got != tt.wantCalls {
+				t.Errorf("model called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
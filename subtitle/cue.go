@@ -0,0 +1,70 @@
+// subtitle/cue.go
+package subtitle
+
+import (
+	"regexp"
+	"strings"
+
+	"transbridge/markup"
+)
+
+// cueMarkupPattern 标签之外需要保留的标记：ASS 样式覆盖（如 {\an8}）、WebVTT 卡拉 OK 时间戳（如 <00:00:01.500>）
+// 与裸链接（见 trimURLPunctuation）
+var cueMarkupPattern = regexp.MustCompile(`\{\\[^{}]*\}|<(?:\d+:)?\d{2}:\d{2}\.\d{3}>|(?:https?|ftp)://[^\s<>{}]+`)
+
+// ParseCueText 解析字幕文本中的样式标签，返回可保护标签的文档
+// <i>、<b>、<font>、<c.class>、<v 说话人> 等标签以及 ASS 样式覆盖、时间戳与链接都替换为占位符
+func ParseCueText(text string) *markup.Document {
+	var tokens []markup.Token
+	for _, tok := range markup.Tokenize(text, "xml") {
+		switch tok.Type {
+		case markup.TextToken:
+			tokens = append(tokens, splitCueMarkup(tok.Raw)...)
+		case markup.StartTagToken:
+			// <c.yellow> 与 </c> 配对
+			if dot := strings.IndexByte(tok.Name, '.'); dot > 0 {
+				tok.Name = tok.Name[:dot]
+			}
+			tokens = append(tokens, tok)
+		default:
+			tokens = append(tokens, tok)
+		}
+	}
+	return markup.NewDocument(tokens, false)
+}
+
+func splitCueMarkup(text string) []markup.Token {
+	var tokens []markup.Token
+	last := 0
+	for _, loc := range cueMarkupPattern.FindAllStringIndex(text, -1) {
+		if loc[0] > last {
+			tokens = append(tokens, markup.Token{Type: markup.TextToken, Raw: text[last:loc[0]]})
+		}
+		raw := text[loc[0]:loc[1]]
+		if !strings.HasPrefix(raw, "{") && !strings.HasPrefix(raw, "<") {
+			raw = trimURLPunctuation(raw)
+		}
+		tokens = append(tokens, markup.Token{Type: markup.OpaqueToken, Raw: raw})
+		last = loc[0] + len(raw)
+	}
+	if last < len(text) {
+		tokens = append(tokens, markup.Token{Type: markup.TextToken, Raw: text[last:]})
+	}
+	return tokens
+}
+
+// trimURLPunctuation 去掉裸链接末尾的标点，例如句末的 "." 与不成对的 ")"
+func trimURLPunctuation(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?'\"", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
@@ -0,0 +1,158 @@
+// subtitle/subtitle.go
+package subtitle
+
+import (
+	"strings"
+)
+
+// 字幕格式
+const (
+	FormatSRT = "srt"
+	FormatVTT = "vtt"
+)
+
+// Cue 一条字幕
+type Cue struct {
+	ID     string // 序号（SRT）或标识（WebVTT），可能为空
+	Timing string // 时间轴行，例如 "00:00:01,000 --> 00:00:02,500"，包含 WebVTT 的位置设置
+	Text   string // 字幕文本，多行时以 "\n" 连接
+}
+
+// part 文件按顺序拆分成的片段：原样输出的文本或字幕文本
+type part struct {
+	literal string
+	cue     int // 字幕下标，-1 表示原样输出 literal
+}
+
+// File 解析后的字幕文件
+// 序号、时间轴、WebVTT 文件头与 NOTE/STYLE/REGION 块原样保留，只有字幕文本需要翻译
+type File struct {
+	Format string
+	parts  []part
+	cues   []Cue
+	crlf   bool
+}
+
+// Parse 解析 SRT 或 WebVTT 字幕，以 "WEBVTT" 开头的按 WebVTT 处理，其余按 SRT 处理
+func Parse(text string) *File {
+	f := &File{Format: FormatSRT, crlf: strings.Contains(text, "\r\n")}
+	if f.crlf {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+
+	body := strings.TrimPrefix(text, "\ufeff")
+	f.addLiteral(text[:len(text)-len(body)])
+	if strings.HasPrefix(body, "WEBVTT") {
+		f.Format = FormatVTT
+	}
+
+	lines := strings.Split(body, "\n")
+	for i := 0; i < len(lines); {
+		if i > 0 {
+			f.addLiteral("\n")
+		}
+		if strings.TrimSpace(lines[i]) == "" {
+			f.addLiteral(lines[i])
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+			end++
+		}
+		f.block(lines[i:end], i == 0)
+		i = end
+	}
+	return f
+}
+
+// block 处理以空行分隔的一个块
+func (f *File) block(lines []string, first bool) {
+	timing := -1
+	for j, line := range lines {
+		if strings.Contains(line, "-->") {
+			timing = j
+			break
+		}
+	}
+
+	// WebVTT 文件头、NOTE/STYLE/REGION 块、没有时间轴或文本的块原样保留
+	if timing < 0 || timing == len(lines)-1 || (f.Format == FormatVTT && first) || isVTTMetadata(lines[0]) {
+		f.addLiteral(strings.Join(lines, "\n"))
+		return
+	}
+
+	cue := Cue{
+		ID:     strings.Join(lines[:timing], "\n"),
+		Timing: lines[timing],
+		Text:   strings.Join(lines[timing+1:], "\n"),
+	}
+	f.addLiteral(strings.Join(lines[:timing+1], "\n") + "\n")
+	f.parts = append(f.parts, part{cue: len(f.cues)})
+	f.cues = append(f.cues, cue)
+}
+
+func isVTTMetadata(line string) bool {
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if line == keyword || strings.HasPrefix(line, keyword+" ") || strings.HasPrefix(line, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// Cues 返回全部字幕
+func (f *File) Cues() []Cue {
+	return f.cues
+}
+
+// Render 用译文替换字幕文本并按原格式输出，translations 与 Cues 一一对应，缺少的字幕保留原文
+// bilingual 为 true 时输出双语字幕：原文在上、译文在下
+func (f *File) Render(translations []string, bilingual bool) string {
+	var b strings.Builder
+	for _, p := range f.parts {
+		if p.cue < 0 {
+			b.WriteString(p.literal)
+			continue
+		}
+
+		original := f.cues[p.cue].Text
+		text := original
+		if p.cue < len(translations) {
+			// 空行会结束字幕块，译文中的空行需要去掉
+			text = removeBlankLines(translations[p.cue])
+			if bilingual {
+				text = original + "\n" + text
+			}
+		}
+		b.WriteString(text)
+	}
+
+	if f.crlf {
+		return strings.ReplaceAll(b.String(), "\n", "\r\n")
+	}
+	return b.String()
+}
+
+func (f *File) addLiteral(s string) {
+	if s == "" {
+		return
+	}
+	if n := len(f.parts); n > 0 && f.parts[n-1].cue < 0 {
+		f.parts[n-1].literal += s
+		return
+	}
+	f.parts = append(f.parts, part{literal: s, cue: -1})
+}
+
+func removeBlankLines(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return strings.Join(kept, "\n")
+}
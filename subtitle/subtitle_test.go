@@ -0,0 +1,115 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+// testFiles 覆盖 SRT 与 WebVTT 常见写法的字幕文件
+var testFiles = []struct {
+	name       string
+	text       string
+	wantFormat string
+	wantCues   int
+}{
+	{
+		name:       "srt",
+		text:       "1\n00:00:01,000 --> 00:00:02,500\nHello there.\n\n2\n00:00:03,000 --> 00:00:05,000\n<i>Two lines,</i>\nsecond line.\n",
+		wantFormat: FormatSRT,
+		wantCues:   2,
+	},
+	{
+		name:       "srt with bom and crlf",
+		text:       "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\n{\\an8}Top of the screen\r\n\r\n2\r\n00:00:02,500 --> 00:00:04,000\r\nVisit https://example.com/help.\r\n",
+		wantFormat: FormatSRT,
+		wantCues:   2,
+	},
+	{
+		name: "webvtt",
+		text: "WEBVTT - Demo\nKind: captions\n\nNOTE written by hand\nover two lines\n\nSTYLE\n::cue { color: yellow }\n\nintro\n00:00.000 --> 00:02.000 align:start position:10%\n<v Roger>Welcome <c.yellow>back</c>!\n\n" +
+			"00:02.500 --> 00:04.000\n<00:02.500>Sing <00:03.000>along\n\n00:04.500 --> 00:06.000\nDocs at ftp://files.example.com/a_(b), thanks\n",
+		wantFormat: FormatVTT,
+		wantCues:   3,
+	},
+	{
+		name:       "blank lines around blocks",
+		text:       "\n\n1\n00:00:01,000 --> 00:00:02,000\nText\n\n\n\n2\n00:00:03,000 --> 00:00:04,000\nMore text\n\n",
+		wantFormat: FormatSRT,
+		wantCues:   2,
+	},
+}
+
+func TestRenderIdentity(t *testing.T) {
+	for _, tt := range testFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Parse(tt.text)
+			if f.Format != tt.wantFormat {
+				t.Errorf("Format = %q, want %q", f.Format, tt.wantFormat)
+			}
+			cues := f.Cues()
+			if len(cues) != tt.wantCues {
+				t.Fatalf("got %d cues, want %d", len(cues), tt.wantCues)
+			}
+
+			identity := make([]string, len(cues))
+			for i, cue := range cues {
+				// 字幕文本中的标记替换为占位符再还原，模拟原样返回的译文
+				doc := ParseCueText(cue.Text)
+				restored, err := doc.Restore(doc.Protected())
+				if err != nil {
+					t.Fatalf("cue %d: Restore(Protected()) error = %v", i, err)
+				}
+				identity[i] = restored
+			}
+			if got := f.Render(identity, false); got != tt.text {
+				t.Errorf("Render(identity) differs from the input:\n got %q\nwant %q", got, tt.text)
+			}
+			if got := f.Render(nil, false); got != tt.text {
+				t.Errorf("Render(nil) differs from the input:\n got %q\nwant %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestMarkupAndURLsNotTranslated(t *testing.T) {
+	// 这些片段出现在字幕文件中，但不能出现在交给模型的任何文本中
+	protected := []string{
+		"-->", "00:0", "WEBVTT", "Kind:", "NOTE", "written by hand", "::cue", "intro", "align:start",
+		"<i>", "<v", "Roger", "<c", "{\\an8}", "\ufeff",
+		"://", "example.com", "a_(b)",
+	}
+
+	for _, tt := range testFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cue := range Parse(tt.text).Cues() {
+				for _, text := range ParseCueText(cue.Text).TextNodes() {
+					for _, p := range protected {
+						if strings.Contains(text, p) {
+							t.Errorf("%q handed out for translation, contains %q", text, p)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCueTextURLPunctuation(t *testing.T) {
+	tests := []struct {
+		text      string
+		wantNodes []string
+	}{
+		{text: "Visit https://example.com/help.", wantNodes: []string{"Visit", "."}},
+		{text: "(see https://example.com/a_(b))", wantNodes: []string{"(see", ")"}},
+		{text: "Open http://example.com, then sign in!", wantNodes: []string{"Open", ", then sign in!"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			nodes := ParseCueText(tt.text).TextNodes()
+			if strings.Join(nodes, "|") != strings.Join(tt.wantNodes, "|") {
+				t.Errorf("TextNodes() = %q, want %q", nodes, tt.wantNodes)
+			}
+		})
+	}
+}
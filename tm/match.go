@@ -0,0 +1,181 @@
+// tm/match.go
+package tm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxFuzzyRunes 参与模糊查找的原文最大长度（字符数），更长的原文只能精确匹配
+const maxFuzzyRunes = 1000
+
+// maxCandidates 按 n-gram 重合度初筛后计算编辑距离的候选条目数
+const maxCandidates = 50
+
+// normalize 规范化原文：去掉首尾空白并将连续空白合并为一个空格，作为精确匹配的键
+func normalize(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ngrams 返回规范化原文（小写）的字符三元组集合，首尾补空格使短文本也能参与比较；超过 maxFuzzyRunes 时返回 nil
+func ngrams(key string) map[string]struct{} {
+	runes := []rune(" " + strings.ToLower(key) + " ")
+	if len(runes) > maxFuzzyRunes+2 {
+		return nil
+	}
+	grams := make(map[string]struct{}, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])] = struct{}{}
+	}
+	return grams
+}
+
+// search 查找与规范化文本 key 相似度不低于 minScore 的条目
+// 先按共有的 n-gram 数（Dice 系数）选出候选，再用编辑距离计算相似度
+func (m *memory) search(key string, minScore float64) []Match {
+	if r, ok := m.records[key]; ok {
+		return []Match{{Entry: r.entry, Score: 1}}
+	}
+
+	grams := ngrams(key)
+	if grams == nil {
+		return nil
+	}
+	shared := make(map[string]int)
+	for g := range grams {
+		for k := range m.grams[g] {
+			shared[k]++
+		}
+	}
+
+	type candidate struct {
+		record *record
+		dice   float64
+	}
+	keyLen := len([]rune(key))
+	candidates := make([]candidate, 0, len(shared))
+	for k, n := range shared {
+		r := m.records[k]
+		// 相似度不会超过两段文本长度之比
+		recordLen := len([]rune(r.key))
+		if float64(min(keyLen, recordLen))/float64(max(keyLen, recordLen)) < minScore {
+			continue
+		}
+		candidates = append(candidates, candidate{record: r, dice: 2 * float64(n) / float64(len(grams)+r.grams)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].dice > candidates[j].dice
+	})
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+
+	var matches []Match
+	for _, c := range candidates {
+		if score := similarity(key, c.record.key); score >= minScore {
+			matches = append(matches, Match{Entry: c.record.entry, Score: score})
+		}
+	}
+	return matches
+}
+
+// similarity 返回两段文本基于编辑距离的相似度：1 - 距离 / 较长文本的字符数
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein 计算字符级编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// reuseTokenPattern 直接采用记忆译文时必须与查询文本一致的片段：数字、printf/ICU/模板占位符与标签
+var reuseTokenPattern = regexp.MustCompile(`\d+(?:[.,]\d+)*|%(?:\d+\$)?[-+ #0]*\d*(?:\.\d+)?[a-zA-Z@]|\{\{[^{}]*\}\}|\$?\{[^{}]*\}|<[^<>]+>`)
+
+// negationWords 常见语言的否定词（小写）
+var negationWords = map[string]bool{
+	"not": true, "no": true, "never": true, "none": true, "nothing": true, "nobody": true, "neither": true, "nor": true, "cannot": true, "without": true,
+	"nicht": true, "kein": true, "keine": true, "keinen": true, "keinem": true, "keiner": true, "keines": true, "nie": true, "niemals": true, "ohne": true,
+	"ne": true, "pas": true, "jamais": true, "aucun": true, "aucune": true, "rien": true, "personne": true, "sans": true, "non": true,
+	"nunca": true, "nada": true, "nadie": true, "ningún": true, "ninguna": true, "ninguno": true, "sin": true,
+	"não": true, "nem": true, "nenhum": true, "nenhuma": true, "sem": true,
+	"mai": true, "niente": true, "nessun": true, "nessuna": true, "nessuno": true, "senza": true,
+	"niet": true, "geen": true, "nooit": true, "zonder": true,
+	"не": true, "нет": true, "ни": true, "никогда": true, "без": true,
+}
+
+// cjkNegations 中日韩文本中的否定标记，按出现次数比较
+var cjkNegations = []string{"不", "没", "沒", "未", "无", "無", "别", "別", "非", "ない", "ません", "않", "못"}
+
+// Reusable 判断记忆中的原文 source 能否直接代替查询文本 text 的译文：
+// 两者的数字、占位符与否定词必须完全一致，只差在这些片段上的相似原文含义不同，只能作为参考译文
+func Reusable(text, source string) bool {
+	a, b := reuseTokens(text), reuseTokens(source)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// reuseTokens 返回文本中数字、占位符与否定词的有序列表
+func reuseTokens(text string) []string {
+	tokens := reuseTokenPattern.FindAllString(text, -1)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	})
+	for _, word := range words {
+		if negationWords[word] || strings.HasSuffix(word, "n't") || strings.HasSuffix(word, "n’t") {
+			tokens = append(tokens, "neg:"+word)
+		}
+	}
+	for _, marker := range cjkNegations {
+		for n := strings.Count(text, marker); n > 0; n-- {
+			tokens = append(tokens, "neg:"+marker)
+		}
+	}
+	return tokens
+}
+
+// Instructions 生成提示词中的参考译文说明
+func Instructions(matches []Match) string {
+	if len(matches) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("The following reference translations of similar texts come from the translation memory. " +
+		"Follow their terminology and style where they apply, but translate the text itself and do not copy parts that differ:\n")
+	for _, match := range matches {
+		fmt.Fprintf(&b, "- %q => %q\n", match.Source, match.Target)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,352 @@
+// tm/tm.go
+package tm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"transbridge/internal/utils"
+)
+
+// 条目来源
+const (
+	OriginImport  = "import"  // TMX 文件或管理接口导入
+	OriginLearned = "learned" // 模型译文自动写入
+)
+
+// Entry 翻译记忆中的一对原文与译文
+type Entry struct {
+	Source    string    `json:"source"`
+	Target    string    `json:"target"`
+	Origin    string    `json:"origin,omitempty"` // import 或 learned
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Match 模糊查找的结果，Score 为原文与查询文本的相似度（0~1）
+type Match struct {
+	Entry
+	Score float64 `json:"score"`
+}
+
+// PairInfo 单个语言对翻译记忆的概况
+type PairInfo struct {
+	SourceLang string    `json:"source_lang"`
+	TargetLang string    `json:"target_lang"`
+	Entries    int       `json:"entries"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// record 存储中的条目，key 为规范化后的原文
+type record struct {
+	entry Entry
+	key   string
+	grams int // n-gram 数量，原文过长不参与模糊查找时为 0
+}
+
+// memory 单个语言对的翻译记忆，按规范化原文索引，并维护 n-gram 倒排索引用于模糊查找
+type memory struct {
+	records   map[string]*record
+	grams     map[string]map[string]struct{}
+	updatedAt time.Time
+}
+
+// Store 按语言对存放翻译记忆，可并发读写；条目只保存在内存中，重启后需重新从 TMX 文件加载
+type Store struct {
+	mu         sync.RWMutex
+	memories   map[string]*memory
+	learned    int
+	maxLearned int
+}
+
+// NewStore 创建翻译记忆存储，maxLearned 为自动写入条目数的上限，0 表示不限制
+func NewStore(maxLearned int) *Store {
+	return &Store{
+		memories:   make(map[string]*memory),
+		maxLearned: maxLearned,
+	}
+}
+
+// Upsert 新增或更新指定语言对的条目，返回写入的条目数
+func (s *Store) Upsert(sourceLang, targetLang string, entries []Entry) (int, error) {
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, entry := range entries {
+		if entry.Origin == "" {
+			entry.Origin = OriginImport
+		}
+		if s.put(key, entry) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Learn 写入一条模型译文；语言对无效或自动写入的条目数已达上限时忽略
+// 已存在的原文只在原条目也是自动写入时更新，不覆盖导入的译文
+func (s *Store) Learn(sourceLang, targetLang, source, target string) bool {
+	if s == nil {
+		return false
+	}
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := Entry{Source: source, Target: target, Origin: OriginLearned}
+	if m, ok := s.memories[key]; ok {
+		if r, ok := m.records[normalize(source)]; ok {
+			return r.entry.Origin == OriginLearned && s.put(key, entry)
+		}
+	}
+	if s.maxLearned > 0 && s.learned >= s.maxLearned {
+		return false
+	}
+	return s.put(key, entry)
+}
+
+// put 写入一条记录并更新索引，调用方需持有写锁
+func (s *Store) put(pair string, entry Entry) bool {
+	entry.Source = strings.TrimSpace(entry.Source)
+	entry.Target = strings.TrimSpace(entry.Target)
+	if entry.Source == "" || entry.Target == "" {
+		return false
+	}
+	if entry.UpdatedAt.IsZero() {
+		entry.UpdatedAt = time.Now()
+	}
+
+	m, ok := s.memories[pair]
+	if !ok {
+		m = &memory{
+			records: make(map[string]*record),
+			grams:   make(map[string]map[string]struct{}),
+		}
+		s.memories[pair] = m
+	}
+
+	key := normalize(entry.Source)
+	if old, ok := m.records[key]; ok {
+		s.remove(m, old)
+	}
+
+	r := &record{entry: entry, key: key}
+	if grams := ngrams(key); grams != nil {
+		r.grams = len(grams)
+		for g := range grams {
+			postings, ok := m.grams[g]
+			if !ok {
+				postings = make(map[string]struct{})
+				m.grams[g] = postings
+			}
+			postings[key] = struct{}{}
+		}
+	}
+	m.records[key] = r
+	if entry.Origin == OriginLearned {
+		s.learned++
+	}
+	m.updatedAt = time.Now()
+	return true
+}
+
+// remove 删除一条记录及其索引，调用方需持有写锁
+func (s *Store) remove(m *memory, r *record) {
+	delete(m.records, r.key)
+	if r.grams > 0 {
+		for g := range ngrams(r.key) {
+			postings := m.grams[g]
+			delete(postings, r.key)
+			if len(postings) == 0 {
+				delete(m.grams, g)
+			}
+		}
+	}
+	if r.entry.Origin == OriginLearned {
+		s.learned--
+	}
+}
+
+// Delete 删除指定语言对中的条目；sources 为空时删除整个语言对，返回删除的条目数
+func (s *Store) Delete(sourceLang, targetLang string, sources []string) (int, error) {
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.memories[key]
+	if !ok {
+		return 0, nil
+	}
+
+	if len(sources) == 0 {
+		for _, r := range m.records {
+			if r.entry.Origin == OriginLearned {
+				s.learned--
+			}
+		}
+		delete(s.memories, key)
+		return len(m.records), nil
+	}
+
+	count := 0
+	for _, source := range sources {
+		if r, ok := m.records[normalize(source)]; ok {
+			s.remove(m, r)
+			count++
+		}
+	}
+	if len(m.records) == 0 {
+		delete(s.memories, key)
+	} else {
+		m.updatedAt = time.Now()
+	}
+	return count, nil
+}
+
+// Entries 返回指定语言对的全部条目，按原文排序
+func (s *Store) Entries(sourceLang, targetLang string) ([]Entry, error) {
+	key, err := pairKey(sourceLang, targetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.memories[key]
+	if !ok {
+		return []Entry{}, nil
+	}
+	return sortedEntries(m), nil
+}
+
+// Pairs 返回所有语言对翻译记忆的概况
+func (s *Store) Pairs() []PairInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pairs := make([]PairInfo, 0, len(s.memories))
+	for key, m := range s.memories {
+		sourceLang, targetLang, _ := strings.Cut(key, "->")
+		pairs = append(pairs, PairInfo{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			Entries:    len(m.records),
+			UpdatedAt:  m.updatedAt,
+		})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].SourceLang != pairs[j].SourceLang {
+			return pairs[i].SourceLang < pairs[j].SourceLang
+		}
+		return pairs[i].TargetLang < pairs[j].TargetLang
+	})
+	return pairs
+}
+
+// Search 在适用于该语言对的翻译记忆中查找与 text 相似度不低于 minScore 的条目，最多返回 limit 条，按相似度降序排列
+// 目标语言依次查找基础语言与完整语言代码的记忆，例如 en-US->zh-TW 会查找 en->zh 与 en->zh-TW；
+// 同一原文在两个语言对中都出现时取更具体的语言对
+func (s *Store) Search(sourceLang, targetLang, text string, minScore float64, limit int) []Match {
+	if s == nil || limit <= 0 {
+		return nil
+	}
+	key := normalize(text)
+	if key == "" {
+		return nil
+	}
+	src := utils.BaseLanguage(utils.NormalizeLanguageCode(sourceLang))
+	targets := langCandidates(targetLang)
+	if src == "" || len(targets) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	best := make(map[string]Match)
+	for _, tgt := range targets {
+		m, ok := s.memories[src+"->"+tgt]
+		if !ok {
+			continue
+		}
+		for _, match := range m.search(key, minScore) {
+			best[normalize(match.Source)] = match
+		}
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].UpdatedAt.After(matches[j].UpdatedAt)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// pairKey 规范化语言对；源语言只取基础语言，原文的地区差异不影响匹配
+func pairKey(sourceLang, targetLang string) (string, error) {
+	src, err := normalizeLang(sourceLang)
+	if err != nil {
+		return "", err
+	}
+	src = utils.BaseLanguage(src)
+	tgt, err := normalizeLang(targetLang)
+	if err != nil {
+		return "", err
+	}
+	return src + "->" + tgt, nil
+}
+
+func normalizeLang(lang string) (string, error) {
+	code := utils.NormalizeLanguageCode(lang)
+	if code == "" || !utils.IsValidLanguageCode(utils.BaseLanguage(code)) {
+		return "", fmt.Errorf("invalid language code %q", lang)
+	}
+	return code, nil
+}
+
+// langCandidates 返回从通用到具体的候选语言键，例如 "zh-TW" -> ["zh", "zh-TW"]
+func langCandidates(lang string) []string {
+	code := utils.NormalizeLanguageCode(lang)
+	if code == "" {
+		return nil
+	}
+	if base := utils.BaseLanguage(code); base != code {
+		return []string{base, code}
+	}
+	return []string{code}
+}
+
+func sortedEntries(m *memory) []Entry {
+	entries := make([]Entry, 0, len(m.records))
+	for _, r := range m.records {
+		entries = append(entries, r.entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Source < entries[j].Source
+	})
+	return entries
+}
@@ -0,0 +1,179 @@
+package tm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchThresholds(t *testing.T) {
+	s := NewStore(0)
+	if _, err := s.Upsert("en", "zh", []Entry{
+		{Source: "Save the file before closing", Target: "关闭前保存文件"},
+		{Source: "Delete the selected rows", Target: "删除选中的行"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		targetLang string
+		text       string
+		minScore   float64
+		want       []string // 按相似度降序返回的原文
+		wantScore  float64  // 第一条的相似度，0 表示不检查
+	}{
+		{name: "exact match", targetLang: "zh", text: "Save the file before closing", minScore: 1, want: []string{"Save the file before closing"}, wantScore: 1},
+		{name: "extra whitespace ignored", targetLang: "zh", text: "  Save the  file before closing ", minScore: 1, want: []string{"Save the file before closing"}, wantScore: 1},
+		{name: "one word changed below exact threshold", targetLang: "zh", text: "Save the file before leaving", minScore: 1},
+		{name: "one word changed above fuzzy threshold", targetLang: "zh", text: "Save the file before leaving", minScore: 0.7, want: []string{"Save the file before closing"}},
+		{name: "unrelated text", targetLang: "zh", text: "Open the settings page", minScore: 0.5},
+		{name: "regional target falls back to base language", targetLang: "zh-TW", text: "Delete the selected rows", minScore: 1, want: []string{"Delete the selected rows"}, wantScore: 1},
+		{name: "other target language", targetLang: "de", text: "Delete the selected rows", minScore: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := s.Search("en", tt.targetLang, tt.text, tt.minScore, 3)
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Source)
+				if m.Score < tt.minScore || m.Score > 1 {
+					t.Errorf("%q scored %v, want within [%v, 1]", m.Source, m.Score, tt.minScore)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Search(%q, %v) = %q, want %q", tt.text, tt.minScore, got, tt.want)
+			}
+			if tt.wantScore > 0 && matches[0].Score != tt.wantScore {
+				t.Errorf("score = %v, want %v", matches[0].Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "abcd", b: "abcd", want: 1},
+		{a: "abcd", b: "abce", want: 0.75},
+		{a: "abcd", b: "ab", want: 0.5},
+		{a: "保存文件", b: "保存文档", want: 0.75},
+		{a: "", b: "", want: 1},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestReusable(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		source string
+		want   bool
+	}{
+		{name: "same tokens", text: "Delete 3 files", source: "Delete 3 files!", want: true},
+		{name: "wording differs only", text: "Delete the selected rows", source: "Delete the chosen rows", want: true},
+		{name: "number differs", text: "Delete 3 files", source: "Delete 5 files", want: false},
+		{name: "decimal differs", text: "Costs 1.50 USD", source: "Costs 1.05 USD", want: false},
+		{name: "printf placeholder differs", text: "%d files deleted", source: "%s files deleted", want: false},
+		{name: "icu placeholder differs", text: "Hello, {name}", source: "Hello, {user}", want: false},
+		{name: "tag differs", text: "Click <b>here</b>", source: "Click <i>here</i>", want: false},
+		{name: "negation added", text: "Do not save changes", source: "Do save changes", want: false},
+		{name: "contraction negation", text: "Don't save changes", source: "Do save changes", want: false},
+		{name: "german negation", text: "Datei nicht speichern", source: "Datei speichern", want: false},
+		{name: "cjk negation", text: "不保存更改", source: "保存更改", want: false},
+		{name: "same negation", text: "Never ask again", source: "Never show again", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reusable(tt.text, tt.source); got != tt.want {
+				t.Errorf("Reusable(%q, %q) = %v, want %v", tt.text, tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="test" creationtoolversion="1" segtype="sentence" o-tmf="test" adminlang="en" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu changedate="20240102T030405Z">
+      <tuv xml:lang="en-US"><seg>Save <ph x="1">&lt;b&gt;</ph>all<ph x="2">&lt;/b&gt;</ph> files</seg></tuv>
+      <tuv xml:lang="zh-CN"><seg>保存所有文件</seg></tuv>
+      <tuv xml:lang="de-DE"><seg>Alle Dateien speichern</seg></tuv>
+    </tu>
+    <tu>
+      <prop type="x-transbridge-origin">learned</prop>
+      <tuv xml:lang="en-US"><seg>Tom &amp; Jerry</seg></tuv>
+      <tuv xml:lang="zh-CN"><seg>汤姆 &amp; 杰瑞</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+
+func TestTMXRoundTrip(t *testing.T) {
+	s := NewStore(0)
+	n, err := s.ImportTMX(strings.NewReader(testTMX), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("imported %d entries, want 3", n)
+	}
+
+	zh, err := s.Entries("en", "zh-CN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byText := make(map[string]Entry)
+	for _, e := range zh {
+		byText[e.Source] = e
+	}
+	saved, ok := byText["Save all files"]
+	if !ok || saved.Target != "保存所有文件" {
+		t.Errorf("inline codes not dropped: %+v", zh)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !saved.UpdatedAt.Equal(want) {
+		t.Errorf("updated at = %v, want %v", saved.UpdatedAt, want)
+	}
+	if e := byText["Tom & Jerry"]; e.Target != "汤姆 & 杰瑞" || e.Origin != OriginLearned {
+		t.Errorf("entry = %+v, want unescaped text with learned origin", e)
+	}
+
+	var exported bytes.Buffer
+	if err := s.ExportTMX(&exported, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	restored := NewStore(0)
+	if _, err := restored.ImportTMX(&exported, "", ""); err != nil {
+		t.Fatalf("exported TMX does not import: %v\n%s", err, exported.String())
+	}
+
+	pairs, restoredPairs := s.Pairs(), restored.Pairs()
+	if len(restoredPairs) != len(pairs) {
+		t.Fatalf("pairs after round trip = %+v, want %+v", restoredPairs, pairs)
+	}
+	for i, pair := range pairs {
+		if restoredPairs[i].SourceLang != pair.SourceLang || restoredPairs[i].TargetLang != pair.TargetLang || restoredPairs[i].Entries != pair.Entries {
+			t.Errorf("pair %d after round trip = %+v, want %+v", i, restoredPairs[i], pair)
+		}
+
+		// TMX 的修改时间精确到秒
+		want, _ := s.Entries(pair.SourceLang, pair.TargetLang)
+		for j := range want {
+			want[j].UpdatedAt = want[j].UpdatedAt.UTC().Truncate(time.Second)
+		}
+		got, _ := restored.Entries(pair.SourceLang, pair.TargetLang)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s->%s entries after round trip = %+v, want %+v", pair.SourceLang, pair.TargetLang, got, want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// tm/tmx.go
+package tm
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"transbridge/internal/utils"
+)
+
+// tmxTimeLayout TMX 中 creationdate、changedate 的格式
+const tmxTimeLayout = "20060102T150405Z"
+
+// tmxOriginProp 导出时记录条目来源的 prop 类型
+const tmxOriginProp = "x-transbridge-origin"
+
+// allLanguages TMX 头部 srclang 取该值时，各翻译单元的源语言由单元自身的 srclang 指定
+const allLanguages = "*all*"
+
+// inlineCodes TMX 段落中包裹原始格式代码的内联元素，其内容不属于文本
+var inlineCodes = map[string]bool{"bpt": true, "ept": true, "ph": true, "it": true, "ut": true}
+
+// tmxUnit 解析出的翻译单元
+type tmxUnit struct {
+	srcLang  string
+	updated  time.Time
+	origin   string
+	variants []tmxVariant
+}
+
+type tmxVariant struct {
+	lang string
+	text string
+}
+
+// LoadFile 从 TMX 文件加载条目，返回加载的条目数；语言为空时使用 TMX 中的语言，见 ImportTMX
+func (s *Store) LoadFile(path, sourceLang, targetLang string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open translation memory file: %w", err)
+	}
+	defer f.Close()
+
+	count, err := s.ImportTMX(f, sourceLang, targetLang)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load translation memory file %s: %w", path, err)
+	}
+	return count, nil
+}
+
+// ImportTMX 从 TMX 文档导入条目，返回写入的条目数
+// sourceLang 为空时使用翻译单元或头部的 srclang；targetLang 为空时导入源语言以外的所有语言。
+// 指定语言时按基础语言匹配 TMX 中的语言（例如 "zh" 匹配 zh-CN），条目存入指定的语言对
+func (s *Store) ImportTMX(r io.Reader, sourceLang, targetLang string) (int, error) {
+	units, headerLang, err := parseTMX(r)
+	if err != nil {
+		return 0, err
+	}
+
+	byPair := make(map[[2]string][]Entry)
+	var order [][2]string
+	for _, unit := range units {
+		srcLang := sourceLang
+		if srcLang == "" {
+			srcLang = unit.srcLang
+		}
+		if srcLang == "" || srcLang == allLanguages {
+			srcLang = headerLang
+		}
+		if srcLang == "" || srcLang == allLanguages {
+			continue
+		}
+
+		source := -1
+		for i, variant := range unit.variants {
+			if langMatches(variant.lang, srcLang) {
+				source = i
+				break
+			}
+		}
+		if source < 0 {
+			continue
+		}
+
+		for _, variant := range unit.variants {
+			if langMatches(variant.lang, srcLang) {
+				continue
+			}
+			pair := [2]string{srcLang, variant.lang}
+			if targetLang != "" {
+				if !langMatches(variant.lang, targetLang) {
+					continue
+				}
+				pair[1] = targetLang
+			}
+			if sourceLang == "" {
+				pair[0] = unit.variants[source].lang
+			}
+			if _, ok := byPair[pair]; !ok {
+				order = append(order, pair)
+			}
+			byPair[pair] = append(byPair[pair], Entry{
+				Source:    unit.variants[source].text,
+				Target:    variant.text,
+				Origin:    unit.origin,
+				UpdatedAt: unit.updated,
+			})
+		}
+	}
+
+	count := 0
+	for _, pair := range order {
+		n, err := s.Upsert(pair[0], pair[1], byPair[pair])
+		if err != nil {
+			return count, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// langMatches 判断 TMX 中的语言是否与指定语言一致；指定语言不含地区等子标签时按基础语言比较
+func langMatches(lang, want string) bool {
+	code := utils.NormalizeLanguageCode(lang)
+	wantCode := utils.NormalizeLanguageCode(want)
+	if code == "" || wantCode == "" {
+		return false
+	}
+	if code == wantCode {
+		return true
+	}
+	return utils.BaseLanguage(wantCode) == wantCode && utils.BaseLanguage(code) == wantCode
+}
+
+// parseTMX 解析 TMX 文档，返回翻译单元与头部的 srclang
+// 段落中的内联格式代码（bpt、ept、ph、it、ut）被忽略，只保留文本
+func parseTMX(r io.Reader) ([]tmxUnit, string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		units      []tmxUnit
+		headerLang string
+		unit       *tmxUnit
+		variant    *tmxVariant
+		prop       string
+		propText   strings.Builder
+		seg        *strings.Builder
+		skipDepth  int // 位于内联格式代码中的层数
+		root       bool
+	)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid TMX document: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !root {
+				if t.Name.Local != "tmx" {
+					return nil, "", errors.New("invalid TMX document: root element is not <tmx>")
+				}
+				root = true
+				continue
+			}
+			if skipDepth > 0 || (seg != nil && inlineCodes[t.Name.Local]) {
+				skipDepth++
+				continue
+			}
+			switch t.Name.Local {
+			case "header":
+				headerLang = attr(t, "srclang")
+			case "tu":
+				unit = &tmxUnit{srcLang: attr(t, "srclang"), origin: OriginImport}
+				for _, name := range []string{"changedate", "creationdate"} {
+					if ts, err := time.Parse(tmxTimeLayout, attr(t, name)); err == nil {
+						unit.updated = ts
+						break
+					}
+				}
+			case "prop":
+				if unit != nil && variant == nil {
+					prop = attr(t, "type")
+					propText.Reset()
+				}
+			case "tuv":
+				if unit != nil {
+					variant = &tmxVariant{lang: attr(t, "lang")}
+				}
+			case "seg":
+				if variant != nil {
+					seg = &strings.Builder{}
+				}
+			}
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			switch t.Name.Local {
+			case "tu":
+				if unit != nil {
+					units = append(units, *unit)
+				}
+				unit = nil
+			case "prop":
+				if prop == tmxOriginProp && unit != nil {
+					unit.origin = strings.TrimSpace(propText.String())
+				}
+				prop = ""
+			case "tuv":
+				if unit != nil && variant != nil && variant.lang != "" {
+					unit.variants = append(unit.variants, *variant)
+				}
+				variant = nil
+			case "seg":
+				if variant != nil && seg != nil {
+					variant.text = seg.String()
+				}
+				seg = nil
+			}
+
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			if seg != nil {
+				seg.Write(t)
+			} else if prop != "" {
+				propText.Write(t)
+			}
+		}
+	}
+	if !root {
+		return nil, "", errors.New("invalid TMX document: empty document")
+	}
+	return units, headerLang, nil
+}
+
+// attr 返回元素的属性值；lang 同时匹配 xml:lang（TMX 1.4）与 lang（TMX 1.1）
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// tmxDocument 导出用的 TMX 1.4 文档结构
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Body    tmxBody   `xml:"body"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTmf                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+type tmxBody struct {
+	Units []tmxUnitXML `xml:"tu"`
+}
+
+type tmxUnitXML struct {
+	SrcLang    string          `xml:"srclang,attr,omitempty"`
+	ChangeDate string          `xml:"changedate,attr,omitempty"`
+	Props      []tmxPropXML    `xml:"prop"`
+	Variants   []tmxVariantXML `xml:"tuv"`
+}
+
+type tmxPropXML struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type tmxVariantXML struct {
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Seg  string `xml:"seg"`
+}
+
+// ExportTMX 将翻译记忆导出为 TMX 1.4 文档；语言为空时导出所有语言对
+func (s *Store) ExportTMX(w io.Writer, sourceLang, targetLang string) error {
+	var keys []string
+	if sourceLang != "" || targetLang != "" {
+		key, err := pairKey(sourceLang, targetLang)
+		if err != nil {
+			return err
+		}
+		keys = []string{key}
+	}
+
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "TransBridge",
+			CreationToolVersion: "1.0",
+			SegType:             "sentence",
+			OTmf:                "TransBridge",
+			AdminLang:           "en",
+			SrcLang:             allLanguages,
+			DataType:            "plaintext",
+		},
+	}
+
+	s.mu.RLock()
+	if keys == nil {
+		for key := range s.memories {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	srcLangs := make(map[string]bool)
+	for _, key := range keys {
+		m, ok := s.memories[key]
+		if !ok {
+			continue
+		}
+		src, tgt, _ := strings.Cut(key, "->")
+		srcLangs[src] = true
+		for _, entry := range sortedEntries(m) {
+			doc.Body.Units = append(doc.Body.Units, tmxUnitXML{
+				SrcLang:    src,
+				ChangeDate: entry.UpdatedAt.UTC().Format(tmxTimeLayout),
+				Props:      []tmxPropXML{{Type: tmxOriginProp, Value: entry.Origin}},
+				Variants: []tmxVariantXML{
+					{Lang: src, Seg: entry.Source},
+					{Lang: tgt, Seg: entry.Target},
+				},
+			})
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(srcLangs) == 1 {
+		for src := range srcLangs {
+			doc.Header.SrcLang = src
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
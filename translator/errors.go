@@ -0,0 +1,49 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"transbridge/internal/utils"
+)
+
+// ErrEmptyResponse 上游返回了空的翻译结果
+var ErrEmptyResponse = errors.New("no translation result in response")
+
+// UpstreamError 上游接口返回了非成功状态码
+type UpstreamError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("upstream status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsFailoverError 判断错误是否值得切换到下一个模型重试
+// 超时、网络错误、5xx、429 以及空响应都会触发故障转移；
+// 调用方主动取消和其余 4xx（请求本身有问题）则不会
+func IsFailoverError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, utils.ErrInvalidPromptTemplate) {
+		return false
+	}
+
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		switch {
+		case upstreamErr.StatusCode >= 500:
+			return true
+		case upstreamErr.StatusCode == http.StatusTooManyRequests,
+			upstreamErr.StatusCode == http.StatusRequestTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	return true
+}
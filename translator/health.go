@@ -0,0 +1,334 @@
+package translator
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常放行
+	BreakerOpen     BreakerState = "open"      // 熔断中，拒绝请求
+	BreakerHalfOpen BreakerState = "half_open" // 放行少量探测请求以判断是否恢复
+)
+
+// latencyAlpha 延迟 EWMA 的平滑系数
+const latencyAlpha = 0.2
+
+// HealthOptions 健康跟踪与熔断选项
+type HealthOptions struct {
+	Enabled            bool          // 是否启用熔断；关闭时仍统计健康数据但不会拒绝请求
+	FailureThreshold   int           // 连续失败多少次后熔断
+	ErrorRateThreshold float64       // 滚动窗口内错误率达到该值时熔断（0~1）
+	MinRequests        int           // 计算错误率所需的最少请求数
+	WindowSize         int           // 滚动窗口大小（最近 N 次请求）
+	OpenTimeout        time.Duration // 熔断后经过多久进入半开状态
+	HalfOpenProbes     int           // 半开状态下允许同时进行的探测请求数
+}
+
+// modelHealth 单个模型的健康状态
+type modelHealth struct {
+	state               BreakerState
+	consecutiveFailures int
+	window              []bool // 滚动窗口，true 表示失败
+	windowPos           int
+	windowCount         int
+	latencyEWMA         float64 // 毫秒
+	openedAt            time.Time
+	probesInFlight      int
+	totalRequests       int64
+	totalFailures       int64
+	lastError           string
+	lastErrorAt         time.Time
+	lastSuccessAt       time.Time
+}
+
+// ModelHealthSnapshot 模型健康状态快照，用于管理接口展示
+type ModelHealthSnapshot struct {
+	Provider            string       `json:"provider"`
+	Model               string       `json:"model"`
+	APIURL              string       `json:"api_url"`
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	ErrorRate           float64      `json:"error_rate"`
+	WindowRequests      int          `json:"window_requests"`
+	LatencyEWMAMs       float64      `json:"latency_ewma_ms"`
+	TotalRequests       int64        `json:"total_requests"`
+	TotalFailures       int64        `json:"total_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time   `json:"last_error_at,omitempty"`
+	LastSuccessAt       *time.Time   `json:"last_success_at,omitempty"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+}
+
+// HealthTracker 按 ModelIdentifier 跟踪模型健康状态并实现熔断
+type HealthTracker struct {
+	mu     sync.Mutex
+	opts   HealthOptions
+	models map[ModelIdentifier]*modelHealth
+}
+
+// NewHealthTracker 创建健康跟踪器
+func NewHealthTracker(opts HealthOptions) *HealthTracker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.ErrorRateThreshold <= 0 || opts.ErrorRateThreshold > 1 {
+		opts.ErrorRateThreshold = 0.5
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = 10
+	}
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = 20
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	if opts.HalfOpenProbes <= 0 {
+		opts.HalfOpenProbes = 1
+	}
+
+	return &HealthTracker{
+		opts:   opts,
+		models: make(map[ModelIdentifier]*modelHealth),
+	}
+}
+
+// get 返回模型的健康状态，不存在时创建（调用方需持有锁）
+func (h *HealthTracker) get(id ModelIdentifier) *modelHealth {
+	mh, ok := h.models[id]
+	if !ok {
+		mh = &modelHealth{
+			state:  BreakerClosed,
+			window: make([]bool, h.opts.WindowSize),
+		}
+		h.models[id] = mh
+	}
+	return mh
+}
+
+// Available 判断模型当前是否可被选中（不占用探测名额）
+func (h *HealthTracker) Available(id ModelIdentifier) bool {
+	if !h.opts.Enabled {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	mh := h.get(id)
+	switch mh.state {
+	case BreakerOpen:
+		return time.Since(mh.openedAt) >= h.opts.OpenTimeout
+	case BreakerHalfOpen:
+		return mh.probesInFlight < h.opts.HalfOpenProbes
+	default:
+		return true
+	}
+}
+
+// ProbeReady 判断模型是否处于熔断冷却结束、等待探测的状态
+func (h *HealthTracker) ProbeReady(id ModelIdentifier) bool {
+	if !h.opts.Enabled {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	mh := h.get(id)
+	return mh.state == BreakerOpen && time.Since(mh.openedAt) >= h.opts.OpenTimeout
+}
+
+// Allow 在真正发起请求前调用，决定是否放行；半开状态下会占用一个探测名额
+func (h *HealthTracker) Allow(id ModelIdentifier) bool {
+	if !h.opts.Enabled {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	mh := h.get(id)
+	switch mh.state {
+	case BreakerOpen:
+		if time.Since(mh.openedAt) < h.opts.OpenTimeout {
+			return false
+		}
+		mh.state = BreakerHalfOpen
+		mh.probesInFlight = 1
+		return true
+	case BreakerHalfOpen:
+		if mh.probesInFlight >= h.opts.HalfOpenProbes {
+			return false
+		}
+		mh.probesInFlight++
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess 记录一次成功请求
+func (h *HealthTracker) RecordSuccess(id ModelIdentifier, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	mh := h.get(id)
+	mh.record(false, latency)
+	mh.consecutiveFailures = 0
+	mh.lastSuccessAt = time.Now()
+
+	if mh.state != BreakerClosed {
+		// 探测成功，恢复正常并清空窗口，避免旧错误立即再次触发熔断
+		mh.state = BreakerClosed
+		mh.probesInFlight = 0
+		mh.resetWindow()
+	}
+}
+
+// RecordFailure 记录一次上游故障
+func (h *HealthTracker) RecordFailure(id ModelIdentifier, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	mh := h.get(id)
+	mh.record(true, latency)
+	mh.consecutiveFailures++
+	mh.totalFailures++
+	mh.lastErrorAt = time.Now()
+	if err != nil {
+		mh.lastError = err.Error()
+	}
+
+	if !h.opts.Enabled {
+		return
+	}
+
+	switch mh.state {
+	case BreakerHalfOpen:
+		// 探测失败，重新熔断
+		mh.trip()
+	case BreakerClosed:
+		if mh.consecutiveFailures >= h.opts.FailureThreshold ||
+			(mh.windowCount >= h.opts.MinRequests && mh.errorRate() >= h.opts.ErrorRateThreshold) {
+			mh.trip()
+		}
+	}
+}
+
+// Release 归还未计入成败的请求占用的探测名额（如调用方取消、请求本身错误）
+func (h *HealthTracker) Release(id ModelIdentifier) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	mh := h.get(id)
+	if mh.state == BreakerHalfOpen && mh.probesInFlight > 0 {
+		mh.probesInFlight--
+	}
+}
+
+// Snapshot 返回指定模型的健康状态快照
+func (h *HealthTracker) Snapshot(ids []ModelIdentifier) []ModelHealthSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshots := make([]ModelHealthSnapshot, 0, len(ids))
+	for _, id := range ids {
+		mh := h.get(id)
+		state := mh.state
+		if state == BreakerOpen && time.Since(mh.openedAt) >= h.opts.OpenTimeout {
+			state = BreakerHalfOpen
+		}
+
+		snapshot := ModelHealthSnapshot{
+			Provider:            id.Provider,
+			Model:               id.Model,
+			APIURL:              id.APIURL,
+			State:               state,
+			ConsecutiveFailures: mh.consecutiveFailures,
+			ErrorRate:           mh.errorRate(),
+			WindowRequests:      mh.windowCount,
+			LatencyEWMAMs:       mh.latencyEWMA,
+			TotalRequests:       mh.totalRequests,
+			TotalFailures:       mh.totalFailures,
+			LastError:           mh.lastError,
+		}
+		if !mh.lastErrorAt.IsZero() {
+			t := mh.lastErrorAt
+			snapshot.LastErrorAt = &t
+		}
+		if !mh.lastSuccessAt.IsZero() {
+			t := mh.lastSuccessAt
+			snapshot.LastSuccessAt = &t
+		}
+		if mh.state != BreakerClosed {
+			t := mh.openedAt
+			snapshot.OpenedAt = &t
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].Provider != snapshots[j].Provider {
+			return snapshots[i].Provider < snapshots[j].Provider
+		}
+		if snapshots[i].APIURL != snapshots[j].APIURL {
+			return snapshots[i].APIURL < snapshots[j].APIURL
+		}
+		return snapshots[i].Model < snapshots[j].Model
+	})
+	return snapshots
+}
+
+// record 写入滚动窗口并更新延迟 EWMA
+func (mh *modelHealth) record(failed bool, latency time.Duration) {
+	mh.totalRequests++
+
+	mh.window[mh.windowPos] = failed
+	mh.windowPos = (mh.windowPos + 1) % len(mh.window)
+	if mh.windowCount < len(mh.window) {
+		mh.windowCount++
+	}
+
+	ms := float64(latency.Microseconds()) / 1000
+	if mh.latencyEWMA == 0 {
+		mh.latencyEWMA = ms
+	} else {
+		mh.latencyEWMA = latencyAlpha*ms + (1-latencyAlpha)*mh.latencyEWMA
+	}
+}
+
+// errorRate 计算滚动窗口内的错误率
+func (mh *modelHealth) errorRate() float64 {
+	if mh.windowCount == 0 {
+		return 0
+	}
+
+	// 窗口未写满时，有效数据位于 [0, windowCount)
+	failures := 0
+	for i := 0; i < mh.windowCount; i++ {
+		if mh.window[i] {
+			failures++
+		}
+	}
+	return float64(failures) / float64(mh.windowCount)
+}
+
+func (mh *modelHealth) trip() {
+	mh.state = BreakerOpen
+	mh.openedAt = time.Now()
+	mh.probesInFlight = 0
+}
+
+func (mh *modelHealth) resetWindow() {
+	for i := range mh.window {
+		mh.window[i] = false
+	}
+	mh.windowPos = 0
+	mh.windowCount = 0
+}
@@ -4,7 +4,9 @@ package translator
 import (
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -22,16 +24,26 @@ func (m ModelIdentifier) String() string {
 	return fmt.Sprintf("%s/%s", m.Provider, m.Model)
 }
 
+// IdentifierOf 返回翻译器对应的模型标识
+func IdentifierOf(t Translator) ModelIdentifier {
+	return ModelIdentifier{
+		Provider: t.GetProvider(),
+		Model:    t.GetModel(),
+		APIURL:   t.GetAPIURL(),
+	}
+}
+
 // ModelManager 管理多个服务提供商和其模型
 type ModelManager struct {
 	translators  map[ModelIdentifier]Translator
 	modelWeights map[ModelIdentifier]int
 	defaultModel ModelIdentifier
+	health       *HealthTracker
 	mu           sync.RWMutex
 	rng          *rand.Rand
 }
 
-func NewModelManager(providers []config.ProviderConfig) (*ModelManager, error) {
+func NewModelManager(providers []config.ProviderConfig, healthOpts HealthOptions) (*ModelManager, error) {
 	if len(providers) == 0 {
 		return nil, errors.New("no providers configured")
 	}
@@ -39,6 +51,7 @@ func NewModelManager(providers []config.ProviderConfig) (*ModelManager, error) {
 	mm := &ModelManager{
 		translators:  make(map[ModelIdentifier]Translator),
 		modelWeights: make(map[ModelIdentifier]int),
+		health:       NewHealthTracker(healthOpts),
 	}
 
 	// 使用独立的随机源，避免未播种导致的可预测选择
@@ -121,6 +134,15 @@ func (mm *ModelManager) GetModel(provider, model string) (Translator, error) {
 	return nil, fmt.Errorf("model %s not found for provider %s", model, provider)
 }
 
+// Lookup 按完整标识（含 APIURL）获取翻译器
+func (mm *ModelManager) Lookup(id ModelIdentifier) (Translator, bool) {
+	mm.mu.RLock()
+	defer mm.mu.RUnlock()
+
+	translator, ok := mm.translators[id]
+	return translator, ok
+}
+
 // GetDefaultModel 获取默认模型
 func (mm *ModelManager) GetDefaultModel() Translator {
 	mm.mu.RLock()
@@ -128,12 +150,22 @@ func (mm *ModelManager) GetDefaultModel() Translator {
 	return mm.translators[mm.defaultModel]
 }
 
+// GetRandomModel 按权重随机选择一个模型，跳过已熔断的模型
+// 熔断冷却结束的模型会被优先选中，作为探测流量帮助其恢复
 func (mm *ModelManager) GetRandomModel() Translator {
 	mm.mu.RLock()
 	defer mm.mu.RUnlock()
 
 	var totalWeight int
-	for _, weight := range mm.modelWeights {
+	available := make(map[ModelIdentifier]int, len(mm.modelWeights))
+	for identifier, weight := range mm.modelWeights {
+		if mm.health.ProbeReady(identifier) {
+			return mm.translators[identifier]
+		}
+		if !mm.health.Available(identifier) {
+			continue
+		}
+		available[identifier] = weight
 		totalWeight += weight
 	}
 
@@ -142,7 +174,7 @@ func (mm *ModelManager) GetRandomModel() Translator {
 	}
 
 	r := mm.rng.Intn(totalWeight)
-	for identifier, weight := range mm.modelWeights {
+	for identifier, weight := range available {
 		r -= weight
 		if r <= 0 {
 			return mm.translators[identifier]
@@ -152,6 +184,90 @@ func (mm *ModelManager) GetRandomModel() Translator {
 	return mm.translators[mm.defaultModel]
 }
 
+// FailoverCandidates 返回按故障转移顺序排列的候选翻译器
+// 第一个为首选模型（指定的模型，或按权重随机选出的模型），
+// 其后优先排列尚未出现过的提供商/地址上的模型，同等条件下按权重从高到低排列
+func (mm *ModelManager) FailoverCandidates(provider, model string) []Translator {
+	var primary Translator
+	if provider != "" && model != "" {
+		if t, err := mm.GetModel(provider, model); err == nil {
+			primary = t
+		} else {
+			log.Printf("Specified model %s/%s not found: %v, falling back to default", provider, model, err)
+			primary = mm.GetDefaultModel()
+		}
+	} else {
+		primary = mm.GetRandomModel()
+	}
+
+	mm.mu.RLock()
+	defer mm.mu.RUnlock()
+
+	primaryID := IdentifierOf(primary)
+	rest := make([]ModelIdentifier, 0, len(mm.translators))
+	for identifier := range mm.translators {
+		// 已熔断的模型不参与故障转移
+		if identifier != primaryID && mm.health.Available(identifier) {
+			rest = append(rest, identifier)
+		}
+	}
+
+	sort.Slice(rest, func(i, j int) bool {
+		wi, wj := mm.modelWeights[rest[i]], mm.modelWeights[rest[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return rest[i].String() < rest[j].String()
+	})
+
+	// 每个尚未尝试过的上游（提供商+地址）先各取一个模型，其余模型排在后面
+	upstream := func(id ModelIdentifier) string {
+		return id.Provider + "|" + id.APIURL
+	}
+	seen := map[string]bool{upstream(primaryID): true}
+	ordered := make([]ModelIdentifier, 0, len(rest))
+	var deferred []ModelIdentifier
+	for _, identifier := range rest {
+		if !seen[upstream(identifier)] {
+			seen[upstream(identifier)] = true
+			ordered = append(ordered, identifier)
+		} else {
+			deferred = append(deferred, identifier)
+		}
+	}
+	rest = append(ordered, deferred...)
+
+	candidates := make([]Translator, 0, len(rest)+1)
+	candidates = append(candidates, primary)
+	for _, identifier := range rest {
+		candidates = append(candidates, mm.translators[identifier])
+	}
+	return candidates
+}
+
+// AllowRequest 在向模型发起请求前调用，熔断中的模型返回 false
+func (mm *ModelManager) AllowRequest(id ModelIdentifier) bool {
+	return mm.health.Allow(id)
+}
+
+// ReportResult 上报一次请求结果，用于更新模型健康状态
+// 只有上游故障（见 IsFailoverError）计为失败，调用方取消等情况不影响熔断
+func (mm *ModelManager) ReportResult(id ModelIdentifier, latency time.Duration, err error) {
+	switch {
+	case err == nil:
+		mm.health.RecordSuccess(id, latency)
+	case IsFailoverError(err):
+		mm.health.RecordFailure(id, latency, err)
+	default:
+		mm.health.Release(id)
+	}
+}
+
+// HealthSnapshot 返回所有模型的健康状态
+func (mm *ModelManager) HealthSnapshot() []ModelHealthSnapshot {
+	return mm.health.Snapshot(mm.ListModels())
+}
+
 // ListModels 列出所有可用的模型
 func (mm *ModelManager) ListModels() []ModelIdentifier {
 	mm.mu.RLock()
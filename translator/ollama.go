@@ -1,14 +1,19 @@
 package translator
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 	"transbridge/internal/utils"
+
+	"github.com/sashabaranov/go-openai"
 )
 
 // OllamaTranslator 实现 Ollama 的翻译器
@@ -22,17 +27,21 @@ type OllamaTranslator struct {
 
 // OllamaRequest 定义 Ollama API 请求结构
 type OllamaRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream"`
+	Model    string                 `json:"model"`
+	Messages []Message              `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
 }
 
 // OllamaResponse 定义 Ollama API 响应结构
 type OllamaResponse struct {
-	Model     string  `json:"model"`
-	CreatedAt string  `json:"created_at"`
-	Message   Message `json:"message"`
-	Done      bool    `json:"done"`
+	Model           string  `json:"model"`
+	CreatedAt       string  `json:"created_at"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason,omitempty"`
+	PromptEvalCount int     `json:"prompt_eval_count,omitempty"`
+	EvalCount       int     `json:"eval_count,omitempty"`
 }
 
 // Message 定义消息结构
@@ -52,26 +61,33 @@ func NewOllamaTranslator(apiURL, model string, timeout int) *OllamaTranslator {
 	}
 }
 
-// Translate 实现翻译接口
-func (t *OllamaTranslator) Translate(promptTemplate, text, sourceLang, targetLang string) (string, error) {
+// Translate 实现翻译接口，ctx 取消或超时时会立即中止请求与重试
+func (t *OllamaTranslator) Translate(ctx context.Context, promptTemplate utils.PromptTemplate, text, sourceLang, targetLang string) (string, error) {
 	slang, _ := utils.GetLanguageName(sourceLang)
 	tlang, _ := utils.GetLanguageName(targetLang)
 
-	prompt, err := utils.ApplyPromptTemplate(promptTemplate, text, slang, tlang)
+	system, prompt, err := promptTemplate.Render(text, slang, tlang)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
 
+	var messages []Message
+	if system != "" {
+		messages = append(messages, Message{
+			Role:    "system",
+			Content: system,
+		})
+	}
+	messages = append(messages, Message{
+		Role:    "user",
+		Content: prompt,
+	})
+
 	reqBody := OllamaRequest{
-		Model: t.model,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false,
+		Model:    t.model,
+		Messages: messages,
+		Stream:   false,
 	}
 
 	jsonData, errVar := json.Marshal(reqBody)
@@ -79,26 +95,36 @@ func (t *OllamaTranslator) Translate(promptTemplate, text, sourceLang, targetLan
 		return "", fmt.Errorf("failed to marshal request: %w", errVar)
 	}
 
-	req, errVar := http.NewRequest("POST", t.apiURL, bytes.NewBuffer(jsonData))
-	if errVar != nil {
-		return "", fmt.Errorf("failed to create request: %w", errVar)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
 	var resp *http.Response
-	//var err error
 	for attempt := 0; attempt <= t.retryTimes; attempt++ {
+		req, errVar := http.NewRequestWithContext(ctx, "POST", t.apiURL, bytes.NewReader(jsonData))
+		if errVar != nil {
+			return "", fmt.Errorf("failed to create request: %w", errVar)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
 		resp, err = t.httpClient.Do(req)
 		if err == nil && resp != nil && resp.StatusCode == http.StatusOK {
 			break
 		}
+		// 上下文已取消（客户端断开或超时），不再重试
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return "", fmt.Errorf("request aborted: %w", ctx.Err())
+		}
+		if attempt == t.retryTimes {
+			break
+		}
 		if resp != nil {
 			io.Copy(io.Discard, resp.Body)
 			resp.Body.Close()
 		}
 		backoff := time.Duration(200*(1<<attempt)) * time.Millisecond
-		time.Sleep(backoff)
+		if err := sleepWithContext(ctx, backoff); err != nil {
+			return "", fmt.Errorf("request aborted: %w", err)
+		}
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
@@ -107,7 +133,7 @@ func (t *OllamaTranslator) Translate(promptTemplate, text, sourceLang, targetLan
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", &UpstreamError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var ollamaResp OllamaResponse
@@ -115,6 +141,10 @@ func (t *OllamaTranslator) Translate(promptTemplate, text, sourceLang, targetLan
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if ollamaResp.Message.Content == "" {
+		return "", ErrEmptyResponse
+	}
+
 	return ollamaResp.Message.Content, nil
 }
 
@@ -136,3 +166,218 @@ func (t *OllamaTranslator) GetProvider() string {
 func (t *OllamaTranslator) Close() error {
 	return nil
 }
+
+// ollamaDefaultContext Ollama 默认的上下文长度（num_ctx），输入与输出共用
+const ollamaDefaultContext = 2048
+
+// MaxOutputTokens 单次请求可用于输出的 token 数，按默认上下文长度的一半计算
+func (t *OllamaTranslator) MaxOutputTokens() int {
+	return ollamaDefaultContext / 2
+}
+
+// EstimateTokens 估算文本的 token 数
+func (t *OllamaTranslator) EstimateTokens(text string) int {
+	return utils.EstimateTokens(text)
+}
+
+// OllamaChatCompletion 将 OpenAI 格式的聊天完成请求转换为 Ollama /api/chat 调用
+type OllamaChatCompletion struct {
+	*OllamaTranslator
+}
+
+// NewOllamaChatCompletion 创建新的 Ollama 聊天完成实例
+func NewOllamaChatCompletion(translator *OllamaTranslator) *OllamaChatCompletion {
+	return &OllamaChatCompletion{
+		OllamaTranslator: translator,
+	}
+}
+
+// CreateChatCompletion 非流式聊天完成，返回 OpenAI 格式的响应
+func (t *OllamaChatCompletion) CreateChatCompletion(ctx context.Context, oaiRequest openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	resp, err := t.send(ctx, t.httpClient, oaiRequest, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ollamaResp OllamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &openai.ChatCompletionResponse{
+		ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   t.model,
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Index: 0,
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: ollamaResp.Message.Content,
+				},
+				FinishReason: ollamaFinishReason(ollamaResp.DoneReason),
+			},
+		},
+		Usage: openai.Usage{
+			PromptTokens:     ollamaResp.PromptEvalCount,
+			CompletionTokens: ollamaResp.EvalCount,
+			TotalTokens:      ollamaResp.PromptEvalCount + ollamaResp.EvalCount,
+		},
+	}, nil
+}
+
+// CreateChatCompletionStream 流式聊天完成，将 Ollama 的 NDJSON 流转换为 chat.completion.chunk 帧
+func (t *OllamaChatCompletion) CreateChatCompletionStream(ctx context.Context, oaiRequest openai.ChatCompletionRequest) (ChatCompletionStream, error) {
+	// 流式响应可能持续很久，不使用带整体超时的 Client，由 ctx 控制生命周期
+	resp, err := t.send(ctx, streamClient(t.httpClient), oaiRequest, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ollamaStream{
+		body:    resp.Body,
+		reader:  bufio.NewReader(resp.Body),
+		id:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
+		created: time.Now().Unix(),
+		model:   t.model,
+	}, nil
+}
+
+// send 将 OpenAI 请求转换为 Ollama 请求并发送，非 200 状态码时返回 UpstreamError
+func (t *OllamaChatCompletion) send(ctx context.Context, client *http.Client, oaiRequest openai.ChatCompletionRequest, stream bool) (*http.Response, error) {
+	reqBody := OllamaRequest{
+		Model:    t.model,
+		Messages: make([]Message, 0, len(oaiRequest.Messages)),
+		Stream:   stream,
+		Options:  map[string]interface{}{},
+	}
+	for _, msg := range oaiRequest.Messages {
+		content := msg.Content
+		if content == "" && len(msg.MultiContent) > 0 {
+			var parts []string
+			for _, part := range msg.MultiContent {
+				if part.Type == openai.ChatMessagePartTypeText {
+					parts = append(parts, part.Text)
+				}
+			}
+			content = strings.Join(parts, "\n")
+		}
+		reqBody.Messages = append(reqBody.Messages, Message{Role: msg.Role, Content: content})
+	}
+	if oaiRequest.Temperature > 0 {
+		reqBody.Options["temperature"] = oaiRequest.Temperature
+	}
+	if oaiRequest.TopP > 0 {
+		reqBody.Options["top_p"] = oaiRequest.TopP
+	}
+	if oaiRequest.MaxTokens > 0 {
+		reqBody.Options["num_predict"] = oaiRequest.MaxTokens
+	}
+
+	reqData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.apiURL, bytes.NewReader(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &UpstreamError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// chatCompletionChunk 精简的 chat.completion.chunk 帧结构
+type chatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []chunkChoice `json:"choices"`
+}
+
+type chunkChoice struct {
+	Index        int                  `json:"index"`
+	Delta        chunkDelta           `json:"delta"`
+	FinishReason *openai.FinishReason `json:"finish_reason"`
+}
+
+type chunkDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// ollamaStream 逐行读取 Ollama NDJSON 流并转换为 OpenAI chunk
+type ollamaStream struct {
+	body     io.ReadCloser
+	reader   *bufio.Reader
+	id       string
+	created  int64
+	model    string
+	sentRole bool
+	done     bool
+}
+
+func (s *ollamaStream) Recv() ([]byte, error) {
+	for {
+		if s.done {
+			return nil, io.EOF
+		}
+
+		line, err := s.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			var chunk OllamaResponse
+			if jsonErr := json.Unmarshal(line, &chunk); jsonErr != nil {
+				return nil, fmt.Errorf("failed to decode stream chunk: %w", jsonErr)
+			}
+
+			choice := chunkChoice{Index: 0, Delta: chunkDelta{Content: chunk.Message.Content}}
+			if !s.sentRole {
+				choice.Delta.Role = openai.ChatMessageRoleAssistant
+				s.sentRole = true
+			}
+			if chunk.Done {
+				reason := ollamaFinishReason(chunk.DoneReason)
+				choice.FinishReason = &reason
+				s.done = true
+			}
+
+			return json.Marshal(chatCompletionChunk{
+				ID:      s.id,
+				Object:  "chat.completion.chunk",
+				Created: s.created,
+				Model:   s.model,
+				Choices: []chunkChoice{choice},
+			})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *ollamaStream) Close() error {
+	return s.body.Close()
+}
+
+// ollamaFinishReason 将 Ollama 的 done_reason 映射为 OpenAI 的 finish_reason
+func ollamaFinishReason(reason string) openai.FinishReason {
+	if reason == "length" {
+		return openai.FinishReasonLength
+	}
+	return openai.FinishReasonStop
+}
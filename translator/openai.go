@@ -38,7 +38,11 @@ type OpenAITranslator struct {
 }
 
 // 确保 OpenAITranslator 实现了 Translator 接口
-var _ Translator = (*OpenAITranslator)(nil)
+var (
+	_ Translator  = (*OpenAITranslator)(nil)
+	_ TokenBudget = (*OpenAITranslator)(nil)
+	_ Sampling    = (*OpenAITranslator)(nil)
+)
 
 // NewOpenAITranslator 创建新的OpenAI翻译器实例
 func NewOpenAITranslator(provider, apiURL, apiKey, model string, timeout, maxTokens int, temperature float32) *OpenAITranslator {
@@ -68,30 +72,30 @@ func NewOpenAITranslator(provider, apiURL, apiKey, model string, timeout, maxTok
 	}
 }
 
-// Translate 实现翻译功能
-func (t *OpenAITranslator) Translate(promptTemplate, text, sourceLang, targetLang string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.Timeout)*time.Second)
+// Translate 实现翻译功能，ctx 取消或超时时会立即中止请求与重试
+func (t *OpenAITranslator) Translate(ctx context.Context, promptTemplate utils.PromptTemplate, text, sourceLang, targetLang string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.Timeout)*time.Second)
 	defer cancel()
 
-	return t.TranslateWithContext(ctx, promptTemplate, text, sourceLang, targetLang)
-}
-
-// TranslateWithContext 支持上下文的翻译方法
-func (t *OpenAITranslator) TranslateWithContext(ctx context.Context, promptTemplate, text, sourceLang, targetLang string) (string, error) {
 	slang, _ := utils.GetLanguageName(sourceLang)
 	tlang, _ := utils.GetLanguageName(targetLang)
 
-	prompt, err := utils.ApplyPromptTemplate(promptTemplate, text, slang, tlang)
+	system, prompt, err := promptTemplate.Render(text, slang, tlang)
 	if err != nil {
 		return "", fmt.Errorf("failed to apply prompt template: %w", err)
 	}
 
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+	var messages []openai.ChatCompletionMessage
+	if system != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    "system",
+			Content: system,
+		})
 	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    "user",
+		Content: prompt,
+	})
 
 	log.Println("prompt", prompt)
 
@@ -110,24 +114,32 @@ func (t *OpenAITranslator) TranslateWithContext(ctx context.Context, promptTempl
 		return "", fmt.Errorf("failed to marshal request: %w", errVar)
 	}
 
-	// 创建请求
-	req, errVar := http.NewRequestWithContext(ctx, "POST", t.ApiURL, bytes.NewBuffer(reqData))
-	if errVar != nil {
-		return "", fmt.Errorf("failed to create request: %w", errVar)
-	}
-
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.ApiKey))
-
-	// 发送请求
+	// 发送请求（每次重试都需要重新创建请求，请求体不可重复读取）
 	var resp *http.Response
-	//var err error
 	for attempt := 0; attempt <= t.RetryTimes; attempt++ {
+		req, errVar := http.NewRequestWithContext(ctx, "POST", t.ApiURL, bytes.NewReader(reqData))
+		if errVar != nil {
+			return "", fmt.Errorf("failed to create request: %w", errVar)
+		}
+
+		// 设置请求头
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.ApiKey))
+
 		resp, err = t.Client.Do(req)
 		if err == nil && resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			break
 		}
+		// 上下文已取消（客户端断开或超时），不再重试
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return "", fmt.Errorf("request aborted: %w", ctx.Err())
+		}
+		if attempt == t.RetryTimes {
+			break
+		}
 		// 读取错误响应体以便下次重试前释放连接
 		if resp != nil {
 			io.Copy(io.Discard, resp.Body)
@@ -135,7 +147,9 @@ func (t *OpenAITranslator) TranslateWithContext(ctx context.Context, promptTempl
 		}
 		// 指数退避
 		backoff := time.Duration(200*(1<<attempt)) * time.Millisecond
-		time.Sleep(backoff)
+		if err := sleepWithContext(ctx, backoff); err != nil {
+			return "", fmt.Errorf("request aborted: %w", err)
+		}
 	}
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
@@ -143,7 +157,7 @@ func (t *OpenAITranslator) TranslateWithContext(ctx context.Context, promptTempl
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("upstream status %d: %s", resp.StatusCode, string(body))
+		return "", &UpstreamError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// 解析响应
@@ -154,7 +168,7 @@ func (t *OpenAITranslator) TranslateWithContext(ctx context.Context, promptTempl
 
 	// 检查响应是否包含翻译结果
 	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
-		return "", fmt.Errorf("no translation result in response")
+		return "", ErrEmptyResponse
 	}
 
 	return result.Choices[0].Message.Content, nil
@@ -175,6 +189,21 @@ func (t *OpenAITranslator) GetModel() string {
 	return t.Model
 }
 
+// MaxOutputTokens 单次请求允许输出的 token 数（max_tokens）
+func (t *OpenAITranslator) MaxOutputTokens() int {
+	return t.MaxTokens
+}
+
+// EstimateTokens 估算文本的 token 数
+func (t *OpenAITranslator) EstimateTokens(text string) int {
+	return utils.EstimateTokens(text)
+}
+
+// SamplingParams 返回请求使用的采样参数
+func (t *OpenAITranslator) SamplingParams() string {
+	return fmt.Sprintf("temperature=%g,top_p=%g", t.Temperature, t.Top_P)
+}
+
 // GetMetrics 获取最近一次请求的指标
 func (t *OpenAITranslator) GetMetrics() TranslationMetrics {
 	return t.LastMetrics
@@ -240,6 +269,11 @@ func (t *OpenAIChatCompletion) CreateChatCompletion(ctx context.Context, oaiRequ
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &UpstreamError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
 	var result openai.ChatCompletionResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -247,3 +281,38 @@ func (t *OpenAIChatCompletion) CreateChatCompletion(ctx context.Context, oaiRequ
 
 	return &result, nil
 }
+
+// CreateChatCompletionStream 以 SSE 方式请求上游，返回逐帧透传的流
+func (t *OpenAIChatCompletion) CreateChatCompletionStream(ctx context.Context, oaiRequest openai.ChatCompletionRequest) (ChatCompletionStream, error) {
+	oaiRequest.Stream = true
+
+	reqData, err := json.Marshal(oaiRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		t.ApiURL,
+		bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.ApiKey))
+
+	// 流式响应可能持续很久，不使用带整体超时的 Client，由 ctx 控制生命周期
+	resp, err := streamClient(t.Client).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &UpstreamError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return newSSEStream(resp.Body), nil
+}
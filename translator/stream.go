@@ -0,0 +1,82 @@
+package translator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ChatCompleter 提供 OpenAI 格式的聊天完成能力
+type ChatCompleter interface {
+	CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatCompletionStream, error)
+}
+
+// ChatCompletionStream 流式聊天完成的数据源
+// Recv 每次返回一帧 chat.completion.chunk 的 JSON，流结束时返回 io.EOF
+type ChatCompletionStream interface {
+	Recv() ([]byte, error)
+	Close() error
+}
+
+// NewChatCompleter 根据翻译器类型返回对应的聊天完成实现
+func NewChatCompleter(t Translator) (ChatCompleter, bool) {
+	switch v := t.(type) {
+	case *OpenAITranslator:
+		return NewOpenAIChatCompletion(v), true
+	case *OllamaTranslator:
+		return NewOllamaChatCompletion(v), true
+	default:
+		return nil, false
+	}
+}
+
+// sseStream 读取上游 text/event-stream 响应，原样透传 data 帧
+type sseStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+func newSSEStream(body io.ReadCloser) *sseStream {
+	return &sseStream{
+		body:   body,
+		reader: bufio.NewReader(body),
+	}
+}
+
+func (s *sseStream) Recv() ([]byte, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+
+		// 只关心 data 字段，忽略空行、注释与 event/id 等字段
+		if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			payload = bytes.TrimSpace(payload)
+			if bytes.Equal(payload, []byte("[DONE]")) {
+				return nil, io.EOF
+			}
+			if len(payload) > 0 {
+				return payload, nil
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *sseStream) Close() error {
+	return s.body.Close()
+}
+
+// streamClient 返回去掉整体超时的 http.Client 副本，流式请求的生命周期由 ctx 控制
+func streamClient(c *http.Client) *http.Client {
+	clone := *c
+	clone.Timeout = 0
+	return &clone
+}
@@ -1,11 +1,43 @@
 // translator/translator.go
 package translator
 
+import (
+	"context"
+	"time"
+	"transbridge/internal/utils"
+)
+
 // Translator 定义翻译器接口
+// prompt 为已按语言对选定的提示词模板，System 不为空时作为系统消息发送
 type Translator interface {
-	Translate(promptTemplate, text, sourceLang, targetLang string) (string, error)
+	Translate(ctx context.Context, prompt utils.PromptTemplate, text, sourceLang, targetLang string) (string, error)
 	GetAPIURL() string
 	GetModel() string
 	GetProvider() string
 	Close() error
 }
+
+// TokenBudget 可选接口：声明单次请求允许输出的 token 数并估算文本的 token 数
+// 服务据此把超出上限的长文本拆分为多块翻译，避免译文被截断
+type TokenBudget interface {
+	MaxOutputTokens() int
+	EstimateTokens(text string) int
+}
+
+// Sampling 可选接口：返回影响译文的采样参数（如 temperature），参与缓存键计算
+type Sampling interface {
+	SamplingParams() string
+}
+
+// sleepWithContext 在重试退避期间等待，上下文取消时立即返回
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
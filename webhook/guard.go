@@ -0,0 +1,98 @@
+// webhook/guard.go
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress 回调地址解析到回环、链路本地或内网地址，且不在允许列表中
+var ErrForbiddenAddress = errors.New("callback address is not allowed")
+
+// sharedAddressSpace 运营商级 NAT 地址段（100.64.0.0/10），同样视为内网地址
+var sharedAddressSpace = &net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+// guard 回调地址的访问控制：建立连接时检查实际连接的 IP，域名解析到内网地址（包括 DNS 重绑定）同样会被拒绝
+// 允许列表为空时可以回调任意公网地址；配置后只能回调列表中的主机，列表中的主机可以是内网地址
+type guard struct {
+	hosts map[string]bool // 允许的主机名（小写）
+	nets  []*net.IPNet    // 允许的 IP 或 IP 段
+}
+
+// newGuard 解析允许列表，条目可以是主机名、IP 或 CIDR
+func newGuard(allowlist []string) *guard {
+	g := &guard{hosts: make(map[string]bool)}
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.Contains(entry, "/"):
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				log.Printf("Ignoring invalid webhook allowlist entry %q: %v", entry, err)
+				continue
+			}
+			g.nets = append(g.nets, ipNet)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			g.nets = append(g.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		default:
+			g.hosts[entry] = true
+		}
+	}
+	return g
+}
+
+// check 检查连接 host 时实际使用的 ip
+func (g *guard) check(host string, ip net.IP) error {
+	allowed := g.hosts[strings.ToLower(host)]
+	for _, ipNet := range g.nets {
+		allowed = allowed || ipNet.Contains(ip)
+	}
+
+	if !allowed && (len(g.hosts) > 0 || len(g.nets) > 0) {
+		return fmt.Errorf("%w: %s is not in the webhook allowlist", ErrForbiddenAddress, host)
+	}
+	if !allowed && internal(ip) {
+		return fmt.Errorf("%w: %s resolves to internal address %s", ErrForbiddenAddress, host, ip)
+	}
+	return nil
+}
+
+// dialContext 返回建立连接前检查目标 IP 的拨号函数
+func (g *guard) dialContext(timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		dialer := &net.Dialer{
+			Timeout: timeout,
+			// Control 在域名解析之后、连接之前调用，address 为实际连接的 IP
+			Control: func(network, address string, _ syscall.RawConn) error {
+				ipStr, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(ipStr)
+				if ip == nil {
+					return fmt.Errorf("%w: invalid address %s", ErrForbiddenAddress, address)
+				}
+				return g.check(host, ip)
+			},
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// internal 判断 ip 是否为回环、链路本地、内网、未指定或组播地址
+func internal(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGuardCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		host      string
+		ip        string
+		wantErr   bool
+	}{
+		{name: "public address", host: "hooks.example.com", ip: "93.184.216.34"},
+		{name: "public ipv6 address", host: "hooks.example.com", ip: "2606:2800:220:1::1"},
+		{name: "loopback", host: "localhost", ip: "127.0.0.1", wantErr: true},
+		{name: "ipv6 loopback", host: "localhost", ip: "::1", wantErr: true},
+		{name: "ipv4-mapped loopback", host: "evil.example.com", ip: "::ffff:127.0.0.1", wantErr: true},
+		{name: "private network", host: "evil.example.com", ip: "10.1.2.3", wantErr: true},
+		{name: "private 192.168", host: "evil.example.com", ip: "192.168.1.10", wantErr: true},
+		{name: "cloud metadata", host: "evil.example.com", ip: "169.254.169.254", wantErr: true},
+		{name: "shared address space", host: "evil.example.com", ip: "100.64.0.1", wantErr: true},
+		{name: "unspecified", host: "evil.example.com", ip: "0.0.0.0", wantErr: true},
+		{name: "unique local ipv6", host: "evil.example.com", ip: "fd00::1", wantErr: true},
+		{name: "allowlisted internal host", allowlist: []string{"Hooks.Internal"}, host: "hooks.internal", ip: "10.0.0.5"},
+		{name: "allowlisted cidr", allowlist: []string{"10.0.0.0/8"}, host: "hooks.internal", ip: "10.0.0.5"},
+		{name: "allowlisted ip", allowlist: []string{"192.168.1.10"}, host: "192.168.1.10", ip: "192.168.1.10"},
+		{name: "allowlist rejects other public hosts", allowlist: []string{"hooks.example.com"}, host: "other.example.com", ip: "93.184.216.34", wantErr: true},
+		{name: "allowlist rejects other addresses", allowlist: []string{"10.0.0.0/24"}, host: "hooks.internal", ip: "10.0.1.5", wantErr: true},
+		{name: "invalid entries ignored", allowlist: []string{"", "10.0.0.0/99"}, host: "hooks.example.com", ip: "93.184.216.34"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newGuard(tt.allowlist).check(tt.host, net.ParseIP(tt.ip))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check(%q, %s) error = %v, wantErr %v", tt.host, tt.ip, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrForbiddenAddress) {
+				t.Errorf("error = %v, want ErrForbiddenAddress", err)
+			}
+		})
+	}
+}
+
+func TestGuardDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	addr := server.Listener.Addr().String()
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		wantErr   bool
+	}{
+		{name: "loopback refused", wantErr: true},
+		{name: "allowlisted loopback connects", allowlist: []string{"127.0.0.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := newGuard(tt.allowlist).dialContext(time.Second)(context.Background(), "tcp", addr)
+			if conn != nil {
+				conn.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dial %s error = %v, wantErr %v", addr, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrForbiddenAddress) {
+				t.Errorf("error = %v, want ErrForbiddenAddress", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// webhook/webhook.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// 回调请求头
+const (
+	HeaderSignature = "X-TransBridge-Signature" // sha256=<body 的 HMAC-SHA256，十六进制>
+	HeaderEvent     = "X-TransBridge-Event"     // 事件类型，例如 job.completed
+	HeaderDelivery  = "X-TransBridge-Delivery"  // 投递 ID，重试时不变，可用于去重
+	HeaderAttempt   = "X-TransBridge-Attempt"   // 第几次投递，从 1 开始
+)
+
+// Options 回调投递选项
+type Options struct {
+	Timeout     time.Duration // 单次请求超时，默认 10 秒
+	MaxAttempts int           // 最多投递次数（含首次），默认 5
+	Backoff     time.Duration // 首次重试前的等待时间，之后每次翻倍，默认 5 秒
+	MaxBackoff  time.Duration // 重试等待时间上限，默认 5 分钟
+	Allowlist   []string      // 允许回调的主机名、IP 或 CIDR；为空时可以回调任意公网地址，配置后只能回调其中的地址（可以是内网地址）
+}
+
+// Message 一次回调：同一 Message 的每次重试发送相同的 Body 与签名
+type Message struct {
+	URL      string
+	Secret   string // 签名密钥
+	Event    string
+	Delivery string
+	Body     []byte
+}
+
+// Attempt 一次投递的结果
+type Attempt struct {
+	Number     int // 第几次投递，从 1 开始
+	StatusCode int // 回调地址返回的状态码，请求未完成时为 0
+	Err        error
+}
+
+// Client 回调投递客户端：POST JSON 正文，失败时按指数退避重试
+type Client struct {
+	http *http.Client
+	opts Options
+}
+
+// NewClient 创建回调投递客户端
+func NewClient(opts Options) *Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 5 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Minute
+	}
+
+	// 不使用环境变量中的代理，否则建立连接时检查的是代理地址
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = newGuard(opts.Allowlist).dialContext(opts.Timeout)
+
+	return &Client{
+		http: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: transport,
+			// 不跟随重定向，避免签名后的正文被转发到其它地址
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		opts: opts,
+	}
+}
+
+// Sign 返回 body 的 HMAC-SHA256 签名，格式与 X-TransBridge-Signature 请求头相同
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateURL 校验回调地址，只支持 http 与 https；目标地址是否允许在投递时按实际连接的 IP 检查
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("callback_url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// Deliver 投递回调直到成功、遇到不可重试的错误或达到最大次数，每次投递后调用 onAttempt 记录结果
+// 2xx 视为成功；网络错误、5xx、408 与 429 会重试，其它状态码与不允许的回调地址直接失败
+func (c *Client) Deliver(ctx context.Context, msg Message, onAttempt func(Attempt)) error {
+	var err error
+	for n := 1; n <= c.opts.MaxAttempts; n++ {
+		if n > 1 {
+			select {
+			case <-time.After(c.backoff(n - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		var status int
+		status, err = c.post(ctx, msg, n)
+		if onAttempt != nil {
+			onAttempt(Attempt{Number: n, StatusCode: status, Err: err})
+		}
+		if err == nil || !retryable(status) || errors.Is(err, ErrForbiddenAddress) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// post 发送一次回调，返回状态码；非 2xx 时返回错误
+func (c *Client) post(ctx context.Context, msg Message, attempt int) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TransBridge-Webhook")
+	req.Header.Set(HeaderSignature, Sign(msg.Secret, msg.Body))
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderDelivery, msg.Delivery)
+	req.Header.Set(HeaderAttempt, fmt.Sprint(attempt))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff 第 n 次重试前的等待时间
+func (c *Client) backoff(n int) time.Duration {
+	d := c.opts.Backoff
+	for i := 1; i < n && d < c.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.opts.MaxBackoff {
+		d = c.opts.MaxBackoff
+	}
+	return d
+}
+
+// retryable 请求未完成（状态码为 0）或服务端暂时不可用时重试
+func retryable(status int) bool {
+	return status == 0 || status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}